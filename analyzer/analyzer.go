@@ -2,9 +2,11 @@ package analyzer
 
 import (
 	"fmt"
+	"math"
+	"math/bits"
 	"path"
-	"strconv"
-	"strings"
+	"sort"
+	"unicode/utf8"
 
 	"github.com/mitchellh/hashstructure/v2"
 	"github.com/tidwall/btree"
@@ -12,6 +14,8 @@ import (
 	"tomasweigenast.com/nexema/tool/parser"
 	"tomasweigenast.com/nexema/tool/scope"
 	"tomasweigenast.com/nexema/tool/token"
+	"tomasweigenast.com/nexema/tool/tokenizer"
+	"tomasweigenast.com/nexema/tool/utils"
 )
 
 // Analyzer takes a linked list of built scopes and analyzes them syntactically.
@@ -23,14 +27,42 @@ type Analyzer struct {
 	currScope      *scope.Scope
 	currLocalScope *scope.LocalScope
 	currTypeId     string
+	targets        []*Target // the targets whose reserved words cannot be used as names
 	files          []definition.NexemaFile
+	packages       []definition.NexemaPackage
+}
+
+// packageFileName is the name of the file that is used to document a package. Its header, if any,
+// is placed before the headers of the other files in the package
+const packageFileName = "package.nex"
+
+// nolintAnnotation suppresses lint rules in the file, type or field it is declared in. In the header of a file, it
+// applies to that file only, so it is not merged into the annotations of the package
+const nolintAnnotation = "nolint"
+
+// enumDefaultAnnotation is the annotation used to select the default member of an enum or union
+const enumDefaultAnnotation = "default"
+
+// unionDiscriminatorAnnotation is the annotation used to name the field that holds the tag of the active member of a union
+const unionDiscriminatorAnnotation = "discriminator"
+
+// unionTagAnnotation is the annotation used to set the value that identifies a union member
+const unionTagAnnotation = "tag"
+
+// flagsBitSizes contains the primitives that can hold the value of a flags type, and their size in bits
+var flagsBitSizes = map[definition.ValuePrimitive]int{
+	definition.Uint8:  8,
+	definition.Uint16: 16,
+	definition.Uint32: 32,
+	definition.Uint64: 64,
 }
 
 func NewAnalyzer(scopes []*scope.Scope) *Analyzer {
 	return &Analyzer{
-		scopes: scopes,
-		errors: newAnalyzerErrorCollection(),
-		files:  make([]definition.NexemaFile, 0),
+		scopes:   scopes,
+		errors:   newAnalyzerErrorCollection(),
+		files:    make([]definition.NexemaFile, 0),
+		packages: make([]definition.NexemaPackage, 0),
 	}
 }
 
@@ -42,10 +74,25 @@ func (self *Analyzer) Files() []definition.NexemaFile {
 	return self.files
 }
 
+func (self *Analyzer) Packages() []definition.NexemaPackage {
+	return self.packages
+}
+
 func (self *Analyzer) Errors() *AnalyzerErrorCollection {
 	return self.errors
 }
 
+// report reports an error found in the file being analyzed and returns it
+func (self *Analyzer) report(kind AnalyzerErrorKind, at tokenizer.Pos) *AnalyzerError {
+	err := NewAnalyzerError(kind, at)
+	if self.currLocalScope != nil {
+		err.File = self.currLocalScope.File()
+	}
+
+	self.errors.push(err)
+	return err
+}
+
 // Analyze starts analyzing and logs any error encountered
 func (self *Analyzer) Analyze() {
 	for _, scope := range self.scopes {
@@ -55,12 +102,100 @@ func (self *Analyzer) Analyze() {
 
 func (self *Analyzer) analyzeScope(s *scope.Scope) {
 	self.currScope = s
-	for _, localScope := range *s.LocalScopes() {
-		self.analyzeLocalScope(localScope)
+	localScopes := *s.LocalScopes()
+
+	// folders without .nex files are not packages
+	if len(localScopes) == 0 {
+		return
+	}
+
+	pkg := definition.NexemaPackage{
+		Name:  s.Name(),
+		Path:  s.Path(),
+		Files: make([]string, 0),
+	}
+
+	for _, localScope := range localScopes {
+		file := self.analyzeLocalScope(localScope)
+		pkg.Files = append(pkg.Files, file.Id)
+	}
+
+	pkg.Documentation, pkg.Annotations = self.analyzePackageHeader(localScopes)
+	self.validateTypeNameCollisions(localScopes)
+
+	hashcode, err := hashstructure.Hash(&pkg, hashstructure.FormatV2, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	pkg.Id = fmt.Sprint(hashcode)
+	self.packages = append(self.packages, pkg)
+}
+
+// analyzePackageHeader merges the headers of every file in a package into the package's documentation and annotations.
+//
+// The header of the package.nex file, if any, goes first, the rest are sorted by file name.
+// Annotations cannot be declared more than once in the same package, errors are reported in the file of the annotation.
+// nolint annotations are lint directives of their own file, so they are left out
+func (self *Analyzer) analyzePackageHeader(localScopes []*scope.LocalScope) ([]string, definition.Assignments) {
+	sorted := make([]*scope.LocalScope, len(localScopes))
+	copy(sorted, localScopes)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].File().FileName, sorted[j].File().FileName
+		if a == packageFileName || b == packageFileName {
+			return a == packageFileName && b != packageFileName
+		}
+
+		return a < b
+	})
+
+	type declaredAnnotation struct {
+		file *parser.File
+		key  parser.IdentStmt
+	}
+
+	var documentation []string
+	var annotations definition.Assignments
+	declared := map[string]declaredAnnotation{}
+	for _, ls := range sorted {
+		self.currLocalScope = ls
+		if ls.Documentation != nil {
+			documentation = append(documentation, sanitizeComments(&ls.Documentation)...)
+		}
+
+		for _, annotation := range ls.Annotations {
+			assignment := annotation.Assigment
+			key := assignment.Left.Token.Literal
+			if key == nolintAnnotation {
+				continue
+			}
+
+			if annotations == nil {
+				annotations = definition.Assignments{}
+			}
+
+			if previous, ok := declared[key]; ok {
+				self.report(ErrAssignmentKeyAlreadyInUse{key}, assignment.Left.Pos).
+					noteIn(previous.file, "previously defined here", previous.key.Pos)
+				continue
+			}
+			declared[key] = declaredAnnotation{ls.File(), assignment.Left}
+
+			value := assignment.Right.Kind.Value()
+			if !isAnnotationValue(value) {
+				self.report(ErrWrongAnnotationValue{}, assignment.Right.Pos)
+				continue
+			}
+
+			annotations[key] = value
+		}
 	}
+
+	self.currLocalScope = nil
+	return documentation, annotations
 }
 
-func (self *Analyzer) analyzeLocalScope(ls *scope.LocalScope) {
+func (self *Analyzer) analyzeLocalScope(ls *scope.LocalScope) *definition.NexemaFile {
 	self.currLocalScope = ls
 	file := ls.File()
 	nexFile := definition.NexemaFile{
@@ -70,6 +205,11 @@ func (self *Analyzer) analyzeLocalScope(ls *scope.LocalScope) {
 		PackageName: path.Base(file.Path),
 	}
 
+	// types referenced by the header of the file are not part of the package's documentation links, but they
+	// are references of the file
+	self.currTypeId = ""
+	self.getDocumentationLinks(ls.Documentation)
+
 	for _, obj := range *ls.Objects() {
 		self.currTypeId = obj.Id
 		def := self.analyzeTypeStmt(obj.Source())
@@ -86,42 +226,74 @@ func (self *Analyzer) analyzeLocalScope(ls *scope.LocalScope) {
 		panic(err)
 	}
 	self.files = append(self.files, nexFile)
+	return &nexFile
 }
 
 // analyzeTypeStmt analyses a TypeStmt in order to match the following set of rules:
 //
-// 1- modifier is token.Struct, token.Enum, token.Union or token.Base
+// 1- modifier is token.Struct, token.Enum, token.Union, token.Base or token.Flags
 // 2- if struct extends another type, check it exists and is a valid Base type
 // 3- each field validates against its own rules
-// 4- each default value, if any, is declared once and points to a valid field
+// 4- each default value, if any, is declared once and points to a valid field. Arrays, bounded lists and bounded
+// strings must respect their declared length, and numbers must fit in their primitive
+// 5- enums and unions declare at most one default member. For enums, if there is no one, the first member is the default one
+// 6- flags underlying type is uint8, uint16, uint32 or uint64. If not declared, it is uint32
+// 7- oneof groups are only declared in structs and validate against their own rules
+// 8- union discriminator, if declared, is a non-empty string, and member tags are non-empty strings that are not duplicated.
+// If a member does not declare its tag, it is its name
+// 9- type names are not reserved words in the checked targets, and field, member and oneof names are not reserved
+// words in the checked targets that do not accept them as member names. Field, member and oneof names do not
+// collide once converted to snake_case, camelCase or PascalCase
 //
 // If succeed, it outputs a valid definition.TypeDefinition
 func (self *Analyzer) analyzeTypeStmt(stmt *parser.TypeStmt) *definition.TypeDefinition {
 	def := new(definition.TypeDefinition)
+	def.Id = self.currTypeId
 	def.Name = stmt.Name.Token.Literal
 
 	// rule 1
 	switch stmt.Modifier {
-	case token.Struct, token.Enum, token.Union, token.Base:
+	case token.Struct, token.Enum, token.Union, token.Base, token.Flags:
 		def.Modifier = stmt.Modifier
 
 	default:
-		self.errors.push(ErrUnknownTypeModifier{stmt.Modifier}, stmt.Name.Pos)
+		self.report(ErrUnknownTypeModifier{stmt.Modifier}, stmt.Name.Pos)
 	}
 
 	// rule 2
 	if stmt.BaseType != nil {
-		obj := self.getObject(stmt.BaseType)
+		obj := self.getObject(stmt.BaseType, false)
 		if obj != nil {
-			def.BaseType = &obj.Id
+			if obj.Source().Modifier != token.Base {
+				name, alias := stmt.BaseType.Format()
+				self.report(ErrNotValidBaseType{name, alias}, stmt.BaseType.Pos)
+			} else {
+				def.BaseType = &obj.Id
+			}
 		}
 	}
 
+	// rule 6, needed to validate flags members
+	if stmt.Modifier == token.Flags {
+		def.UnderlyingType = self.getFlagsUnderlyingType(stmt.UnderlyingType)
+	}
+
 	// rule 3
-	fieldNames := map[string]bool{}     // to validate field's rule 2.
-	fieldIndexes := new(btree.Set[int]) // to validate field's rule 3.
+	fieldNames := map[string]tokenizer.Pos{} // to validate field's rule 1, with the position of each name.
+	fieldIndexes := new(btree.Set[int])      // to validate field's rule 3.
+	flagValues := new(btree.Set[uint64])     // to validate flags member's rule 3.
 	for _, field := range stmt.Fields {
-		fieldDef := self.analyzeFieldStmt(&field, &fieldNames, fieldIndexes, stmt.Modifier)
+		var fieldDef *definition.FieldDefinition
+		if stmt.Modifier == token.Flags {
+			fieldDef = self.analyzeFlagsMemberStmt(&field, &fieldNames, flagValues, def.Fields, def.UnderlyingType)
+		} else if len(field.Combines) > 0 {
+			self.report(ErrCombinedMemberNotFlags{field.Name.Token.Literal}, field.Name.Pos)
+		} else if field.AliasOf != nil {
+			fieldDef = self.analyzeEnumAliasStmt(&field, &fieldNames, def.Fields)
+		} else {
+			fieldDef = self.analyzeFieldStmt(&field, &fieldNames, fieldIndexes, stmt.Modifier)
+		}
+
 		if fieldDef != nil {
 			def.Fields = append(def.Fields, fieldDef)
 		}
@@ -130,20 +302,78 @@ func (self *Analyzer) analyzeTypeStmt(stmt *parser.TypeStmt) *definition.TypeDef
 	// rule 4
 	if stmt.Defaults != nil {
 		def.Defaults = self.getAssignments(&stmt.Defaults, false)
+
+		for _, assignment := range stmt.Defaults {
+			field := findMember(def.Fields, assignment.Left.Token.Literal)
+			if field != nil && field.Type != nil {
+				self.validateDefaultValue(assignment.Right, field.Type)
+			}
+		}
+	}
+
+	// rule 5
+	switch stmt.Modifier {
+	case token.Enum:
+		def.DefaultMember = self.getDefaultMember(stmt.Fields)
+		if len(def.DefaultMember) == 0 && len(stmt.Fields) > 0 {
+			def.DefaultMember = stmt.Fields[0].Name.Token.Literal
+		}
+
+	case token.Union:
+		def.DefaultMember = self.getDefaultMember(stmt.Fields)
+	}
+
+	// rule 7
+	if len(stmt.Oneofs) > 0 {
+		if stmt.Modifier != token.Struct && stmt.Modifier != token.Base {
+			self.report(ErrOneofNotAllowed{stmt.Modifier}, stmt.Oneofs[0].Name.Pos)
+		} else {
+			for _, oneof := range stmt.Oneofs {
+				if oneofDef := self.analyzeOneofStmt(&oneof, stmt, &fieldNames, def.Fields); oneofDef != nil {
+					def.Oneofs = append(def.Oneofs, oneofDef)
+				}
+			}
+		}
 	}
 
 	if stmt.Documentation != nil {
 		def.Documentation = sanitizeComments(&stmt.Documentation)
+		def.Links = self.getDocumentationLinks(stmt.Documentation)
+	}
+
+	// rule 8
+	if stmt.Modifier == token.Union {
+		def.Discriminator = self.getUnionDiscriminator(stmt.Annotations)
+		self.setUnionTags(stmt.Fields, def.Fields)
 	}
 
 	if stmt.Annotations != nil {
-		annotations := make([]parser.AssignStmt, len(stmt.Annotations))
-		for i, annotation := range stmt.Annotations {
-			annotations[i] = annotation.Assigment
+		annotations := make([]parser.AssignStmt, 0, len(stmt.Annotations))
+		for _, annotation := range stmt.Annotations {
+			if stmt.Modifier == token.Union && annotation.Assigment.Left.Token.Literal == unionDiscriminatorAnnotation {
+				continue
+			}
+
+			annotations = append(annotations, annotation.Assigment)
 		}
 		def.Annotations = self.getAssignments(&annotations, true)
 	}
 
+	// rule 9
+	names := make([]parser.IdentStmt, 0, len(stmt.Fields)+len(stmt.Oneofs))
+	for _, field := range stmt.Fields {
+		names = append(names, field.Name)
+	}
+	for _, oneof := range stmt.Oneofs {
+		names = append(names, oneof.Name)
+	}
+
+	self.validateReservedName(stmt.Name, false)
+	for _, name := range names {
+		self.validateReservedName(name, true)
+	}
+	self.validateNameCollisions("field", names)
+
 	return def
 }
 
@@ -151,23 +381,26 @@ func (self *Analyzer) analyzeTypeStmt(stmt *parser.TypeStmt) *definition.TypeDef
 //
 // 1- field names are not duplicated
 // 2- field types are valid and defined (at definition.NexemaValueType) Nexema value type or a imported custom type.
-// 2a- if field value type is a list, contains exactly one argument and is a valid and defined Nexema value type and is not a list nor a map.
+// 2a- if field value type is a list, contains exactly one argument and is a valid and defined Nexema value type.
 // 2b- if field value type is a map, its key and value are valid and defined Nexema value types,
-// its key is a non nullable string, bool or int; and its value is not another map nor a list.
+// its key is a non nullable string, int, enum or flags.
+// 2c- if field value type is an array, contains exactly one argument and declares its length.
+// Lists and strings can declare a max length.
+// Arguments can be nullable, lists or maps, and they follow the same rules at any level.
 // 3- indexes start from 0 for enums (and must be subsequents) and 1 for other type and there are no duplicated ones
 // 4- field value type is not the same as the current type
 // 5- unions cannot declare nullable fields
 //
 // If suceeds, outputs a [definition.FieldDefinition]
-func (self *Analyzer) analyzeFieldStmt(field *parser.FieldStmt, names *map[string]bool, indexes *btree.Set[int], typeModifier token.TokenKind) *definition.FieldDefinition {
+func (self *Analyzer) analyzeFieldStmt(field *parser.FieldStmt, names *map[string]tokenizer.Pos, indexes *btree.Set[int], typeModifier token.TokenKind) *definition.FieldDefinition {
 	def := new(definition.FieldDefinition)
 
 	// rule 1
 	fieldName := field.Name.Token.Literal
-	if _, ok := (*names)[fieldName]; ok {
-		self.errors.push(ErrAlreadyDefined{fieldName}, field.Name.Pos)
+	if previous, ok := (*names)[fieldName]; ok {
+		self.report(ErrAlreadyDefined{fieldName}, field.Name.Pos).note("previously defined here", previous)
 	} else {
-		(*names)[fieldName] = true // update map for next field
+		(*names)[fieldName] = field.Name.Pos // update map for next field
 		def.Name = fieldName
 	}
 
@@ -178,69 +411,16 @@ func (self *Analyzer) analyzeFieldStmt(field *parser.FieldStmt, names *map[strin
 			primitiveValueType, ok := valueType.(definition.PrimitiveValueType)
 			if ok {
 				if typeModifier == token.Union && primitiveValueType.Nullable {
-					self.errors.push(ErrNonNullableUnionFields{}, field.Name.Pos)
+					self.report(ErrNonNullableUnionFields{}, field.Name.Pos)
 				}
 
-				switch primitiveValueType.Primitive {
-				//rule 2a
-				case definition.List:
-					if len(primitiveValueType.Arguments) != 1 {
-						self.errors.push(ErrWrongArgumentsLen{definition.List, len(primitiveValueType.Arguments)}, field.ValueType.Pos)
-						break // stop because the next check can fail if len(..) is 0
-					}
-
-					argument, ok := primitiveValueType.Arguments[0].(definition.PrimitiveValueType)
-					if ok && (argument.Primitive == definition.List || argument.Primitive == definition.Map) {
-						self.errors.push(ErrWrongArguments{Primitive: definition.List}, field.ValueType.Pos)
-					}
-
-				// rule 2b
-				case definition.Map:
-					if len(primitiveValueType.Arguments) != 2 {
-						self.errors.push(ErrWrongArgumentsLen{definition.Map, len(primitiveValueType.Arguments)}, field.ValueType.Pos)
-						break
-					}
-
-					key, ok := primitiveValueType.Arguments[0].(definition.PrimitiveValueType)
-					wrongArgs := !ok
-
-					if ok {
-						switch key.Primitive {
-						case definition.String,
-							definition.Int,
-							definition.Uint,
-							definition.Int8,
-							definition.Int16,
-							definition.Int32,
-							definition.Int64,
-							definition.Uint8,
-							definition.Uint16,
-							definition.Uint32,
-							definition.Uint64:
-
-							if key.Nullable {
-								wrongArgs = true
-							}
-
-						default:
-							wrongArgs = true
-						}
-					}
-
-					if wrongArgs {
-						self.errors.push(ErrWrongArguments{definition.Map, true}, field.ValueType.Pos)
-					}
-
-					value, ok := primitiveValueType.Arguments[1].(definition.PrimitiveValueType)
-					if ok && (value.Primitive == definition.List || value.Primitive == definition.Map) {
-						self.errors.push(ErrWrongArguments{Primitive: definition.Map}, field.ValueType.Pos)
-					}
-				}
+				// rule 2a and 2b
+				self.validateArguments(primitiveValueType, field.ValueType)
 			} else {
 				// rule 4
 				customTypeId := valueType.(definition.CustomValueType).ObjectId
 				if customTypeId == self.currTypeId {
-					self.errors.push(ErrIllegalUseCycle{field.ValueType.Token.Literal}, field.ValueType.Pos)
+					self.report(ErrIllegalUseCycle{field.ValueType.Token.Literal}, field.ValueType.Pos)
 				}
 			}
 
@@ -256,21 +436,28 @@ func (self *Analyzer) analyzeFieldStmt(field *parser.FieldStmt, names *map[strin
 			fieldIndex += 1
 		}
 	} else {
-		fieldIndex = toInt(field.Index.Token.Literal) // its sure Literal will be a number
+		index, err := token.ParseInteger(field.Index.Token.Literal, 32)
+		if err != nil {
+			self.report(ErrWrongFieldIndex{ErrBaseWrongFieldIndex_OutOfRange}, field.Index.Pos)
+			self.analyzeFieldMetadata(field, def, typeModifier)
+			return def
+		}
+
+		fieldIndex = int(index)
 	}
 
 	// check if already in use
 	if indexes.Contains(fieldIndex) {
-		self.errors.push(ErrWrongFieldIndex{ErrBaseWrongFieldIndex_DuplicatedIndex}, field.Index.Pos)
+		self.report(ErrWrongFieldIndex{ErrBaseWrongFieldIndex_DuplicatedIndex}, field.Index.Pos)
 	} else if typeModifier == token.Enum {
 		// check if first index is zero
 		if indexes.Len() == 0 && fieldIndex != 0 {
-			self.errors.push(ErrWrongFieldIndex{ErrBaseWrongFieldIndex_EnumShouldBeZeroBased}, field.Index.Pos)
+			self.report(ErrWrongFieldIndex{ErrBaseWrongFieldIndex_EnumShouldBeZeroBased}, field.Index.Pos)
 		} else {
 			// check if subsequent
 			previousIndex, ok := indexes.GetAt(indexes.Len() - 1)
 			if ok && fieldIndex != previousIndex+1 {
-				self.errors.push(ErrWrongFieldIndex{ErrBaseWrongFieldIndex_EnumShouldBeSubsequent}, field.Index.Pos)
+				self.report(ErrWrongFieldIndex{ErrBaseWrongFieldIndex_EnumShouldBeSubsequent}, field.Index.Pos)
 			}
 		}
 	}
@@ -279,47 +466,594 @@ func (self *Analyzer) analyzeFieldStmt(field *parser.FieldStmt, names *map[strin
 
 	def.Index = fieldIndex
 
+	self.analyzeFieldMetadata(field, def, typeModifier)
+
+	return def
+}
+
+// validateArguments checks that valueType, if it is a list or a map, declares the right arguments, and then
+// validates each argument that is a list or a map itself. decl is the declaration valueType was built from.
+func (self *Analyzer) validateArguments(valueType definition.PrimitiveValueType, decl *parser.DeclStmt) {
+	args := typeArguments(decl)
+	switch valueType.Primitive {
+	case definition.List, definition.Array:
+		if len(valueType.Arguments) != 1 {
+			self.report(ErrWrongArgumentsLen{valueType.Primitive, len(valueType.Arguments)}, decl.Pos)
+			return // stop because the next checks can fail if len(..) is 0
+		}
+
+	case definition.Map:
+		if len(valueType.Arguments) != 2 {
+			self.report(ErrWrongArgumentsLen{definition.Map, len(valueType.Arguments)}, decl.Pos)
+			return
+		}
+
+		self.validateMapKey(valueType.Arguments[0], &args[0])
+	}
+
+	for i, argument := range valueType.Arguments {
+		if primitive, ok := argument.(definition.PrimitiveValueType); ok {
+			self.validateArguments(primitive, &args[i])
+		}
+	}
+}
+
+// validateMapKey checks that key, declared at decl, is a non nullable string, int, enum or flags
+func (self *Analyzer) validateMapKey(key definition.BaseValueType, decl *parser.DeclStmt) {
+	var wrongKey bool
+	switch key := key.(type) {
+	case definition.PrimitiveValueType:
+		switch key.Primitive {
+		case definition.String,
+			definition.Int,
+			definition.Uint,
+			definition.Int8,
+			definition.Int16,
+			definition.Int32,
+			definition.Int64,
+			definition.Uint8,
+			definition.Uint16,
+			definition.Uint32,
+			definition.Uint64:
+
+			if key.Nullable {
+				wrongKey = true
+			}
+
+		default:
+			wrongKey = true
+		}
+
+	case definition.CustomValueType:
+		obj := self.getObject(decl, true)
+		if obj == nil {
+			break
+		}
+
+		switch obj.Source().Modifier {
+		case token.Enum, token.Flags:
+			if key.Nullable {
+				wrongKey = true
+			}
+
+		default:
+			self.report(ErrWrongMapKeyType{obj.Name, obj.Source().Modifier}, decl.Pos)
+		}
+	}
+
+	if wrongKey {
+		self.report(ErrWrongArguments{definition.Map, true}, decl.Pos)
+	}
+}
+
+// validateDefaultValue checks that the arrays, lists and strings of value, at any level, respect the lengths declared by valueType,
+// and that its numbers fit in the primitive they are assigned to
+func (self *Analyzer) validateDefaultValue(value parser.LiteralStmt, valueType definition.BaseValueType) {
+	primitive, ok := valueType.(definition.PrimitiveValueType)
+	if !ok {
+		return
+	}
+
+	switch literal := value.Kind.(type) {
+	case parser.IntLiteral:
+		if !fitsInPrimitive(literal.Value().(int64), primitive.Primitive) {
+			self.report(ErrDefaultOutOfRange{literal.Literal(), primitive.Primitive}, value.Pos)
+		}
+
+	case parser.UintLiteral:
+		if !uintFitsInPrimitive(primitive.Primitive) {
+			self.report(ErrDefaultOutOfRange{literal.Literal(), primitive.Primitive}, value.Pos)
+		}
+
+	case parser.FloatLiteral:
+		if primitive.Primitive == definition.Float32 && math.Abs(literal.Value().(float64)) > math.MaxFloat32 {
+			self.report(ErrDefaultOutOfRange{literal.Literal(), primitive.Primitive}, value.Pos)
+		}
+
+	case parser.StringLiteral:
+		length := utf8.RuneCountInString(literal.Literal())
+		if primitive.MaxLength > 0 && length > primitive.MaxLength {
+			self.report(ErrWrongDefaultLength{primitive.Primitive, primitive.MaxLength, length}, value.Pos)
+		}
+
+	case parser.ListLiteral:
+		length := len(literal)
+		if primitive.Primitive == definition.Array && primitive.Length > 0 && length != primitive.Length {
+			self.report(ErrWrongDefaultLength{primitive.Primitive, primitive.Length, length}, value.Pos)
+		} else if primitive.MaxLength > 0 && length > primitive.MaxLength {
+			self.report(ErrWrongDefaultLength{primitive.Primitive, primitive.MaxLength, length}, value.Pos)
+		}
+
+		if len(primitive.Arguments) == 1 {
+			for _, elem := range literal {
+				self.validateDefaultValue(elem, primitive.Arguments[0])
+			}
+		}
+
+	case parser.MapLiteral:
+		if len(primitive.Arguments) == 2 {
+			for _, entry := range literal {
+				self.validateDefaultValue(entry.Key, primitive.Arguments[0])
+				self.validateDefaultValue(entry.Value, primitive.Arguments[1])
+			}
+		}
+	}
+}
+
+// analyzeEnumAliasStmt analyses an enum member declared as an alias of another one, in order to match the following set of rules:
+//
+// 1- member names are not duplicated
+// 2- aliases do not declare an index, they share the one of the aliased member
+// 3- the aliased member is declared before the alias
+//
+// If suceeds, outputs a [definition.FieldDefinition]
+func (self *Analyzer) analyzeEnumAliasStmt(field *parser.FieldStmt, names *map[string]tokenizer.Pos, members []*definition.FieldDefinition) *definition.FieldDefinition {
+	def := new(definition.FieldDefinition)
+
+	// rule 1
+	fieldName := field.Name.Token.Literal
+	if previous, ok := (*names)[fieldName]; ok {
+		self.report(ErrAlreadyDefined{fieldName}, field.Name.Pos).note("previously defined here", previous)
+	} else {
+		(*names)[fieldName] = field.Name.Pos // update map for next field
+		def.Name = fieldName
+	}
+
+	// rule 2
+	if field.Index != nil {
+		self.report(ErrIndexedEnumAlias{fieldName}, field.Index.Pos)
+	}
+
+	// rule 3
+	aliasOf := field.AliasOf.Token.Literal
+	aliased := findMember(members, aliasOf)
+	if aliased == nil {
+		self.report(ErrUnknownEnumMember{aliasOf, token.Enum}, field.AliasOf.Pos)
+	} else {
+		def.Index = aliased.Index
+		def.AliasOf = aliased.Name
+
+		// an alias of an alias points to the original member
+		if len(aliased.AliasOf) > 0 {
+			def.AliasOf = aliased.AliasOf
+		}
+	}
+
+	self.analyzeFieldMetadata(field, def, token.Enum)
+
+	return def
+}
+
+// analyzeFlagsMemberStmt analyses a member of a flags type in order to match the following set of rules:
+//
+// 1- member names are not duplicated
+// 2- members that are not an alias nor a combination are single bits. If they do not declare their value,
+// they take the bit next to the highest one declared before, starting from 1
+// 3- single bit values fit in the underlying type and are not duplicated
+// 4- aliases and combinations do not declare a value, and the members they point to are declared before them
+//
+// The index of a flags member is its position in the type, its value is stored in Value.
+// If suceeds, outputs a [definition.FieldDefinition]
+func (self *Analyzer) analyzeFlagsMemberStmt(field *parser.FieldStmt, names *map[string]tokenizer.Pos, values *btree.Set[uint64], members []*definition.FieldDefinition, underlyingType definition.ValuePrimitive) *definition.FieldDefinition {
+	def := new(definition.FieldDefinition)
+	def.Index = len(members)
+
+	// rule 1
+	fieldName := field.Name.Token.Literal
+	if previous, ok := (*names)[fieldName]; ok {
+		self.report(ErrAlreadyDefined{fieldName}, field.Name.Pos).note("previously defined here", previous)
+	} else {
+		(*names)[fieldName] = field.Name.Pos // update map for next field
+		def.Name = fieldName
+	}
+
+	references := field.Combines
+	if field.AliasOf != nil {
+		references = []parser.IdentStmt{*field.AliasOf}
+	}
+
+	if len(references) > 0 {
+		// rule 4
+		if field.Index != nil {
+			self.report(ErrIndexedEnumAlias{fieldName}, field.Index.Pos)
+		}
+
+		for _, reference := range references {
+			member := findMember(members, reference.Token.Literal)
+			if member == nil {
+				self.report(ErrUnknownEnumMember{reference.Token.Literal, token.Flags}, reference.Pos)
+				continue
+			}
+
+			def.Value |= member.Value
+			if field.AliasOf == nil {
+				def.Combines = append(def.Combines, member.Name)
+			} else if len(member.AliasOf) > 0 {
+				def.AliasOf = member.AliasOf
+			} else {
+				def.AliasOf = member.Name
+			}
+		}
+	} else {
+		// rule 2
+		pos := field.Name.Pos
+		overflows := false
+		if field.Index == nil {
+			// the next bit of the highest value, or the first one if it is the first member
+			def.Value = 1
+			if highest, ok := values.GetAt(values.Len() - 1); ok {
+				// after the top bit of an uint64, shifting gives zero
+				overflows = bits.Len64(highest) == 64
+				def.Value = highest << 1
+			}
+		} else {
+			pos = field.Index.Pos
+			value, err := token.ParseUnsignedInteger(field.Index.Token.Literal, 64)
+			if err == nil {
+				def.Value = value
+			}
+		}
+
+		// rule 3
+		if overflows {
+			self.report(ErrFlagsMemberOverflow{fieldName, underlyingType}, pos)
+		} else if def.Value == 0 || def.Value&(def.Value-1) != 0 {
+			self.report(ErrFlagsMemberNotSingleBit{fieldName}, pos)
+		} else if bitSize, ok := flagsBitSizes[underlyingType]; ok && bits.Len64(def.Value) > bitSize {
+			self.report(ErrFlagsMemberOverflow{fieldName, underlyingType}, pos)
+		} else if values.Contains(def.Value) {
+			self.report(ErrWrongFieldIndex{ErrBaseWrongFieldIndex_DuplicatedIndex}, pos)
+		} else {
+			values.Insert(def.Value)
+		}
+	}
+
+	self.analyzeFieldMetadata(field, def, token.Flags)
+
+	return def
+}
+
+// analyzeOneofStmt analyses a OneofStmt declared in stmt in order to match the following set of rules:
+//
+// 1- group name is not duplicated, neither by another group nor by a field
+// 2- group declares at least one field
+// 3- group fields are not nullable
+// 4- at most one group field declares a default value
+//
+// Fields of the group were already analyzed with the rest of stmt's fields, so they share
+// their indexes. members are those definitions, which get marked as part of the group.
+//
+// If succeed, it outputs a valid definition.OneofDefinition
+func (self *Analyzer) analyzeOneofStmt(oneof *parser.OneofStmt, stmt *parser.TypeStmt, names *map[string]tokenizer.Pos, members []*definition.FieldDefinition) *definition.OneofDefinition {
+	def := new(definition.OneofDefinition)
+	def.Name = oneof.Name.Token.Literal
+
+	// rule 1
+	if previous, ok := (*names)[def.Name]; ok {
+		self.report(ErrAlreadyDefined{def.Name}, oneof.Name.Pos).note("previously defined here", previous)
+		return nil
+	}
+	(*names)[def.Name] = oneof.Name.Pos
+
+	for _, field := range stmt.Fields {
+		if field.Oneof == nil || field.Oneof.Token.Literal != def.Name {
+			continue
+		}
+
+		fieldName := field.Name.Token.Literal
+		member := findMember(members, fieldName)
+		if member == nil {
+			continue
+		}
+
+		// rule 3
+		if isNullable(member.Type) {
+			self.report(ErrNullableOneofField{fieldName, def.Name}, field.Name.Pos)
+		}
+
+		member.Oneof = def.Name
+		def.Fields = append(def.Fields, fieldName)
+	}
+
+	// rule 2
+	if len(def.Fields) == 0 {
+		self.report(ErrEmptyOneof{def.Name}, oneof.Name.Pos)
+	}
+
+	// rule 4
+	var defaultField string
+	for _, assignment := range stmt.Defaults {
+		fieldName := assignment.Left.Token.Literal
+		member := findMember(members, fieldName)
+		if member == nil || member.Oneof != def.Name {
+			continue
+		}
+
+		if len(defaultField) > 0 {
+			self.report(ErrOneofDefaultAlreadyDefined{def.Name, defaultField}, assignment.Left.Pos)
+			continue
+		}
+
+		defaultField = fieldName
+	}
+
+	if oneof.Documentation != nil {
+		def.Documentation = sanitizeComments(&oneof.Documentation)
+	}
+
+	if oneof.Annotations != nil {
+		annotations := make([]parser.AssignStmt, len(oneof.Annotations))
+		for i, annotation := range oneof.Annotations {
+			annotations[i] = annotation.Assigment
+		}
+		def.Annotations = self.getAssignments(&annotations, true)
+	}
+
+	return def
+}
+
+// isNullable reports whether valueType accepts null values
+func isNullable(valueType definition.BaseValueType) bool {
+	switch valueType := valueType.(type) {
+	case definition.PrimitiveValueType:
+		return valueType.Nullable
+
+	case definition.CustomValueType:
+		return valueType.Nullable
+	}
+
+	return false
+}
+
+// getFlagsUnderlyingType returns the primitive declared as the underlying type of a flags type, or uint32 if it is not declared
+func (self *Analyzer) getFlagsUnderlyingType(ident *parser.IdentStmt) definition.ValuePrimitive {
+	if ident == nil {
+		return definition.Uint32
+	}
+
+	primitive, _ := definition.ParsePrimitive(ident.Token.Literal)
+	if _, ok := flagsBitSizes[primitive]; !ok {
+		self.report(ErrWrongFlagsUnderlyingType{ident.Token.Literal}, ident.Pos)
+		return definition.Undefined
+	}
+
+	return primitive
+}
+
+// findMember returns the member called name in members, or nil if it is not found
+func findMember(members []*definition.FieldDefinition, name string) *definition.FieldDefinition {
+	for _, member := range members {
+		if member.Name == name {
+			return member
+		}
+	}
+
+	return nil
+}
+
+// analyzeFieldMetadata sets the documentation and annotations of field to def.
+// The default annotation of enum and union members is not included, it is read by getDefaultMember,
+// neither the tag annotation of union members, which is read by setUnionTags.
+func (self *Analyzer) analyzeFieldMetadata(field *parser.FieldStmt, def *definition.FieldDefinition, typeModifier token.TokenKind) {
 	if field.Documentation != nil {
 		def.Documentation = sanitizeComments(&field.Documentation)
+		def.Links = self.getDocumentationLinks(field.Documentation)
 	}
 
 	if field.Annotations != nil {
-		annotations := make([]parser.AssignStmt, len(field.Annotations))
-		for i, annotation := range field.Annotations {
-			annotations[i] = annotation.Assigment
+		annotations := make([]parser.AssignStmt, 0, len(field.Annotations))
+		for _, annotation := range field.Annotations {
+			key := annotation.Assigment.Left.Token.Literal
+			if (typeModifier == token.Enum || typeModifier == token.Union) && key == enumDefaultAnnotation {
+				continue
+			}
+
+			if typeModifier == token.Union && key == unionTagAnnotation {
+				continue
+			}
+
+			annotations = append(annotations, annotation.Assigment)
 		}
 		def.Annotations = self.getAssignments(&annotations, true)
 	}
+}
 
-	return def
+// getDefaultMember returns the name of the member of an enum or union annotated with #default = true,
+// or an empty string if there is no one.
+func (self *Analyzer) getDefaultMember(fields []parser.FieldStmt) string {
+	var defaultMember string
+	for _, field := range fields {
+		for _, annotation := range field.Annotations {
+			if annotation.Assigment.Left.Token.Literal != enumDefaultAnnotation {
+				continue
+			}
+
+			isDefault, ok := annotation.Assigment.Right.Kind.Value().(bool)
+			if !ok {
+				self.report(ErrWrongDefaultMemberValue{}, annotation.Assigment.Right.Pos)
+				continue
+			}
+
+			if !isDefault {
+				continue
+			}
+
+			if len(defaultMember) > 0 {
+				self.report(ErrDefaultMemberAlreadyDefined{defaultMember}, annotation.Pos)
+				continue
+			}
+
+			defaultMember = field.Name.Token.Literal
+		}
+	}
+
+	return defaultMember
+}
+
+// getUnionDiscriminator returns the value of the #discriminator annotation of a union, or an empty string if it is not declared
+func (self *Analyzer) getUnionDiscriminator(annotations []parser.AnnotationStmt) string {
+	for _, annotation := range annotations {
+		if annotation.Assigment.Left.Token.Literal != unionDiscriminatorAnnotation {
+			continue
+		}
+
+		discriminator, ok := annotation.Assigment.Right.Kind.Value().(string)
+		if !ok || len(discriminator) == 0 {
+			self.report(ErrWrongDiscriminatorValue{}, annotation.Assigment.Right.Pos)
+			return ""
+		}
+
+		return discriminator
+	}
+
+	return ""
+}
+
+// setUnionTags sets the tag of each union member in members, which is the value of its #tag annotation
+// or its name if it is not declared. fields are the statements members were built from.
+func (self *Analyzer) setUnionTags(fields []parser.FieldStmt, members []*definition.FieldDefinition) {
+	tags := map[string]string{} // tag -> member name
+	for _, field := range fields {
+		member := findMember(members, field.Name.Token.Literal)
+		if member == nil {
+			continue
+		}
+
+		tag := member.Name
+		tagPos := field.Name.Pos
+		for _, annotation := range field.Annotations {
+			if annotation.Assigment.Left.Token.Literal != unionTagAnnotation {
+				continue
+			}
+
+			value, ok := annotation.Assigment.Right.Kind.Value().(string)
+			if !ok || len(value) == 0 {
+				self.report(ErrWrongUnionTagValue{}, annotation.Assigment.Right.Pos)
+				continue
+			}
+
+			tag = value
+			tagPos = annotation.Pos
+		}
+
+		if name, ok := tags[tag]; ok {
+			self.report(ErrDuplicatedUnionTag{tag, name}, tagPos)
+			continue
+		}
+
+		tags[tag] = member.Name
+		member.Tag = tag
+	}
 }
 
-// getObject under the hood calls FindOjbect on self.currLocalScope and reports any error if any
-func (self *Analyzer) getObject(decl *parser.DeclStmt) *scope.Object {
+// getObject under the hood calls FindOjbect on self.currLocalScope and reports any error if any.
+// acceptsPrimitives tells if a primitive could have been written instead, so it can be suggested
+func (self *Analyzer) getObject(decl *parser.DeclStmt, acceptsPrimitives bool) *scope.Object {
 	name, alias := decl.Format()
 	obj, needAlias := self.currLocalScope.FindObject(name, alias)
 	if obj == nil {
 		if needAlias {
-			self.errors.push(ErrNeedAlias{}, decl.Pos)
+			self.report(ErrNeedAlias{}, decl.Pos)
 		} else {
-			self.errors.push(ErrTypeNotFound{name, alias}, decl.Pos)
+			err := ErrTypeNotFound{Name: name, Alias: alias}
+			err.Suggestion, err.Import = self.suggestType(name, alias, acceptsPrimitives)
+			self.report(err, decl.Pos)
 		}
 	} else {
-		if obj.Source().Modifier != token.Base {
-			self.errors.push(ErrNotValidBaseType{name, alias}, decl.Pos)
-		} else {
-			return obj
+		self.addReference(obj)
+	}
+
+	return obj
+}
+
+// addReference records that the file being analyzed references obj, unless it is the type being analyzed
+func (self *Analyzer) addReference(obj *scope.Object) {
+	if obj.Id != self.currTypeId {
+		self.currLocalScope.AddReference(obj)
+	}
+}
+
+// suggestType returns the type whose name is the closest to the one written, as it should be written, if any.
+// Types visible from self.currLocalScope, and primitives if acceptsPrimitives, are preferred. Otherwise, types
+// declared in a package not imported yet are looked up, returning also the use statement that imports it. The
+// statement only declares an alias, the name of the package, if the type was written with one.
+func (self *Analyzer) suggestType(name, alias string, acceptsPrimitives bool) (suggestion, importStmt string) {
+	fullName := name
+	if len(alias) > 0 {
+		fullName = alias + "." + name
+	}
+
+	candidates := self.currLocalScope.VisibleNames()
+	if acceptsPrimitives {
+		candidates = append(candidates, definition.PrimitiveNames()...)
+	}
+
+	suggestion, distance, ok := utils.Closest(fullName, candidates)
+	if !ok {
+		distance = math.MaxInt
+	}
+
+	for _, pkgScope := range self.scopes {
+		if pkgScope == self.currScope {
+			continue
+		}
+
+		if _, imported := (*self.currLocalScope.ResolvedScopes())[pkgScope]; imported {
+			continue
+		}
+
+		objects := pkgScope.GetAllObjects()
+		sort.Slice(objects, func(i, j int) bool {
+			return objects[i].Name < objects[j].Name
+		})
+
+		for _, obj := range objects {
+			qualifiedName := pkgScope.Name() + "." + obj.Name
+			candidate := obj.Name
+			if len(alias) > 0 {
+				candidate = qualifiedName
+			}
+
+			if _, d, ok := utils.Closest(fullName, []string{candidate}); ok && d < distance {
+				distance = d
+				if len(alias) == 0 {
+					// without an alias, the types of an imported package are referenced by their name
+					suggestion, importStmt = obj.Name, fmt.Sprintf("use %q", pkgScope.Path())
+				} else {
+					suggestion, importStmt = qualifiedName, fmt.Sprintf("use %q as %s", pkgScope.Path(), pkgScope.Name())
+				}
+			}
 		}
 	}
 
-	return nil
+	return suggestion, importStmt
 }
 
 func (self *Analyzer) getValueType(decl *parser.DeclStmt) definition.BaseValueType {
 	typeName, _ := decl.Format()
 	primitive, valid := definition.ParsePrimitive(typeName)
 	if !valid {
-		obj := self.getObject(decl)
+		obj := self.getObject(decl, true)
 		if obj != nil {
 			return definition.CustomValueType{ObjectId: obj.Id, Nullable: decl.Nullable}
 		}
@@ -329,14 +1063,19 @@ func (self *Analyzer) getValueType(decl *parser.DeclStmt) definition.BaseValueTy
 			Nullable:  decl.Nullable,
 		}
 
-		if len(decl.Args) > 0 {
-			valueType.Arguments = make([]definition.BaseValueType, len(decl.Args))
-			for i, arg := range decl.Args {
-				argType := self.getValueType(&arg)
-				if argType != nil {
-					valueType.Arguments[i] = argType
-				}
+		var hasLength bool
+		for i, arg := range decl.Args {
+			if arg.Token.Kind == token.Integer {
+				hasLength = true
+				self.setLength(&valueType, &arg, i == len(decl.Args)-1)
+				continue
 			}
+
+			valueType.Arguments = append(valueType.Arguments, self.getValueType(&arg))
+		}
+
+		if primitive == definition.Array && !hasLength {
+			self.report(ErrMissingArrayLength{}, decl.Pos)
 		}
 
 		return valueType
@@ -345,16 +1084,118 @@ func (self *Analyzer) getValueType(decl *parser.DeclStmt) definition.BaseValueTy
 	return nil
 }
 
-// sanitizeComments returns a []string from a []parser.CommentStmt, and trims every comment
+// setLength sets the length declared by arg to valueType. It is the number of elements of an array, or the
+// max length of a list or a string. isLast reports if arg is the last argument of the declaration.
+func (self *Analyzer) setLength(valueType *definition.PrimitiveValueType, arg *parser.DeclStmt, isLast bool) {
+	switch valueType.Primitive {
+	case definition.Array, definition.List, definition.String:
+		break
+
+	default:
+		self.report(ErrLengthNotAllowed{valueType.Primitive}, arg.Pos)
+		return
+	}
+
+	if !isLast {
+		self.report(ErrMisplacedLength{}, arg.Pos)
+		return
+	}
+
+	length, err := token.ParseInteger(arg.Token.Literal, 0)
+	if err != nil || length <= 0 {
+		self.report(ErrWrongLength{arg.Token.Literal}, arg.Pos)
+		return
+	}
+
+	if valueType.Primitive == definition.Array {
+		valueType.Length = int(length)
+	} else {
+		valueType.MaxLength = int(length)
+	}
+}
+
+// fitsInPrimitive reports whether value is in the range of primitive. Any value fits in non integer primitives
+func fitsInPrimitive(value int64, primitive definition.ValuePrimitive) bool {
+	switch primitive {
+	case definition.Int8:
+		return value >= math.MinInt8 && value <= math.MaxInt8
+	case definition.Int16:
+		return value >= math.MinInt16 && value <= math.MaxInt16
+	case definition.Int32:
+		return value >= math.MinInt32 && value <= math.MaxInt32
+	case definition.Uint8:
+		return value >= 0 && value <= math.MaxUint8
+	case definition.Uint16:
+		return value >= 0 && value <= math.MaxUint16
+	case definition.Uint32:
+		return value >= 0 && value <= math.MaxUint32
+	case definition.Uint, definition.Uint64:
+		return value >= 0
+	}
+
+	return true
+}
+
+// uintFitsInPrimitive reports whether an integer greater than the maximum int64 is in the range of primitive,
+// which happens only for 64 bits unsigned integers. Any value fits in non integer primitives
+func uintFitsInPrimitive(primitive definition.ValuePrimitive) bool {
+	switch primitive {
+	case definition.Int, definition.Int8, definition.Int16, definition.Int32, definition.Int64, definition.Uint8, definition.Uint16, definition.Uint32:
+		return false
+	}
+
+	return true
+}
+
+// typeArguments returns the arguments of decl that declare a type, leaving out lengths
+func typeArguments(decl *parser.DeclStmt) []parser.DeclStmt {
+	args := make([]parser.DeclStmt, 0, len(decl.Args))
+	for _, arg := range decl.Args {
+		if arg.Token.Kind != token.Integer {
+			args = append(args, arg)
+		}
+	}
+
+	return args
+}
+
+// sanitizeComments returns a []string from a []parser.CommentStmt, with the documentation written in them
 func sanitizeComments(arr *[]parser.CommentStmt) []string {
-	out := make([]string, len(*arr))
-	for i, e := range *arr {
-		out[i] = strings.TrimSpace(e.Token.Literal)
+	return parser.FormatDocumentation(*arr)
+}
+
+// getDocumentationLinks resolves the cross-references written in documentation. References that cannot be resolved
+// are skipped, because they were already reported by the linker
+func (self *Analyzer) getDocumentationLinks(comments []parser.CommentStmt) []definition.DocumentationLink {
+	var out []definition.DocumentationLink
+	for _, link := range parser.FindDocLinks(comments) {
+		obj, field, ok := self.currLocalScope.ResolveReference(link.Text)
+		if !ok {
+			continue
+		}
+
+		self.addReference(obj)
+
+		out = append(out, definition.DocumentationLink{
+			Text:   link.Text,
+			TypeId: obj.Id,
+			Field:  field,
+		})
 	}
 
 	return out
 }
 
+// isAnnotationValue returns true if value is a string, an integer, a float or a boolean, the values an annotation can hold
+func isAnnotationValue(value interface{}) bool {
+	switch value.(type) {
+	case string, int64, uint64, float64, bool:
+		return true
+	}
+
+	return false
+}
+
 // getAssignments takes a []parser.AssignStmt and outputs a valid definition.Assignments.
 // If isAnnotation is true, it will validate if the given assignments values are string, int64, float64 or boolean
 func (self *Analyzer) getAssignments(arr *[]parser.AssignStmt, isAnnotation bool) definition.Assignments {
@@ -363,20 +1204,14 @@ func (self *Analyzer) getAssignments(arr *[]parser.AssignStmt, isAnnotation bool
 		key := e.Left.Token.Literal
 		if _, ok := out[key]; ok {
 			// already declared
-			self.errors.push(ErrAssignmentKeyAlreadyInUse{key}, e.Left.Pos)
+			self.report(ErrAssignmentKeyAlreadyInUse{key}, e.Left.Pos)
 			continue
 		}
 
 		value := e.Right.Kind.Value()
-		if isAnnotation {
-			switch value.(type) {
-			case string, int64, float64, bool:
-				break
-
-			default:
-				self.errors.push(ErrWrongAnnotationValue{}, e.Right.Pos)
-				continue
-			}
+		if isAnnotation && !isAnnotationValue(value) {
+			self.report(ErrWrongAnnotationValue{}, e.Right.Pos)
+			continue
 		}
 
 		out[key] = value
@@ -384,12 +1219,3 @@ func (self *Analyzer) getAssignments(arr *[]parser.AssignStmt, isAnnotation bool
 
 	return out
 }
-
-func toInt(s string) int {
-	i, err := strconv.Atoi(s)
-	if err != nil {
-		panic(err)
-	}
-
-	return i
-}
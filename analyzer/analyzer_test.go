@@ -1,12 +1,15 @@
 package analyzer
 
 import (
+	"math"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/require"
 	"github.com/tidwall/btree"
 	"tomasweigenast.com/nexema/tool/definition"
+	"tomasweigenast.com/nexema/tool/diagnostic"
 	"tomasweigenast.com/nexema/tool/parser"
 	"tomasweigenast.com/nexema/tool/scope"
 	"tomasweigenast.com/nexema/tool/token"
@@ -14,6 +17,13 @@ import (
 )
 
 func TestAnalyzer_ValidateField(t *testing.T) {
+	objects := map[string]*scope.Object{
+		"Address":    newObject("Address", token.Struct),
+		"Payment":    newObject("Payment", token.Union),
+		"Status":     newObject("Status", token.Enum),
+		"Permission": newObject("Permission", token.Flags),
+	}
+
 	tests := []struct {
 		name         string
 		input        parser.FieldStmt
@@ -64,6 +74,45 @@ func TestAnalyzer_ValidateField(t *testing.T) {
 				NewAnalyzerError(ErrWrongFieldIndex{Err: ErrBaseWrongFieldIndex_DuplicatedIndex}, *tokenizer.NewPos()),
 			},
 		},
+		{
+			name: "field index out of the int32 range",
+			input: parser.FieldStmt{
+				Index:     &parser.IdentStmt{Token: *token.NewToken(token.Integer, "0x1_0000_0000_0000_0000")},
+				Name:      parser.IdentStmt{Token: *token.NewToken(token.Ident, "field_name")},
+				ValueType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "string"), Nullable: false},
+			},
+			typeModifier: token.Struct,
+			wantErrs: &AnalyzerErrorCollection{
+				NewAnalyzerError(ErrWrongFieldIndex{Err: ErrBaseWrongFieldIndex_OutOfRange}, *tokenizer.NewPos()),
+			},
+		},
+		{
+			name: "field index greater than the maximum int32",
+			input: parser.FieldStmt{
+				Index:     &parser.IdentStmt{Token: *token.NewToken(token.Integer, "2147483648")},
+				Name:      parser.IdentStmt{Token: *token.NewToken(token.Ident, "field_name")},
+				ValueType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "string"), Nullable: false},
+			},
+			typeModifier: token.Struct,
+			wantErrs: &AnalyzerErrorCollection{
+				NewAnalyzerError(ErrWrongFieldIndex{Err: ErrBaseWrongFieldIndex_OutOfRange}, *tokenizer.NewPos()),
+			},
+		},
+		{
+			name: "field index at the maximum int32",
+			input: parser.FieldStmt{
+				Index:     &parser.IdentStmt{Token: *token.NewToken(token.Integer, "2147483647")},
+				Name:      parser.IdentStmt{Token: *token.NewToken(token.Ident, "field_name")},
+				ValueType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "string"), Nullable: false},
+			},
+			typeModifier: token.Struct,
+			wantDef: &definition.FieldDefinition{
+				Name:  "field_name",
+				Index: math.MaxInt32,
+				Type:  definition.PrimitiveValueType{Primitive: definition.String},
+			},
+			wantErrs: newAnalyzerErrorCollection(),
+		},
 		{
 			name: "enum field that does not start with zero",
 			input: parser.FieldStmt{
@@ -140,7 +189,7 @@ func TestAnalyzer_ValidateField(t *testing.T) {
 			inputNames:   []string{"field_name"},
 			typeModifier: token.Struct,
 			wantErrs: &AnalyzerErrorCollection{
-				NewAnalyzerError(ErrAlreadyDefined{Name: "field_name"}, *tokenizer.NewPos()),
+				NewAnalyzerError(ErrAlreadyDefined{Name: "field_name"}, *tokenizer.NewPos()).note("previously defined here", *tokenizer.NewPos()),
 			},
 		},
 		{
@@ -170,7 +219,29 @@ func TestAnalyzer_ValidateField(t *testing.T) {
 			},
 		},
 		{
-			name: "list argument is not another list",
+			name: "misspelled primitives are suggested",
+			input: parser.FieldStmt{
+				Name:      parser.IdentStmt{Token: *token.NewToken(token.Ident, "field_name")},
+				ValueType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "strng")},
+			},
+			typeModifier: token.Struct,
+			wantErrs: &AnalyzerErrorCollection{
+				NewAnalyzerError(ErrTypeNotFound{Name: "strng", Suggestion: "string"}, *tokenizer.NewPos()),
+			},
+		},
+		{
+			name: "misspelled types are suggested",
+			input: parser.FieldStmt{
+				Name:      parser.IdentStmt{Token: *token.NewToken(token.Ident, "field_name")},
+				ValueType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "Adress")},
+			},
+			typeModifier: token.Struct,
+			wantErrs: &AnalyzerErrorCollection{
+				NewAnalyzerError(ErrTypeNotFound{Name: "Adress", Suggestion: "Address"}, *tokenizer.NewPos()),
+			},
+		},
+		{
+			name: "list of lists",
 			input: parser.FieldStmt{
 				Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "field_name")},
 				ValueType: &parser.DeclStmt{
@@ -178,14 +249,131 @@ func TestAnalyzer_ValidateField(t *testing.T) {
 					Args: []parser.DeclStmt{
 						{
 							Token: *token.NewToken(token.Ident, "list"),
+							Args:  []parser.DeclStmt{{Token: *token.NewToken(token.Ident, "varint")}},
 						},
 					},
 				},
 			},
 			typeModifier: token.Struct,
-			wantDef:      nil,
+			wantDef: &definition.FieldDefinition{
+				Name: "field_name",
+				Type: definition.PrimitiveValueType{
+					Primitive: definition.List,
+					Arguments: []definition.BaseValueType{
+						definition.PrimitiveValueType{
+							Primitive: definition.List,
+							Arguments: []definition.BaseValueType{definition.PrimitiveValueType{Primitive: definition.Int}},
+						},
+					},
+				},
+			},
+			wantErrs: newAnalyzerErrorCollection(),
+		},
+		{
+			name: "list of nullable elements",
+			input: parser.FieldStmt{
+				Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "field_name")},
+				ValueType: &parser.DeclStmt{
+					Token: *token.NewToken(token.Ident, "list"),
+					Args:  []parser.DeclStmt{{Token: *token.NewToken(token.Ident, "string"), Nullable: true}},
+				},
+			},
+			typeModifier: token.Struct,
+			wantDef: &definition.FieldDefinition{
+				Name: "field_name",
+				Type: definition.PrimitiveValueType{
+					Primitive: definition.List,
+					Arguments: []definition.BaseValueType{definition.PrimitiveValueType{Primitive: definition.String, Nullable: true}},
+				},
+			},
+			wantErrs: newAnalyzerErrorCollection(),
+		},
+		{
+			name: "map values can be lists and maps",
+			input: parser.FieldStmt{
+				Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "field_name")},
+				ValueType: &parser.DeclStmt{
+					Token: *token.NewToken(token.Ident, "map"),
+					Args: []parser.DeclStmt{
+						{Token: *token.NewToken(token.Ident, "string")},
+						{
+							Token: *token.NewToken(token.Ident, "map"),
+							Args: []parser.DeclStmt{
+								{Token: *token.NewToken(token.Ident, "int32")},
+								{
+									Token:    *token.NewToken(token.Ident, "list"),
+									Args:     []parser.DeclStmt{{Token: *token.NewToken(token.Ident, "string"), Nullable: true}},
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+			},
+			typeModifier: token.Struct,
+			wantDef: &definition.FieldDefinition{
+				Name: "field_name",
+				Type: definition.PrimitiveValueType{
+					Primitive: definition.Map,
+					Arguments: []definition.BaseValueType{
+						definition.PrimitiveValueType{Primitive: definition.String},
+						definition.PrimitiveValueType{
+							Primitive: definition.Map,
+							Arguments: []definition.BaseValueType{
+								definition.PrimitiveValueType{Primitive: definition.Int32},
+								definition.PrimitiveValueType{
+									Primitive: definition.List,
+									Nullable:  true,
+									Arguments: []definition.BaseValueType{definition.PrimitiveValueType{Primitive: definition.String, Nullable: true}},
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErrs: newAnalyzerErrorCollection(),
+		},
+		{
+			name: "nested arguments are validated",
+			input: parser.FieldStmt{
+				Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "field_name")},
+				ValueType: &parser.DeclStmt{
+					Token: *token.NewToken(token.Ident, "list"),
+					Args: []parser.DeclStmt{
+						{
+							Token: *token.NewToken(token.Ident, "map"),
+							Args: []parser.DeclStmt{
+								{Token: *token.NewToken(token.Ident, "string"), Nullable: true},
+								{Token: *token.NewToken(token.Ident, "list")},
+							},
+						},
+					},
+				},
+			},
+			typeModifier: token.Struct,
+			wantErrs: &AnalyzerErrorCollection{
+				NewAnalyzerError(ErrWrongArguments{Primitive: definition.Map, IsMapKey: true}, *tokenizer.NewPos()),
+				NewAnalyzerError(ErrWrongArgumentsLen{Primitive: definition.List, ArgumentsLen: 0}, *tokenizer.NewPos()),
+			},
+		},
+		{
+			name: "map keys cannot be lists",
+			input: parser.FieldStmt{
+				Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "field_name")},
+				ValueType: &parser.DeclStmt{
+					Token: *token.NewToken(token.Ident, "map"),
+					Args: []parser.DeclStmt{
+						{
+							Token: *token.NewToken(token.Ident, "list"),
+							Args:  []parser.DeclStmt{{Token: *token.NewToken(token.Ident, "string")}},
+						},
+						{Token: *token.NewToken(token.Ident, "string")},
+					},
+				},
+			},
+			typeModifier: token.Struct,
 			wantErrs: &AnalyzerErrorCollection{
-				NewAnalyzerError(ErrWrongArguments{Primitive: definition.List}, *tokenizer.NewPos()),
+				NewAnalyzerError(ErrWrongArguments{Primitive: definition.Map, IsMapKey: true}, *tokenizer.NewPos()),
 			},
 		},
 		{
@@ -275,29 +463,84 @@ func TestAnalyzer_ValidateField(t *testing.T) {
 			},
 		},
 		{
-			name: "map value cannot be another map",
+			name: "array with length",
 			input: parser.FieldStmt{
 				Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "field_name")},
 				ValueType: &parser.DeclStmt{
-					Token: *token.NewToken(token.Ident, "map"),
+					Token: *token.NewToken(token.Ident, "array"),
+					Args: []parser.DeclStmt{
+						{Token: *token.NewToken(token.Ident, "float32")},
+						{Token: *token.NewToken(token.Integer, "3")},
+					},
+				},
+			},
+			typeModifier: token.Struct,
+			wantDef: &definition.FieldDefinition{
+				Name: "field_name",
+				Type: definition.PrimitiveValueType{
+					Primitive: definition.Array,
+					Arguments: []definition.BaseValueType{definition.PrimitiveValueType{Primitive: definition.Float32}},
+					Length:    3,
+				},
+			},
+			wantErrs: newAnalyzerErrorCollection(),
+		},
+		{
+			name: "bounded list of bounded strings",
+			input: parser.FieldStmt{
+				Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "field_name")},
+				ValueType: &parser.DeclStmt{
+					Token: *token.NewToken(token.Ident, "list"),
 					Args: []parser.DeclStmt{
 						{
 							Token: *token.NewToken(token.Ident, "string"),
+							Args:  []parser.DeclStmt{{Token: *token.NewToken(token.Integer, "64")}},
 						},
-						{
-							Token: *token.NewToken(token.Ident, "map"),
-						},
+						{Token: *token.NewToken(token.Integer, "10")},
 					},
 				},
 			},
 			typeModifier: token.Struct,
-			wantDef:      nil,
+			wantDef: &definition.FieldDefinition{
+				Name: "field_name",
+				Type: definition.PrimitiveValueType{
+					Primitive: definition.List,
+					Arguments: []definition.BaseValueType{definition.PrimitiveValueType{Primitive: definition.String, MaxLength: 64}},
+					MaxLength: 10,
+				},
+			},
+			wantErrs: newAnalyzerErrorCollection(),
+		},
+		{
+			name: "array must declare its length",
+			input: parser.FieldStmt{
+				Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "field_name")},
+				ValueType: &parser.DeclStmt{
+					Token: *token.NewToken(token.Ident, "array"),
+					Args:  []parser.DeclStmt{{Token: *token.NewToken(token.Ident, "float32")}},
+				},
+			},
+			typeModifier: token.Struct,
+			wantErrs: &AnalyzerErrorCollection{
+				NewAnalyzerError(ErrMissingArrayLength{}, *tokenizer.NewPos()),
+			},
+		},
+		{
+			name: "array expects exactly one argument",
+			input: parser.FieldStmt{
+				Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "field_name")},
+				ValueType: &parser.DeclStmt{
+					Token: *token.NewToken(token.Ident, "array"),
+					Args:  []parser.DeclStmt{{Token: *token.NewToken(token.Integer, "3")}},
+				},
+			},
+			typeModifier: token.Struct,
 			wantErrs: &AnalyzerErrorCollection{
-				NewAnalyzerError(ErrWrongArguments{Primitive: definition.Map}, *tokenizer.NewPos()),
+				NewAnalyzerError(ErrWrongArgumentsLen{Primitive: definition.Array, ArgumentsLen: 0}, *tokenizer.NewPos()),
 			},
 		},
 		{
-			name: "map value cannot be a list",
+			name: "wrong lengths",
 			input: parser.FieldStmt{
 				Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "field_name")},
 				ValueType: &parser.DeclStmt{
@@ -305,103 +548,947 @@ func TestAnalyzer_ValidateField(t *testing.T) {
 					Args: []parser.DeclStmt{
 						{
 							Token: *token.NewToken(token.Ident, "string"),
+							Args:  []parser.DeclStmt{{Token: *token.NewToken(token.Integer, "0")}},
 						},
 						{
 							Token: *token.NewToken(token.Ident, "list"),
+							Args: []parser.DeclStmt{
+								{Token: *token.NewToken(token.Integer, "2")},
+								{Token: *token.NewToken(token.Ident, "bool"), Args: []parser.DeclStmt{{Token: *token.NewToken(token.Integer, "1")}}},
+							},
 						},
+						{Token: *token.NewToken(token.Integer, "5")},
 					},
 				},
 			},
 			typeModifier: token.Struct,
-			wantDef:      nil,
 			wantErrs: &AnalyzerErrorCollection{
-				NewAnalyzerError(ErrWrongArguments{Primitive: definition.Map}, *tokenizer.NewPos()),
+				NewAnalyzerError(ErrWrongLength{Length: "0"}, *tokenizer.NewPos()),
+				NewAnalyzerError(ErrMisplacedLength{}, *tokenizer.NewPos()),
+				NewAnalyzerError(ErrLengthNotAllowed{Primitive: definition.Boolean}, *tokenizer.NewPos()),
+				NewAnalyzerError(ErrLengthNotAllowed{Primitive: definition.Map}, *tokenizer.NewPos()),
 			},
 		},
-	}
-
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			analyzer := NewAnalyzer([]*scope.Scope{})
-			analyzer.currScope = &scope.Scope{}
-			analyzer.currLocalScope = &scope.LocalScope{}
-			names := map[string]bool{}
-			indexes := btree.Set[int]{}
-
-			for _, name := range test.inputNames {
-				names[name] = true
-			}
-
-			for _, idx := range test.inputIndexes {
-				indexes.Insert(idx)
-			}
-
-			gotDef := analyzer.analyzeFieldStmt(&test.input, &names, &indexes, test.typeModifier)
-			if diff := cmp.Diff(test.wantErrs, analyzer.errors, cmpopts.EquateErrors()); diff != "" {
-				t.Errorf("TestAnalyzer_TestValidateField: %s: wantErrs mismatch (-want +got):\n%s", test.name, diff)
-			}
-
-			if analyzer.errors.IsEmpty() {
-				if diff := cmp.Diff(test.wantDef, gotDef); diff != "" {
-					t.Errorf("TestAnalyzer_TestValidateField: %s: wantDef mismatch (-want +got):\n%s", test.name, diff)
-				}
-			}
-		})
-	}
-}
-
-func TestAnalyzer_ValidateType(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    parser.TypeStmt
-		wantDef  *definition.TypeDefinition
-		wantErrs *AnalyzerErrorCollection
-	}{
 		{
-			name: "modifier must be struct, union, base or enum",
-			input: parser.TypeStmt{
-				Name:     parser.IdentStmt{Token: *token.NewToken(token.Ident, "A")},
-				Modifier: token.As,
+			name: "custom types are valid field types",
+			input: parser.FieldStmt{
+				Name:      parser.IdentStmt{Token: *token.NewToken(token.Ident, "field_name")},
+				ValueType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "Address")},
 			},
-			wantErrs: &AnalyzerErrorCollection{
-				NewAnalyzerError(ErrUnknownTypeModifier{Token: token.As}, *tokenizer.NewPos()),
+			typeModifier: token.Struct,
+			wantDef: &definition.FieldDefinition{
+				Name: "field_name",
+				Type: definition.CustomValueType{ObjectId: objects["Address"].Id},
 			},
+			wantErrs: newAnalyzerErrorCollection(),
 		},
 		{
-			name: "base struct must exists",
-			input: parser.TypeStmt{
-				Name:     parser.IdentStmt{Token: *token.NewToken(token.Ident, "A")},
-				Modifier: token.Struct,
-				BaseType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "B")},
+			name: "enums are valid map keys",
+			input: parser.FieldStmt{
+				Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "field_name")},
+				ValueType: &parser.DeclStmt{
+					Token: *token.NewToken(token.Ident, "map"),
+					Args: []parser.DeclStmt{
+						{Token: *token.NewToken(token.Ident, "Status")},
+						{Token: *token.NewToken(token.Ident, "int32")},
+					},
+				},
 			},
-			wantErrs: &AnalyzerErrorCollection{
-				NewAnalyzerError(ErrTypeNotFound{Name: "B"}, *tokenizer.NewPos()),
+			typeModifier: token.Struct,
+			wantDef: &definition.FieldDefinition{
+				Name: "field_name",
+				Type: definition.PrimitiveValueType{
+					Primitive: definition.Map,
+					Arguments: []definition.BaseValueType{
+						definition.CustomValueType{ObjectId: objects["Status"].Id},
+						definition.PrimitiveValueType{Primitive: definition.Int32},
+					},
+				},
 			},
+			wantErrs: newAnalyzerErrorCollection(),
 		},
-	}
-
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			analyzer := NewAnalyzer([]*scope.Scope{})
-			analyzer.currScope = &scope.Scope{}
-			analyzer.currLocalScope = &scope.LocalScope{}
-
-			gotDef := analyzer.analyzeTypeStmt(&test.input)
-			if diff := cmp.Diff(test.wantErrs, analyzer.errors, cmpopts.EquateErrors()); diff != "" {
-				t.Errorf("TestAnalyzer_TestValidateType: %s: wantErrs mismatch (-want +got):\n%s", test.name, diff)
-			}
-
-			if analyzer.errors.IsEmpty() {
-				if diff := cmp.Diff(test.wantDef, gotDef); diff != "" {
-					t.Errorf("TestAnalyzer_TestValidateType: %s: wantDef mismatch (-want +got):\n%s", test.name, diff)
-				}
-			}
-		})
-	}
-}
-
-func TestAnalyzer_GetAssignments(t *testing.T) {
-	tests := []struct {
+		{
+			name: "flags are valid map keys",
+			input: parser.FieldStmt{
+				Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "field_name")},
+				ValueType: &parser.DeclStmt{
+					Token: *token.NewToken(token.Ident, "map"),
+					Args: []parser.DeclStmt{
+						{Token: *token.NewToken(token.Ident, "Permission")},
+						{Token: *token.NewToken(token.Ident, "bool")},
+					},
+				},
+			},
+			typeModifier: token.Struct,
+			wantDef: &definition.FieldDefinition{
+				Name: "field_name",
+				Type: definition.PrimitiveValueType{
+					Primitive: definition.Map,
+					Arguments: []definition.BaseValueType{
+						definition.CustomValueType{ObjectId: objects["Permission"].Id},
+						definition.PrimitiveValueType{Primitive: definition.Boolean},
+					},
+				},
+			},
+			wantErrs: newAnalyzerErrorCollection(),
+		},
+		{
+			name: "enum map keys cannot be nullable",
+			input: parser.FieldStmt{
+				Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "field_name")},
+				ValueType: &parser.DeclStmt{
+					Token: *token.NewToken(token.Ident, "map"),
+					Args: []parser.DeclStmt{
+						{Token: *token.NewToken(token.Ident, "Status"), Nullable: true},
+						{Token: *token.NewToken(token.Ident, "int32")},
+					},
+				},
+			},
+			typeModifier: token.Struct,
+			wantErrs: &AnalyzerErrorCollection{
+				NewAnalyzerError(ErrWrongArguments{Primitive: definition.Map, IsMapKey: true}, *tokenizer.NewPos()),
+			},
+		},
+		{
+			name: "structs are not valid map keys",
+			input: parser.FieldStmt{
+				Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "field_name")},
+				ValueType: &parser.DeclStmt{
+					Token: *token.NewToken(token.Ident, "map"),
+					Args: []parser.DeclStmt{
+						{Token: *token.NewToken(token.Ident, "Address")},
+						{Token: *token.NewToken(token.Ident, "string")},
+					},
+				},
+			},
+			typeModifier: token.Struct,
+			wantErrs: &AnalyzerErrorCollection{
+				NewAnalyzerError(ErrWrongMapKeyType{TypeName: "Address", Modifier: token.Struct}, *tokenizer.NewPos()),
+			},
+		},
+		{
+			name: "unions are not valid map keys",
+			input: parser.FieldStmt{
+				Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "field_name")},
+				ValueType: &parser.DeclStmt{
+					Token: *token.NewToken(token.Ident, "map"),
+					Args: []parser.DeclStmt{
+						{Token: *token.NewToken(token.Ident, "Payment")},
+						{Token: *token.NewToken(token.Ident, "string")},
+					},
+				},
+			},
+			typeModifier: token.Struct,
+			wantErrs: &AnalyzerErrorCollection{
+				NewAnalyzerError(ErrWrongMapKeyType{TypeName: "Payment", Modifier: token.Union}, *tokenizer.NewPos()),
+			},
+		},
+		{
+			name: "map keys must be existing types",
+			input: parser.FieldStmt{
+				Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "field_name")},
+				ValueType: &parser.DeclStmt{
+					Token: *token.NewToken(token.Ident, "map"),
+					Args: []parser.DeclStmt{
+						{Token: *token.NewToken(token.Ident, "Unknown")},
+						{Token: *token.NewToken(token.Ident, "string")},
+					},
+				},
+			},
+			typeModifier: token.Struct,
+			wantErrs: &AnalyzerErrorCollection{
+				NewAnalyzerError(ErrTypeNotFound{Name: "Unknown"}, *tokenizer.NewPos()),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			analyzer := NewAnalyzer([]*scope.Scope{})
+			analyzer.currScope = &scope.Scope{}
+			analyzer.currLocalScope = scope.NewLocalScope(nil, nil, objects)
+			names := map[string]tokenizer.Pos{}
+			indexes := btree.Set[int]{}
+
+			for _, name := range test.inputNames {
+				names[name] = *tokenizer.NewPos()
+			}
+
+			for _, idx := range test.inputIndexes {
+				indexes.Insert(idx)
+			}
+
+			gotDef := analyzer.analyzeFieldStmt(&test.input, &names, &indexes, test.typeModifier)
+			if diff := cmp.Diff(test.wantErrs, analyzer.errors, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("TestAnalyzer_TestValidateField: %s: wantErrs mismatch (-want +got):\n%s", test.name, diff)
+			}
+
+			if analyzer.errors.IsEmpty() {
+				if diff := cmp.Diff(test.wantDef, gotDef); diff != "" {
+					t.Errorf("TestAnalyzer_TestValidateField: %s: wantDef mismatch (-want +got):\n%s", test.name, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyzer_ValidateType(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        parser.TypeStmt
+		localObjects map[string]*scope.Object
+		wantDef      *definition.TypeDefinition
+		wantErrs     *AnalyzerErrorCollection
+	}{
+		{
+			name: "modifier must be struct, union, base or enum",
+			input: parser.TypeStmt{
+				Name:     parser.IdentStmt{Token: *token.NewToken(token.Ident, "A")},
+				Modifier: token.As,
+			},
+			wantErrs: &AnalyzerErrorCollection{
+				NewAnalyzerError(ErrUnknownTypeModifier{Token: token.As}, *tokenizer.NewPos()),
+			},
+		},
+		{
+			name: "base struct must exists",
+			input: parser.TypeStmt{
+				Name:     parser.IdentStmt{Token: *token.NewToken(token.Ident, "A")},
+				Modifier: token.Struct,
+				BaseType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "B")},
+			},
+			wantErrs: &AnalyzerErrorCollection{
+				NewAnalyzerError(ErrTypeNotFound{Name: "B"}, *tokenizer.NewPos()),
+			},
+		},
+		{
+			name: "struct can only extend base types",
+			input: parser.TypeStmt{
+				Name:     parser.IdentStmt{Token: *token.NewToken(token.Ident, "A")},
+				Modifier: token.Struct,
+				BaseType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "B")},
+			},
+			localObjects: map[string]*scope.Object{"B": newObject("B", token.Struct)},
+			wantErrs: &AnalyzerErrorCollection{
+				NewAnalyzerError(ErrNotValidBaseType{Name: "B"}, *tokenizer.NewPos()),
+			},
+		},
+		{
+			name: "defaults must respect declared lengths",
+			input: parser.TypeStmt{
+				Name:     parser.IdentStmt{Token: *token.NewToken(token.Ident, "A")},
+				Modifier: token.Struct,
+				Fields: []parser.FieldStmt{
+					{
+						Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "position")},
+						ValueType: &parser.DeclStmt{
+							Token: *token.NewToken(token.Ident, "array"),
+							Args: []parser.DeclStmt{
+								{Token: *token.NewToken(token.Ident, "float32")},
+								{Token: *token.NewToken(token.Integer, "3")},
+							},
+						},
+					},
+					{
+						Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "tags")},
+						ValueType: &parser.DeclStmt{
+							Token: *token.NewToken(token.Ident, "list"),
+							Args: []parser.DeclStmt{
+								{
+									Token: *token.NewToken(token.Ident, "string"),
+									Args:  []parser.DeclStmt{{Token: *token.NewToken(token.Integer, "3")}},
+								},
+								{Token: *token.NewToken(token.Integer, "2")},
+							},
+						},
+					},
+					{
+						Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "greeting")},
+						ValueType: &parser.DeclStmt{
+							Token: *token.NewToken(token.Ident, "string"),
+							Args:  []parser.DeclStmt{{Token: *token.NewToken(token.Integer, "5")}},
+						},
+					},
+					{
+						Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "farewell")},
+						ValueType: &parser.DeclStmt{
+							Token: *token.NewToken(token.Ident, "string"),
+							Args:  []parser.DeclStmt{{Token: *token.NewToken(token.Integer, "5")}},
+						},
+					},
+				},
+				Defaults: []parser.AssignStmt{
+					{
+						Left: parser.IdentStmt{Token: *token.NewToken(token.Ident, "position")},
+						Right: parser.LiteralStmt{Kind: parser.MakeListLiteral(
+							parser.LiteralStmt{Kind: parser.MakeFloatLiteral(1)},
+							parser.LiteralStmt{Kind: parser.MakeFloatLiteral(2)},
+						)},
+					},
+					{
+						Left: parser.IdentStmt{Token: *token.NewToken(token.Ident, "tags")},
+						Right: parser.LiteralStmt{Kind: parser.MakeListLiteral(
+							parser.LiteralStmt{Kind: parser.MakeStringLiteral("a")},
+							parser.LiteralStmt{Kind: parser.MakeStringLiteral("long")},
+							parser.LiteralStmt{Kind: parser.MakeStringLiteral("c")},
+						)},
+					},
+					{
+						Left:  parser.IdentStmt{Token: *token.NewToken(token.Ident, "greeting")},
+						Right: parser.LiteralStmt{Kind: parser.MakeStringLiteral("héllo")},
+					},
+					{
+						Left:  parser.IdentStmt{Token: *token.NewToken(token.Ident, "farewell")},
+						Right: parser.LiteralStmt{Kind: parser.MakeStringLiteral("adiós!")},
+					},
+				},
+			},
+			wantErrs: &AnalyzerErrorCollection{
+				NewAnalyzerError(ErrWrongDefaultLength{Primitive: definition.Array, Expected: 3, Got: 2}, *tokenizer.NewPos()),
+				NewAnalyzerError(ErrWrongDefaultLength{Primitive: definition.List, Expected: 2, Got: 3}, *tokenizer.NewPos()),
+				NewAnalyzerError(ErrWrongDefaultLength{Primitive: definition.String, Expected: 3, Got: 4}, *tokenizer.NewPos()),
+				NewAnalyzerError(ErrWrongDefaultLength{Primitive: definition.String, Expected: 5, Got: 6}, *tokenizer.NewPos()),
+			},
+		},
+		{
+			name: "default numbers must fit in their primitive",
+			input: parser.TypeStmt{
+				Name:     parser.IdentStmt{Token: *token.NewToken(token.Ident, "A")},
+				Modifier: token.Struct,
+				Fields: []parser.FieldStmt{
+					{
+						Name:      parser.IdentStmt{Token: *token.NewToken(token.Ident, "small")},
+						ValueType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "int8")},
+					},
+					{
+						Name:      parser.IdentStmt{Token: *token.NewToken(token.Ident, "mask")},
+						ValueType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "uint8")},
+					},
+					{
+						Name:      parser.IdentStmt{Token: *token.NewToken(token.Ident, "count")},
+						ValueType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "uint32")},
+					},
+					{
+						Name:      parser.IdentStmt{Token: *token.NewToken(token.Ident, "ratio")},
+						ValueType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "float32")},
+					},
+					{
+						Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "bytes")},
+						ValueType: &parser.DeclStmt{
+							Token: *token.NewToken(token.Ident, "list"),
+							Args:  []parser.DeclStmt{{Token: *token.NewToken(token.Ident, "uint8")}},
+						},
+					},
+					{
+						Name:      parser.IdentStmt{Token: *token.NewToken(token.Ident, "offset")},
+						ValueType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "int64")},
+					},
+					{
+						Name:      parser.IdentStmt{Token: *token.NewToken(token.Ident, "limit")},
+						ValueType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "int64")},
+					},
+					{
+						Name:      parser.IdentStmt{Token: *token.NewToken(token.Ident, "id")},
+						ValueType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "uint64")},
+					},
+					{
+						Name:      parser.IdentStmt{Token: *token.NewToken(token.Ident, "big")},
+						ValueType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "int64")},
+					},
+					{
+						Name:      parser.IdentStmt{Token: *token.NewToken(token.Ident, "half")},
+						ValueType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "uint32")},
+					},
+				},
+				Defaults: []parser.AssignStmt{
+					{
+						Left:  parser.IdentStmt{Token: *token.NewToken(token.Ident, "small")},
+						Right: parser.LiteralStmt{Kind: parser.MakeIntLiteral(-129)},
+					},
+					{
+						Left:  parser.IdentStmt{Token: *token.NewToken(token.Ident, "mask")},
+						Right: parser.LiteralStmt{Kind: parser.MakeIntLiteral(0xFF)},
+					},
+					{
+						Left:  parser.IdentStmt{Token: *token.NewToken(token.Ident, "count")},
+						Right: parser.LiteralStmt{Kind: parser.MakeIntLiteral(-1)},
+					},
+					{
+						Left:  parser.IdentStmt{Token: *token.NewToken(token.Ident, "ratio")},
+						Right: parser.LiteralStmt{Kind: parser.MakeFloatLiteral(1e39)},
+					},
+					{
+						Left: parser.IdentStmt{Token: *token.NewToken(token.Ident, "bytes")},
+						Right: parser.LiteralStmt{Kind: parser.MakeListLiteral(
+							parser.LiteralStmt{Kind: parser.MakeIntLiteral(0)},
+							parser.LiteralStmt{Kind: parser.MakeIntLiteral(256)},
+						)},
+					},
+					{
+						Left:  parser.IdentStmt{Token: *token.NewToken(token.Ident, "offset")},
+						Right: parser.LiteralStmt{Kind: parser.MakeIntLiteral(math.MinInt64)},
+					},
+					{
+						Left:  parser.IdentStmt{Token: *token.NewToken(token.Ident, "limit")},
+						Right: parser.LiteralStmt{Kind: parser.MakeIntLiteral(math.MaxInt64)},
+					},
+					{
+						Left:  parser.IdentStmt{Token: *token.NewToken(token.Ident, "id")},
+						Right: parser.LiteralStmt{Kind: parser.MakeUintLiteral(math.MaxUint64)},
+					},
+					{
+						Left:  parser.IdentStmt{Token: *token.NewToken(token.Ident, "big")},
+						Right: parser.LiteralStmt{Kind: parser.MakeUintLiteral(math.MaxUint64)},
+					},
+					{
+						Left:  parser.IdentStmt{Token: *token.NewToken(token.Ident, "half")},
+						Right: parser.LiteralStmt{Kind: parser.MakeUintLiteral(1 << 63)},
+					},
+				},
+			},
+			wantErrs: &AnalyzerErrorCollection{
+				NewAnalyzerError(ErrDefaultOutOfRange{Value: "-129", Primitive: definition.Int8}, *tokenizer.NewPos()),
+				NewAnalyzerError(ErrDefaultOutOfRange{Value: "-1", Primitive: definition.Uint32}, *tokenizer.NewPos()),
+				NewAnalyzerError(ErrDefaultOutOfRange{Value: "1e+39", Primitive: definition.Float32}, *tokenizer.NewPos()),
+				NewAnalyzerError(ErrDefaultOutOfRange{Value: "256", Primitive: definition.Uint8}, *tokenizer.NewPos()),
+				NewAnalyzerError(ErrDefaultOutOfRange{Value: "18446744073709551615", Primitive: definition.Int64}, *tokenizer.NewPos()),
+				NewAnalyzerError(ErrDefaultOutOfRange{Value: "9223372036854775808", Primitive: definition.Uint32}, *tokenizer.NewPos()),
+			},
+		},
+		{
+			name: "enum default member is the first one",
+			input: parser.TypeStmt{
+				Name:     parser.IdentStmt{Token: *token.NewToken(token.Ident, "Color")},
+				Modifier: token.Enum,
+				Fields: []parser.FieldStmt{
+					{Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "unknown")}},
+					{Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "red")}},
+				},
+			},
+			wantDef: &definition.TypeDefinition{
+				Name:     "Color",
+				Modifier: token.Enum,
+				Fields: []*definition.FieldDefinition{
+					{Name: "unknown", Index: 0},
+					{Name: "red", Index: 1},
+				},
+				DefaultMember: "unknown",
+			},
+			wantErrs: newAnalyzerErrorCollection(),
+		},
+		{
+			name: "enum with annotated members, default member and aliases",
+			input: parser.TypeStmt{
+				Name:     parser.IdentStmt{Token: *token.NewToken(token.Ident, "Color")},
+				Modifier: token.Enum,
+				Fields: []parser.FieldStmt{
+					{Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "unknown")}},
+					{
+						Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "red")},
+						Documentation: []parser.CommentStmt{
+							{Token: *token.NewToken(token.DocComment, " The red color")},
+						},
+						Annotations: []parser.AnnotationStmt{
+							newAnnotation("default", parser.MakeBooleanLiteral(true)),
+							newAnnotation("display_name", parser.MakeStringLiteral("Red")),
+						},
+					},
+					{
+						Name:    parser.IdentStmt{Token: *token.NewToken(token.Ident, "rojo")},
+						AliasOf: &parser.IdentStmt{Token: *token.NewToken(token.Ident, "red")},
+					},
+					{
+						Name:    parser.IdentStmt{Token: *token.NewToken(token.Ident, "rouge")},
+						AliasOf: &parser.IdentStmt{Token: *token.NewToken(token.Ident, "rojo")},
+					},
+					{Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "green")}},
+				},
+			},
+			wantDef: &definition.TypeDefinition{
+				Name:     "Color",
+				Modifier: token.Enum,
+				Fields: []*definition.FieldDefinition{
+					{Name: "unknown", Index: 0},
+					{
+						Name:          "red",
+						Index:         1,
+						Documentation: []string{"The red color"},
+						Annotations:   definition.Assignments{"display_name": "Red"},
+					},
+					{Name: "rojo", Index: 1, AliasOf: "red"},
+					{Name: "rouge", Index: 1, AliasOf: "red"},
+					{Name: "green", Index: 2},
+				},
+				DefaultMember: "red",
+			},
+			wantErrs: newAnalyzerErrorCollection(),
+		},
+		{
+			name: "enum alias errors",
+			input: parser.TypeStmt{
+				Name:     parser.IdentStmt{Token: *token.NewToken(token.Ident, "Color")},
+				Modifier: token.Enum,
+				Fields: []parser.FieldStmt{
+					{Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "unknown")}},
+					{
+						Index:   &parser.IdentStmt{Token: *token.NewToken(token.Integer, "0")},
+						Name:    parser.IdentStmt{Token: *token.NewToken(token.Ident, "none")},
+						AliasOf: &parser.IdentStmt{Token: *token.NewToken(token.Ident, "unknown")},
+					},
+					{
+						Name:    parser.IdentStmt{Token: *token.NewToken(token.Ident, "rojo")},
+						AliasOf: &parser.IdentStmt{Token: *token.NewToken(token.Ident, "red")},
+					},
+					{Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "red")}},
+				},
+			},
+			wantErrs: &AnalyzerErrorCollection{
+				NewAnalyzerError(ErrIndexedEnumAlias{Name: "none"}, *tokenizer.NewPos()),
+				NewAnalyzerError(ErrUnknownEnumMember{Name: "red", Modifier: token.Enum}, *tokenizer.NewPos()),
+			},
+		},
+		{
+			name: "enum default member errors",
+			input: parser.TypeStmt{
+				Name:     parser.IdentStmt{Token: *token.NewToken(token.Ident, "Color")},
+				Modifier: token.Enum,
+				Fields: []parser.FieldStmt{
+					{
+						Name:        parser.IdentStmt{Token: *token.NewToken(token.Ident, "unknown")},
+						Annotations: []parser.AnnotationStmt{newAnnotation("default", parser.MakeStringLiteral("yes"))},
+					},
+					{
+						Name:        parser.IdentStmt{Token: *token.NewToken(token.Ident, "red")},
+						Annotations: []parser.AnnotationStmt{newAnnotation("default", parser.MakeBooleanLiteral(true))},
+					},
+					{
+						Name:        parser.IdentStmt{Token: *token.NewToken(token.Ident, "green")},
+						Annotations: []parser.AnnotationStmt{newAnnotation("default", parser.MakeBooleanLiteral(true))},
+					},
+				},
+			},
+			wantErrs: &AnalyzerErrorCollection{
+				NewAnalyzerError(ErrWrongDefaultMemberValue{}, *tokenizer.NewPos()),
+				NewAnalyzerError(ErrDefaultMemberAlreadyDefined{Name: "red"}, *tokenizer.NewPos()),
+			},
+		},
+		{
+			name: "flags",
+			input: parser.TypeStmt{
+				Name:           parser.IdentStmt{Token: *token.NewToken(token.Ident, "Permission")},
+				Modifier:       token.Flags,
+				UnderlyingType: &parser.IdentStmt{Token: *token.NewToken(token.Ident, "uint8")},
+				Fields: []parser.FieldStmt{
+					{Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "read")}},
+					{Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "write")}},
+					{
+						Index: &parser.IdentStmt{Token: *token.NewToken(token.Integer, "128")},
+						Name:  parser.IdentStmt{Token: *token.NewToken(token.Ident, "admin")},
+					},
+					{
+						Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "read_write")},
+						Combines: []parser.IdentStmt{
+							{Token: *token.NewToken(token.Ident, "read")},
+							{Token: *token.NewToken(token.Ident, "write")},
+						},
+					},
+					{
+						Name:    parser.IdentStmt{Token: *token.NewToken(token.Ident, "root")},
+						AliasOf: &parser.IdentStmt{Token: *token.NewToken(token.Ident, "admin")},
+					},
+				},
+			},
+			wantDef: &definition.TypeDefinition{
+				Name:           "Permission",
+				Modifier:       token.Flags,
+				UnderlyingType: definition.Uint8,
+				Fields: []*definition.FieldDefinition{
+					{Name: "read", Index: 0, Value: 1},
+					{Name: "write", Index: 1, Value: 2},
+					{Name: "admin", Index: 2, Value: 128},
+					{Name: "read_write", Index: 3, Value: 3, Combines: []string{"read", "write"}},
+					{Name: "root", Index: 4, Value: 128, AliasOf: "admin"},
+				},
+			},
+			wantErrs: newAnalyzerErrorCollection(),
+		},
+		{
+			name: "flags default underlying type is uint32",
+			input: parser.TypeStmt{
+				Name:     parser.IdentStmt{Token: *token.NewToken(token.Ident, "Permission")},
+				Modifier: token.Flags,
+				Fields: []parser.FieldStmt{
+					{Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "read")}},
+				},
+			},
+			wantDef: &definition.TypeDefinition{
+				Name:           "Permission",
+				Modifier:       token.Flags,
+				UnderlyingType: definition.Uint32,
+				Fields: []*definition.FieldDefinition{
+					{Name: "read", Index: 0, Value: 1},
+				},
+			},
+			wantErrs: newAnalyzerErrorCollection(),
+		},
+		{
+			name: "flags errors",
+			input: parser.TypeStmt{
+				Name:           parser.IdentStmt{Token: *token.NewToken(token.Ident, "Permission")},
+				Modifier:       token.Flags,
+				UnderlyingType: &parser.IdentStmt{Token: *token.NewToken(token.Ident, "uint8")},
+				Fields: []parser.FieldStmt{
+					{
+						Index: &parser.IdentStmt{Token: *token.NewToken(token.Integer, "3")},
+						Name:  parser.IdentStmt{Token: *token.NewToken(token.Ident, "read")},
+					},
+					{
+						Index: &parser.IdentStmt{Token: *token.NewToken(token.Integer, "256")},
+						Name:  parser.IdentStmt{Token: *token.NewToken(token.Ident, "write")},
+					},
+					{
+						Index: &parser.IdentStmt{Token: *token.NewToken(token.Integer, "1")},
+						Name:  parser.IdentStmt{Token: *token.NewToken(token.Ident, "execute")},
+					},
+					{
+						Index: &parser.IdentStmt{Token: *token.NewToken(token.Integer, "1")},
+						Name:  parser.IdentStmt{Token: *token.NewToken(token.Ident, "run")},
+					},
+					{
+						Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "all")},
+						Combines: []parser.IdentStmt{
+							{Token: *token.NewToken(token.Ident, "execute")},
+							{Token: *token.NewToken(token.Ident, "delete")},
+						},
+					},
+				},
+			},
+			wantErrs: &AnalyzerErrorCollection{
+				NewAnalyzerError(ErrFlagsMemberNotSingleBit{Name: "read"}, *tokenizer.NewPos()),
+				NewAnalyzerError(ErrFlagsMemberOverflow{Name: "write", UnderlyingType: definition.Uint8}, *tokenizer.NewPos()),
+				NewAnalyzerError(ErrWrongFieldIndex{ErrBaseWrongFieldIndex_DuplicatedIndex}, *tokenizer.NewPos()),
+				NewAnalyzerError(ErrUnknownEnumMember{Name: "delete", Modifier: token.Flags}, *tokenizer.NewPos()),
+			},
+		},
+		{
+			name: "flags members after the top bit overflow",
+			input: parser.TypeStmt{
+				Name:           parser.IdentStmt{Token: *token.NewToken(token.Ident, "Permission")},
+				Modifier:       token.Flags,
+				UnderlyingType: &parser.IdentStmt{Token: *token.NewToken(token.Ident, "uint64")},
+				Fields: []parser.FieldStmt{
+					{
+						Index: &parser.IdentStmt{Token: *token.NewToken(token.Integer, "0x8000_0000_0000_0000")},
+						Name:  parser.IdentStmt{Token: *token.NewToken(token.Ident, "top")},
+					},
+					{Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "next")}},
+				},
+			},
+			wantErrs: &AnalyzerErrorCollection{
+				NewAnalyzerError(ErrFlagsMemberOverflow{Name: "next", UnderlyingType: definition.Uint64}, *tokenizer.NewPos()),
+			},
+		},
+		{
+			name: "flags underlying type must be an unsigned fixed-size integer",
+			input: parser.TypeStmt{
+				Name:           parser.IdentStmt{Token: *token.NewToken(token.Ident, "Permission")},
+				Modifier:       token.Flags,
+				UnderlyingType: &parser.IdentStmt{Token: *token.NewToken(token.Ident, "int32")},
+			},
+			wantErrs: &AnalyzerErrorCollection{
+				NewAnalyzerError(ErrWrongFlagsUnderlyingType{Name: "int32"}, *tokenizer.NewPos()),
+			},
+		},
+		{
+			name: "only flags can combine members",
+			input: parser.TypeStmt{
+				Name:     parser.IdentStmt{Token: *token.NewToken(token.Ident, "Color")},
+				Modifier: token.Enum,
+				Fields: []parser.FieldStmt{
+					{Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "red")}},
+					{Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "blue")}},
+					{
+						Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "purple")},
+						Combines: []parser.IdentStmt{
+							{Token: *token.NewToken(token.Ident, "red")},
+							{Token: *token.NewToken(token.Ident, "blue")},
+						},
+					},
+				},
+			},
+			wantErrs: &AnalyzerErrorCollection{
+				NewAnalyzerError(ErrCombinedMemberNotFlags{Name: "purple"}, *tokenizer.NewPos()),
+			},
+		},
+		{
+			name: "struct with oneof",
+			input: parser.TypeStmt{
+				Name:     parser.IdentStmt{Token: *token.NewToken(token.Ident, "Contact")},
+				Modifier: token.Struct,
+				Fields: []parser.FieldStmt{
+					{
+						Index:     &parser.IdentStmt{Token: *token.NewToken(token.Integer, "1")},
+						Name:      parser.IdentStmt{Token: *token.NewToken(token.Ident, "name")},
+						ValueType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "string")},
+					},
+					{
+						Index:     &parser.IdentStmt{Token: *token.NewToken(token.Integer, "2")},
+						Name:      parser.IdentStmt{Token: *token.NewToken(token.Ident, "email")},
+						ValueType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "string")},
+						Oneof:     &parser.IdentStmt{Token: *token.NewToken(token.Ident, "method")},
+					},
+					{
+						Index:     &parser.IdentStmt{Token: *token.NewToken(token.Integer, "3")},
+						Name:      parser.IdentStmt{Token: *token.NewToken(token.Ident, "phone")},
+						ValueType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "string")},
+						Oneof:     &parser.IdentStmt{Token: *token.NewToken(token.Ident, "method")},
+					},
+				},
+				Oneofs: []parser.OneofStmt{
+					{
+						Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "method")},
+						Documentation: []parser.CommentStmt{
+							{Token: *token.NewToken(token.DocComment, " How to reach the contact")},
+						},
+					},
+				},
+				Defaults: []parser.AssignStmt{
+					{
+						Left:  parser.IdentStmt{Token: *token.NewToken(token.Ident, "email")},
+						Right: parser.LiteralStmt{Kind: parser.MakeStringLiteral("")},
+					},
+				},
+			},
+			wantDef: &definition.TypeDefinition{
+				Name:     "Contact",
+				Modifier: token.Struct,
+				Fields: []*definition.FieldDefinition{
+					{Name: "name", Index: 1, Type: definition.PrimitiveValueType{Primitive: definition.String}},
+					{Name: "email", Index: 2, Type: definition.PrimitiveValueType{Primitive: definition.String}, Oneof: "method"},
+					{Name: "phone", Index: 3, Type: definition.PrimitiveValueType{Primitive: definition.String}, Oneof: "method"},
+				},
+				Oneofs: []*definition.OneofDefinition{
+					{Name: "method", Documentation: []string{"How to reach the contact"}, Fields: []string{"email", "phone"}},
+				},
+				Defaults: definition.Assignments{"email": ""},
+			},
+			wantErrs: newAnalyzerErrorCollection(),
+		},
+		{
+			name: "oneof fields share indexes with the struct and cannot be nullable",
+			input: parser.TypeStmt{
+				Name:     parser.IdentStmt{Token: *token.NewToken(token.Ident, "Contact")},
+				Modifier: token.Struct,
+				Fields: []parser.FieldStmt{
+					{
+						Index:     &parser.IdentStmt{Token: *token.NewToken(token.Integer, "1")},
+						Name:      parser.IdentStmt{Token: *token.NewToken(token.Ident, "name")},
+						ValueType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "string")},
+					},
+					{
+						Index:     &parser.IdentStmt{Token: *token.NewToken(token.Integer, "1")},
+						Name:      parser.IdentStmt{Token: *token.NewToken(token.Ident, "email")},
+						ValueType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "string")},
+						Oneof:     &parser.IdentStmt{Token: *token.NewToken(token.Ident, "method")},
+					},
+					{
+						Index:     &parser.IdentStmt{Token: *token.NewToken(token.Integer, "2")},
+						Name:      parser.IdentStmt{Token: *token.NewToken(token.Ident, "phone")},
+						ValueType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "string"), Nullable: true},
+						Oneof:     &parser.IdentStmt{Token: *token.NewToken(token.Ident, "method")},
+					},
+				},
+				Oneofs: []parser.OneofStmt{
+					{Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "method")}},
+				},
+			},
+			wantErrs: &AnalyzerErrorCollection{
+				NewAnalyzerError(ErrWrongFieldIndex{ErrBaseWrongFieldIndex_DuplicatedIndex}, *tokenizer.NewPos()),
+				NewAnalyzerError(ErrNullableOneofField{Name: "phone", Oneof: "method"}, *tokenizer.NewPos()),
+			},
+		},
+		{
+			name: "oneof names cannot be duplicated and groups cannot be empty",
+			input: parser.TypeStmt{
+				Name:     parser.IdentStmt{Token: *token.NewToken(token.Ident, "Contact")},
+				Modifier: token.Struct,
+				Fields: []parser.FieldStmt{
+					{
+						Name:      parser.IdentStmt{Token: *token.NewToken(token.Ident, "name")},
+						ValueType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "string")},
+					},
+				},
+				Oneofs: []parser.OneofStmt{
+					{Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "name")}},
+					{Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "method")}},
+				},
+			},
+			wantErrs: &AnalyzerErrorCollection{
+				NewAnalyzerError(ErrAlreadyDefined{Name: "name"}, *tokenizer.NewPos()).note("previously defined here", *tokenizer.NewPos()),
+				NewAnalyzerError(ErrEmptyOneof{Name: "method"}, *tokenizer.NewPos()),
+			},
+		},
+		{
+			name: "only one oneof field can declare a default value",
+			input: parser.TypeStmt{
+				Name:     parser.IdentStmt{Token: *token.NewToken(token.Ident, "Contact")},
+				Modifier: token.Struct,
+				Fields: []parser.FieldStmt{
+					{
+						Index:     &parser.IdentStmt{Token: *token.NewToken(token.Integer, "1")},
+						Name:      parser.IdentStmt{Token: *token.NewToken(token.Ident, "email")},
+						ValueType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "string")},
+						Oneof:     &parser.IdentStmt{Token: *token.NewToken(token.Ident, "method")},
+					},
+					{
+						Index:     &parser.IdentStmt{Token: *token.NewToken(token.Integer, "2")},
+						Name:      parser.IdentStmt{Token: *token.NewToken(token.Ident, "phone")},
+						ValueType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "string")},
+						Oneof:     &parser.IdentStmt{Token: *token.NewToken(token.Ident, "method")},
+					},
+				},
+				Oneofs: []parser.OneofStmt{
+					{Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "method")}},
+				},
+				Defaults: []parser.AssignStmt{
+					{
+						Left:  parser.IdentStmt{Token: *token.NewToken(token.Ident, "email")},
+						Right: parser.LiteralStmt{Kind: parser.MakeStringLiteral("")},
+					},
+					{
+						Left:  parser.IdentStmt{Token: *token.NewToken(token.Ident, "phone")},
+						Right: parser.LiteralStmt{Kind: parser.MakeStringLiteral("")},
+					},
+				},
+			},
+			wantErrs: &AnalyzerErrorCollection{
+				NewAnalyzerError(ErrOneofDefaultAlreadyDefined{Oneof: "method", Field: "email"}, *tokenizer.NewPos()),
+			},
+		},
+		{
+			name: "oneof is only allowed in structs",
+			input: parser.TypeStmt{
+				Name:     parser.IdentStmt{Token: *token.NewToken(token.Ident, "Result")},
+				Modifier: token.Union,
+				Fields: []parser.FieldStmt{
+					{
+						Index:     &parser.IdentStmt{Token: *token.NewToken(token.Integer, "1")},
+						Name:      parser.IdentStmt{Token: *token.NewToken(token.Ident, "ok")},
+						ValueType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "string")},
+						Oneof:     &parser.IdentStmt{Token: *token.NewToken(token.Ident, "value")},
+					},
+				},
+				Oneofs: []parser.OneofStmt{
+					{Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "value")}},
+				},
+			},
+			wantErrs: &AnalyzerErrorCollection{
+				NewAnalyzerError(ErrOneofNotAllowed{Modifier: token.Union}, *tokenizer.NewPos()),
+			},
+		},
+		{
+			name: "union with discriminator, tags and default member",
+			input: parser.TypeStmt{
+				Name:     parser.IdentStmt{Token: *token.NewToken(token.Ident, "Shape")},
+				Modifier: token.Union,
+				Annotations: []parser.AnnotationStmt{
+					newAnnotation("discriminator", parser.MakeStringLiteral("kind")),
+					newAnnotation("obsolete", parser.MakeBooleanLiteral(false)),
+				},
+				Fields: []parser.FieldStmt{
+					{
+						Name:      parser.IdentStmt{Token: *token.NewToken(token.Ident, "circle")},
+						ValueType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "string")},
+						Annotations: []parser.AnnotationStmt{
+							newAnnotation("tag", parser.MakeStringLiteral("CIRCLE")),
+						},
+					},
+					{
+						Name:      parser.IdentStmt{Token: *token.NewToken(token.Ident, "square")},
+						ValueType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "string")},
+					},
+					{
+						Name:      parser.IdentStmt{Token: *token.NewToken(token.Ident, "unknown")},
+						ValueType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "string")},
+						Annotations: []parser.AnnotationStmt{
+							newAnnotation("default", parser.MakeBooleanLiteral(true)),
+						},
+					},
+				},
+			},
+			wantDef: &definition.TypeDefinition{
+				Name:        "Shape",
+				Modifier:    token.Union,
+				Annotations: definition.Assignments{"obsolete": false},
+				Fields: []*definition.FieldDefinition{
+					{Name: "circle", Index: 0, Type: definition.PrimitiveValueType{Primitive: definition.String}, Annotations: definition.Assignments{}, Tag: "CIRCLE"},
+					{Name: "square", Index: 1, Type: definition.PrimitiveValueType{Primitive: definition.String}, Tag: "square"},
+					{Name: "unknown", Index: 2, Type: definition.PrimitiveValueType{Primitive: definition.String}, Annotations: definition.Assignments{}, Tag: "unknown"},
+				},
+				DefaultMember: "unknown",
+				Discriminator: "kind",
+			},
+			wantErrs: newAnalyzerErrorCollection(),
+		},
+		{
+			name: "union tags must be unique non-empty strings",
+			input: parser.TypeStmt{
+				Name:     parser.IdentStmt{Token: *token.NewToken(token.Ident, "Shape")},
+				Modifier: token.Union,
+				Annotations: []parser.AnnotationStmt{
+					newAnnotation("discriminator", parser.MakeIntLiteral(1)),
+				},
+				Fields: []parser.FieldStmt{
+					{
+						Name:      parser.IdentStmt{Token: *token.NewToken(token.Ident, "circle")},
+						ValueType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "string")},
+						Annotations: []parser.AnnotationStmt{
+							newAnnotation("tag", parser.MakeStringLiteral("square")),
+						},
+					},
+					{
+						Name:      parser.IdentStmt{Token: *token.NewToken(token.Ident, "square")},
+						ValueType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "string")},
+					},
+					{
+						Name:      parser.IdentStmt{Token: *token.NewToken(token.Ident, "triangle")},
+						ValueType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "string")},
+						Annotations: []parser.AnnotationStmt{
+							newAnnotation("tag", parser.MakeStringLiteral("")),
+						},
+					},
+				},
+			},
+			wantErrs: &AnalyzerErrorCollection{
+				NewAnalyzerError(ErrWrongDiscriminatorValue{}, *tokenizer.NewPos()),
+				NewAnalyzerError(ErrDuplicatedUnionTag{Tag: "square", Name: "circle"}, *tokenizer.NewPos()),
+				NewAnalyzerError(ErrWrongUnionTagValue{}, *tokenizer.NewPos()),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			analyzer := NewAnalyzer([]*scope.Scope{})
+			analyzer.currScope = &scope.Scope{}
+			analyzer.currLocalScope = scope.NewLocalScope(nil, nil, test.localObjects)
+
+			gotDef := analyzer.analyzeTypeStmt(&test.input)
+			if diff := cmp.Diff(test.wantErrs, analyzer.errors, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("TestAnalyzer_TestValidateType: %s: wantErrs mismatch (-want +got):\n%s", test.name, diff)
+			}
+
+			if analyzer.errors.IsEmpty() {
+				if diff := cmp.Diff(test.wantDef, gotDef); diff != "" {
+					t.Errorf("TestAnalyzer_TestValidateType: %s: wantDef mismatch (-want +got):\n%s", test.name, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyzer_GetAssignments(t *testing.T) {
+	tests := []struct {
 		name            string
 		input           []parser.AssignStmt
 		isAnnotation    bool
@@ -540,3 +1627,193 @@ func TestAnalyzer_SanitizeComments(t *testing.T) {
 		})
 	}
 }
+
+func TestAnalyzer_AnalyzePackageHeader(t *testing.T) {
+	annotation := func(key string, value int64) parser.AnnotationStmt {
+		return parser.AnnotationStmt{
+			Assigment: parser.AssignStmt{
+				Left:  parser.IdentStmt{Token: *token.NewToken(token.Ident, key), Pos: *tokenizer.NewPos(1, 9)},
+				Right: parser.LiteralStmt{Kind: parser.MakeIntLiteral(value)},
+			},
+		}
+	}
+
+	inFile := func(err *AnalyzerError, fileName string) *AnalyzerError {
+		err.File = &parser.File{FileName: fileName, Path: "foo"}
+		return err
+	}
+
+	localScope := func(fileName, doc string, annotations ...parser.AnnotationStmt) *scope.LocalScope {
+		ls := scope.NewLocalScope(&parser.File{FileName: fileName, Path: "foo"}, map[string]*scope.Import{}, map[string]*scope.Object{})
+		if len(doc) > 0 {
+			ls.Documentation = []parser.CommentStmt{{Token: *token.NewToken(token.Comment, doc)}}
+		}
+		ls.Annotations = annotations
+		return ls
+	}
+
+	tests := []struct {
+		name              string
+		input             []*scope.LocalScope
+		wantDocumentation []string
+		wantAnnotations   definition.Assignments
+		wantErrs          *AnalyzerErrorCollection
+	}{
+		{
+			name: "package.nex goes first",
+			input: []*scope.LocalScope{
+				localScope("b.nex", "from b", annotation("b", 2)),
+				localScope("package.nex", "from package", annotation("package", 1)),
+				localScope("a.nex", "from a"),
+			},
+			wantDocumentation: []string{"from package", "from a", "from b"},
+			wantAnnotations:   definition.Assignments{"package": int64(1), "b": int64(2)},
+			wantErrs:          newAnalyzerErrorCollection(),
+		},
+		{
+			name: "files without header",
+			input: []*scope.LocalScope{
+				localScope("a.nex", ""),
+			},
+			wantErrs: newAnalyzerErrorCollection(),
+		},
+		{
+			name: "annotations cannot be repeated between files",
+			input: []*scope.LocalScope{
+				localScope("a.nex", "", annotation("version", 1)),
+				localScope("b.nex", "", annotation("version", 2)),
+			},
+			wantErrs: &AnalyzerErrorCollection{
+				inFile(NewAnalyzerError(ErrAssignmentKeyAlreadyInUse{KeyName: "version"}, *tokenizer.NewPos(1, 9)), "b.nex").
+					noteIn(&parser.File{FileName: "a.nex", Path: "foo"}, "previously defined here", *tokenizer.NewPos(1, 9)),
+			},
+		},
+		{
+			name: "nolint annotations are not merged",
+			input: []*scope.LocalScope{
+				localScope("a.nex", "", annotation("nolint", 1)),
+				localScope("b.nex", "", annotation("nolint", 1), annotation("version", 1)),
+			},
+			wantAnnotations: definition.Assignments{"version": int64(1)},
+			wantErrs:        newAnalyzerErrorCollection(),
+		},
+		{
+			name: "wrong annotation values are reported in their file",
+			input: []*scope.LocalScope{
+				localScope("a.nex", "", annotation("version", 1)),
+				localScope("b.nex", "", parser.AnnotationStmt{
+					Assigment: parser.AssignStmt{
+						Left:  parser.IdentStmt{Token: *token.NewToken(token.Ident, "owners")},
+						Right: parser.LiteralStmt{Kind: parser.MakeListLiteral(), Pos: *tokenizer.NewPos(10, 12)},
+					},
+				}),
+			},
+			wantErrs: &AnalyzerErrorCollection{
+				inFile(NewAnalyzerError(ErrWrongAnnotationValue{}, *tokenizer.NewPos(10, 12)), "b.nex"),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			analyzer := NewAnalyzer([]*scope.Scope{})
+
+			gotDocumentation, gotAnnotations := analyzer.analyzePackageHeader(test.input)
+			if diff := cmp.Diff(test.wantErrs, analyzer.errors, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("TestAnalyzer_AnalyzePackageHeader: %s: wantErrs mismatch (-want +got):\n%s", test.name, diff)
+			}
+
+			if analyzer.errors.IsEmpty() {
+				if diff := cmp.Diff(test.wantDocumentation, gotDocumentation); diff != "" {
+					t.Errorf("TestAnalyzer_AnalyzePackageHeader: %s: wantDocumentation mismatch (-want +got):\n%s", test.name, diff)
+				}
+
+				if diff := cmp.Diff(test.wantAnnotations, gotAnnotations); diff != "" {
+					t.Errorf("TestAnalyzer_AnalyzePackageHeader: %s: wantAnnotations mismatch (-want +got):\n%s", test.name, diff)
+				}
+			}
+		})
+	}
+}
+
+func newAnnotation(key string, value parser.LiteralKind) parser.AnnotationStmt {
+	return parser.AnnotationStmt{
+		Token: *token.NewToken(token.Hash),
+		Assigment: parser.AssignStmt{
+			Token: *token.NewToken(token.Assign),
+			Left:  parser.IdentStmt{Token: *token.NewToken(token.Ident, key)},
+			Right: parser.LiteralStmt{Kind: value},
+		},
+	}
+}
+
+func TestAnalyzer_SuggestType(t *testing.T) {
+	geo := scope.NewScope("common/geo", "geo")
+	geo.PushLocalScope(scope.NewLocalScope(nil, nil, map[string]*scope.Object{
+		"Address": newObject("Address", token.Struct),
+		"City":    newObject("City", token.Struct),
+	}))
+
+	identity := scope.NewScope("identity", "identity")
+	localScope := scope.NewLocalScope(nil, nil, map[string]*scope.Object{
+		"User": newObject("User", token.Struct),
+	})
+	identity.PushLocalScope(localScope)
+
+	tests := []struct {
+		name              string
+		typeName          string
+		alias             string
+		acceptsPrimitives bool
+		wantSuggestion    string
+		wantImport        string
+	}{
+		{name: "visible type", typeName: "Usr", wantSuggestion: "User"},
+		{name: "primitive", typeName: "bol", acceptsPrimitives: true, wantSuggestion: "bool"},
+		{name: "primitives not accepted", typeName: "bol"},
+		{name: "not imported type", typeName: "Adress", wantSuggestion: "Address", wantImport: `use "common/geo"`},
+		{name: "not imported exact type", typeName: "City", wantSuggestion: "City", wantImport: `use "common/geo"`},
+		{name: "not imported aliased type", typeName: "Adress", alias: "geo", wantSuggestion: "geo.Address", wantImport: `use "common/geo" as geo`},
+		{name: "too different", typeName: "Payment", acceptsPrimitives: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			analyzer := NewAnalyzer([]*scope.Scope{geo, identity})
+			analyzer.currScope = identity
+			analyzer.currLocalScope = localScope
+
+			suggestion, importStmt := analyzer.suggestType(test.typeName, test.alias, test.acceptsPrimitives)
+			require.Equal(t, test.wantSuggestion, suggestion)
+			require.Equal(t, test.wantImport, importStmt)
+		})
+	}
+}
+
+func newObject(name string, modifier token.TokenKind) *scope.Object {
+	return scope.NewObject(&parser.TypeStmt{
+		Name:     parser.IdentStmt{Token: *token.NewToken(token.Ident, name)},
+		Modifier: modifier,
+	})
+}
+
+func TestAnalyzer_Diagnostics(t *testing.T) {
+	file := &parser.File{Path: "foo", FileName: "bar.nex"}
+	obj := scope.NewObject(&parser.TypeStmt{
+		Name:     parser.IdentStmt{Token: *token.NewToken(token.Ident, "Bar"), Pos: *tokenizer.NewPos(5, 8)},
+		Modifier: token.Illegal,
+	})
+
+	s := scope.NewScope("foo", "foo")
+	s.PushLocalScope(scope.NewLocalScope(file, map[string]*scope.Import{}, map[string]*scope.Object{"Bar": obj}))
+
+	analyzer := NewAnalyzer([]*scope.Scope{s})
+	analyzer.Analyze()
+
+	want := diagnostic.Diagnostics{
+		diagnostic.New(diagnostic.Error, "NX0301", ErrUnknownTypeModifier{token.Illegal}.Message(), "foo/bar.nex", *tokenizer.NewPos(5, 8)),
+	}
+	if diff := cmp.Diff(want, analyzer.Errors().Diagnostics()); diff != "" {
+		t.Errorf("TestAnalyzer_Diagnostics: mismatch (-want +got):\n%s", diff)
+	}
+}
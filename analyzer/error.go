@@ -6,13 +6,17 @@ import (
 	"strings"
 
 	"tomasweigenast.com/nexema/tool/definition"
+	"tomasweigenast.com/nexema/tool/diagnostic"
+	"tomasweigenast.com/nexema/tool/parser"
 	"tomasweigenast.com/nexema/tool/token"
 	"tomasweigenast.com/nexema/tool/tokenizer"
 )
 
 type AnalyzerError struct {
-	At   tokenizer.Pos
-	Kind AnalyzerErrorKind
+	At    tokenizer.Pos
+	Kind  AnalyzerErrorKind
+	File  *parser.File      // the file where the error was found, if known
+	Notes []diagnostic.Note // related locations in the same file
 }
 
 type AnalyzerErrorKind interface {
@@ -23,6 +27,7 @@ var (
 	ErrBaseWrongFieldIndex_EnumShouldBeZeroBased  error = errors.New("first enum's field's index should be 0")
 	ErrBaseWrongFieldIndex_EnumShouldBeSubsequent error = errors.New("enum field's should be subsequent")
 	ErrBaseWrongFieldIndex_DuplicatedIndex        error = errors.New("fields index must not be duplicated")
+	ErrBaseWrongFieldIndex_OutOfRange             error = errors.New("fields index must be an int32")
 )
 
 type (
@@ -33,8 +38,10 @@ type (
 	ErrNeedAlias struct{}
 
 	ErrTypeNotFound struct {
-		Name  string
-		Alias string
+		Name       string
+		Alias      string
+		Suggestion string // a type with a similar name, as it should be written, if any
+		Import     string // the use statement needed to reference Suggestion, if it is not imported yet
 	}
 
 	ErrNotValidBaseType struct {
@@ -56,6 +63,34 @@ type (
 		IsMapKey  bool
 	}
 
+	ErrWrongMapKeyType struct {
+		TypeName string
+		Modifier token.TokenKind
+	}
+
+	ErrLengthNotAllowed struct {
+		Primitive definition.ValuePrimitive
+	}
+
+	ErrMisplacedLength struct{}
+
+	ErrWrongLength struct {
+		Length string
+	}
+
+	ErrMissingArrayLength struct{}
+
+	ErrWrongDefaultLength struct {
+		Primitive definition.ValuePrimitive
+		Expected  int
+		Got       int
+	}
+
+	ErrDefaultOutOfRange struct {
+		Value     string
+		Primitive definition.ValuePrimitive
+	}
+
 	ErrWrongFieldIndex struct {
 		Err error
 	}
@@ -71,13 +106,114 @@ type (
 	}
 
 	ErrNonNullableUnionFields struct{}
+
+	ErrIndexedEnumAlias struct {
+		Name string
+	}
+
+	ErrUnknownEnumMember struct {
+		Name     string
+		Modifier token.TokenKind // token.Enum or token.Flags
+	}
+
+	ErrDefaultMemberAlreadyDefined struct {
+		Name string
+	}
+
+	ErrWrongDefaultMemberValue struct{}
+
+	ErrCombinedMemberNotFlags struct {
+		Name string
+	}
+
+	ErrWrongFlagsUnderlyingType struct {
+		Name string
+	}
+
+	ErrFlagsMemberNotSingleBit struct {
+		Name string
+	}
+
+	ErrFlagsMemberOverflow struct {
+		Name           string
+		UnderlyingType definition.ValuePrimitive
+	}
+
+	ErrWrongDiscriminatorValue struct{}
+
+	ErrWrongUnionTagValue struct{}
+
+	ErrDuplicatedUnionTag struct {
+		Tag  string
+		Name string
+	}
+
+	ErrOneofNotAllowed struct {
+		Modifier token.TokenKind
+	}
+
+	ErrEmptyOneof struct {
+		Name string
+	}
+
+	ErrNullableOneofField struct {
+		Name  string
+		Oneof string
+	}
+
+	ErrOneofDefaultAlreadyDefined struct {
+		Oneof string
+		Field string
+	}
+
+	ErrReservedName struct {
+		Name    string
+		Targets []string // the targets that reserve Name
+	}
+
+	ErrNameCollision struct {
+		Kind      string // type or field
+		Name      string
+		Other     string // the name declared before
+		Case      string // the case in which both names are the same
+		Converted string // the name both become
+	}
 )
 
 func (e ErrWrongArgumentsLen) Message() string {
-	if e.Primitive == definition.List {
-		return fmt.Sprintf("list expects exactly one argument, got %d instead", e.ArgumentsLen)
-	} else {
+	if e.Primitive == definition.Map {
 		return fmt.Sprintf("map expects exactly two arguments, got %d instead", e.ArgumentsLen)
+	} else {
+		return fmt.Sprintf("%s expects exactly one argument, got %d instead", e.Primitive, e.ArgumentsLen)
+	}
+}
+
+func (e ErrLengthNotAllowed) Message() string {
+	return fmt.Sprintf("%s does not accept a length, only array, list and string do", e.Primitive)
+}
+
+func (ErrMisplacedLength) Message() string {
+	return "length must be the last argument"
+}
+
+func (e ErrWrongLength) Message() string {
+	return fmt.Sprintf("length must be a number greater than zero, got %s", e.Length)
+}
+
+func (ErrMissingArrayLength) Message() string {
+	return "array expects its length as the last argument, like array(float32, 3)"
+}
+
+func (e ErrWrongDefaultLength) Message() string {
+	switch e.Primitive {
+	case definition.Array:
+		return fmt.Sprintf("default value must have exactly %d elements, got %d", e.Expected, e.Got)
+
+	case definition.String:
+		return fmt.Sprintf("default value can have at most %d characters, got %d", e.Expected, e.Got)
+
+	default:
+		return fmt.Sprintf("default value can have at most %d elements, got %d", e.Expected, e.Got)
 	}
 }
 
@@ -85,10 +221,87 @@ func (e ErrWrongAnnotationValue) Message() string {
 	return "annotation value must be a value of type string, int64, float64 or boolean"
 }
 
+func (e ErrDefaultOutOfRange) Message() string {
+	return fmt.Sprintf("default value %s does not fit in %s", e.Value, e.Primitive)
+}
+
 func (e ErrNonNullableUnionFields) Message() string {
 	return "unions cannot declare nullable fields"
 }
 
+func (e ErrIndexedEnumAlias) Message() string {
+	return fmt.Sprintf("member %q points to other members, it cannot declare an index", e.Name)
+}
+
+func (e ErrCombinedMemberNotFlags) Message() string {
+	return fmt.Sprintf("%q combines other members, which is only allowed in flags", e.Name)
+}
+
+func (e ErrWrongFlagsUnderlyingType) Message() string {
+	return fmt.Sprintf("flags underlying type must be uint8, uint16, uint32 or uint64, got %q", e.Name)
+}
+
+func (e ErrFlagsMemberNotSingleBit) Message() string {
+	return fmt.Sprintf("flags member %q must be a single bit, a power of two", e.Name)
+}
+
+func (e ErrFlagsMemberOverflow) Message() string {
+	return fmt.Sprintf("flags member %q does not fit in %s", e.Name, e.UnderlyingType)
+}
+
+func (ErrWrongDiscriminatorValue) Message() string {
+	return "discriminator annotation value must be a non-empty string"
+}
+
+func (ErrWrongUnionTagValue) Message() string {
+	return "tag annotation value must be a non-empty string"
+}
+
+func (e ErrDuplicatedUnionTag) Message() string {
+	return fmt.Sprintf("tag %q is already used by member %q", e.Tag, e.Name)
+}
+
+func (e ErrOneofNotAllowed) Message() string {
+	return fmt.Sprintf("oneof groups can only be declared in structs, not in a %s", e.Modifier)
+}
+
+func (e ErrEmptyOneof) Message() string {
+	return fmt.Sprintf("oneof %q must declare at least one field", e.Name)
+}
+
+func (e ErrNullableOneofField) Message() string {
+	return fmt.Sprintf("field %q cannot be nullable because it belongs to oneof %q, which is already unset when no field is set", e.Name, e.Oneof)
+}
+
+func (e ErrOneofDefaultAlreadyDefined) Message() string {
+	return fmt.Sprintf("oneof %q already has a default value in %q, only one of its fields can declare one", e.Oneof, e.Field)
+}
+
+func (e ErrReservedName) Message() string {
+	targets := e.Targets[len(e.Targets)-1]
+	if len(e.Targets) > 1 {
+		targets = strings.Join(e.Targets[:len(e.Targets)-1], ", ") + " and " + targets
+	}
+
+	return fmt.Sprintf("%q is a reserved word in %s, so the generated code would not compile", e.Name, targets)
+}
+
+func (e ErrNameCollision) Message() string {
+	return fmt.Sprintf("%s %q collides with %q once converted to %s, since both become %q", e.Kind, e.Name, e.Other, e.Case, e.Converted)
+}
+
+func (e ErrUnknownEnumMember) Message() string {
+	return fmt.Sprintf("%q is not a member declared before in the %s", e.Name, e.Modifier)
+}
+
+func (e ErrDefaultMemberAlreadyDefined) Message() string {
+	return fmt.Sprintf("default member is already %q", e.Name)
+}
+
+func (ErrWrongDefaultMemberValue) Message() string {
+	return "default annotation value must be a boolean"
+}
+
 func (e ErrIllegalUseCycle) Message() string {
 	return fmt.Sprintf("cannot declare a field in %[1]q whose value type is %[1]q", e.TypeName)
 }
@@ -102,15 +315,13 @@ func (e ErrWrongFieldIndex) Message() string {
 }
 
 func (e ErrWrongArguments) Message() string {
-	if e.Primitive == definition.List {
-		return "list argument cannot be another list or a map"
-	} else {
-		if e.IsMapKey {
-			return "map key must be a non-nullable string, int, uint, fixed-int, fixed-uint or boolean"
-		} else {
-			return "map value cannot be another list or a map"
-		}
-	}
+	return fmt.Sprintf("%s key must be a non-nullable string, int, uint, fixed-int, fixed-uint, enum or flags", e.Primitive)
+}
+
+func (e ErrWrongMapKeyType) Message() string {
+	return fmt.Sprintf("%q cannot be used as a map key because it is a %s. Keys are compared and hashed by value, "+
+		"which is only possible with scalars, like enums and flags; a %[2]s can hold nullable fields, lists or maps",
+		e.TypeName, e.Modifier)
 }
 
 func (e ErrUnknownTypeModifier) Message() string {
@@ -126,24 +337,129 @@ func (e ErrAlreadyDefined) Message() string {
 }
 
 func (e ErrNotValidBaseType) Message() string {
-	fullName := e.Alias
+	fullName := e.Name
 	if len(e.Alias) > 0 {
-		fullName += "." + e.Name
+		fullName = e.Alias + "." + e.Name
 	}
 	return fmt.Sprintf("%q is not a valid base type", fullName)
 }
 
 func (e ErrTypeNotFound) Message() string {
-	fullName := e.Alias
+	fullName := e.Name
 	if len(e.Alias) > 0 {
-		fullName += "." + e.Name
+		fullName = e.Alias + "." + e.Name
 	}
 
-	return fmt.Sprintf("type %q not found, are you missing an import?", fullName)
+	switch {
+	case len(e.Import) > 0:
+		return fmt.Sprintf("type %q not found, did you mean %q? add %s to import it", fullName, e.Suggestion, e.Import)
+	case len(e.Suggestion) > 0:
+		return fmt.Sprintf("type %q not found, did you mean %q?", fullName, e.Suggestion)
+	default:
+		return fmt.Sprintf("type %q not found, are you missing an import?", fullName)
+	}
 }
 
 func NewAnalyzerError(err AnalyzerErrorKind, at tokenizer.Pos) *AnalyzerError {
-	return &AnalyzerError{at, err}
+	return &AnalyzerError{At: at, Kind: err}
+}
+
+// Code returns the code that identifies the kind of the error in diagnostics
+func (self *AnalyzerError) Code() string {
+	switch self.Kind.(type) {
+	case ErrUnknownTypeModifier:
+		return "NX0301"
+	case ErrNeedAlias:
+		return "NX0302"
+	case ErrTypeNotFound:
+		return "NX0303"
+	case ErrNotValidBaseType:
+		return "NX0304"
+	case ErrAlreadyDefined:
+		return "NX0305"
+	case ErrWrongArgumentsLen:
+		return "NX0306"
+	case ErrWrongArguments:
+		return "NX0307"
+	case ErrWrongMapKeyType:
+		return "NX0308"
+	case ErrLengthNotAllowed:
+		return "NX0309"
+	case ErrMisplacedLength:
+		return "NX0310"
+	case ErrWrongLength:
+		return "NX0311"
+	case ErrMissingArrayLength:
+		return "NX0312"
+	case ErrWrongDefaultLength:
+		return "NX0313"
+	case ErrDefaultOutOfRange:
+		return "NX0314"
+	case ErrWrongFieldIndex:
+		return "NX0315"
+	case ErrAssignmentKeyAlreadyInUse:
+		return "NX0316"
+	case ErrWrongAnnotationValue:
+		return "NX0317"
+	case ErrIllegalUseCycle:
+		return "NX0318"
+	case ErrNonNullableUnionFields:
+		return "NX0319"
+	case ErrIndexedEnumAlias:
+		return "NX0320"
+	case ErrUnknownEnumMember:
+		return "NX0321"
+	case ErrDefaultMemberAlreadyDefined:
+		return "NX0322"
+	case ErrWrongDefaultMemberValue:
+		return "NX0323"
+	case ErrCombinedMemberNotFlags:
+		return "NX0324"
+	case ErrWrongFlagsUnderlyingType:
+		return "NX0325"
+	case ErrFlagsMemberNotSingleBit:
+		return "NX0326"
+	case ErrFlagsMemberOverflow:
+		return "NX0327"
+	case ErrWrongDiscriminatorValue:
+		return "NX0328"
+	case ErrWrongUnionTagValue:
+		return "NX0329"
+	case ErrDuplicatedUnionTag:
+		return "NX0330"
+	case ErrOneofNotAllowed:
+		return "NX0331"
+	case ErrEmptyOneof:
+		return "NX0332"
+	case ErrNullableOneofField:
+		return "NX0333"
+	case ErrOneofDefaultAlreadyDefined:
+		return "NX0334"
+	case ErrReservedName:
+		return "NX0335"
+	case ErrNameCollision:
+		return "NX0336"
+	}
+
+	return "NX0300"
+}
+
+// note adds a related location, in the same file as the error
+func (self *AnalyzerError) note(message string, at tokenizer.Pos) *AnalyzerError {
+	return self.noteIn(self.File, message, at)
+}
+
+// noteIn adds a related location in another file
+func (self *AnalyzerError) noteIn(file *parser.File, message string, at tokenizer.Pos) *AnalyzerError {
+	self.Notes = append(self.Notes, diagnostic.Note{File: file.SourcePath(), At: at, Message: message})
+	return self
+}
+
+// Diagnostic converts the error to a diagnostic.Diagnostic
+func (self *AnalyzerError) Diagnostic() *diagnostic.Diagnostic {
+	out := diagnostic.New(diagnostic.Error, self.Code(), self.Kind.Message(), self.File.SourcePath(), self.At)
+	out.Notes = self.Notes
+	return out
 }
 
 type AnalyzerErrorCollection []*AnalyzerError
@@ -153,8 +469,8 @@ func newAnalyzerErrorCollection() *AnalyzerErrorCollection {
 	return &collection
 }
 
-func (self *AnalyzerErrorCollection) push(kind AnalyzerErrorKind, at tokenizer.Pos) {
-	(*self) = append((*self), NewAnalyzerError(kind, at))
+func (self *AnalyzerErrorCollection) push(err *AnalyzerError) {
+	(*self) = append((*self), err)
 }
 
 func (self *AnalyzerErrorCollection) IsEmpty() bool {
@@ -164,12 +480,23 @@ func (self *AnalyzerErrorCollection) IsEmpty() bool {
 func (self *AnalyzerErrorCollection) Display() string {
 	out := make([]string, len(*self))
 	for i, err := range *self {
-		out[i] = fmt.Sprintf("%d:%d -> %s", err.At.Line, err.At.Start, err.Kind.Message())
+		out[i] = fmt.Sprintf("%d:%d -> %s", err.At.Line, err.At.Column, err.Kind.Message())
 	}
 
 	return strings.Join(out, "\n")
 }
 
+// Diagnostics converts every error in the collection to a diagnostic.Diagnostic
+func (self *AnalyzerErrorCollection) Diagnostics() diagnostic.Diagnostics {
+	out := make(diagnostic.Diagnostics, len(*self))
+	for i, err := range *self {
+		out[i] = err.Diagnostic()
+	}
+
+	return out
+}
+
+// AsError returns the errors in the collection as diagnostic.Diagnostics
 func (self *AnalyzerErrorCollection) AsError() error {
-	return errors.New(self.Display())
+	return self.Diagnostics()
 }
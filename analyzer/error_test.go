@@ -0,0 +1,81 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"tomasweigenast.com/nexema/tool/definition"
+	"tomasweigenast.com/nexema/tool/diagnostic"
+	"tomasweigenast.com/nexema/tool/token"
+	"tomasweigenast.com/nexema/tool/tokenizer"
+)
+
+// Codes are documented and can be suppressed, so they must never change
+func TestAnalyzerError_Code(t *testing.T) {
+	tests := []struct {
+		kind AnalyzerErrorKind
+		want string
+	}{
+		{ErrUnknownTypeModifier{}, "NX0301"},
+		{ErrNeedAlias{}, "NX0302"},
+		{ErrTypeNotFound{}, "NX0303"},
+		{ErrNotValidBaseType{}, "NX0304"},
+		{ErrAlreadyDefined{}, "NX0305"},
+		{ErrWrongArgumentsLen{}, "NX0306"},
+		{ErrWrongArguments{}, "NX0307"},
+		{ErrWrongMapKeyType{}, "NX0308"},
+		{ErrLengthNotAllowed{}, "NX0309"},
+		{ErrMisplacedLength{}, "NX0310"},
+		{ErrWrongLength{}, "NX0311"},
+		{ErrMissingArrayLength{}, "NX0312"},
+		{ErrWrongDefaultLength{}, "NX0313"},
+		{ErrDefaultOutOfRange{}, "NX0314"},
+		{ErrWrongFieldIndex{}, "NX0315"},
+		{ErrAssignmentKeyAlreadyInUse{}, "NX0316"},
+		{ErrWrongAnnotationValue{}, "NX0317"},
+		{ErrIllegalUseCycle{}, "NX0318"},
+		{ErrNonNullableUnionFields{}, "NX0319"},
+		{ErrIndexedEnumAlias{}, "NX0320"},
+		{ErrUnknownEnumMember{}, "NX0321"},
+		{ErrDefaultMemberAlreadyDefined{}, "NX0322"},
+		{ErrWrongDefaultMemberValue{}, "NX0323"},
+		{ErrCombinedMemberNotFlags{}, "NX0324"},
+		{ErrWrongFlagsUnderlyingType{}, "NX0325"},
+		{ErrFlagsMemberNotSingleBit{}, "NX0326"},
+		{ErrFlagsMemberOverflow{}, "NX0327"},
+		{ErrWrongDiscriminatorValue{}, "NX0328"},
+		{ErrWrongUnionTagValue{}, "NX0329"},
+		{ErrDuplicatedUnionTag{}, "NX0330"},
+		{ErrOneofNotAllowed{}, "NX0331"},
+		{ErrEmptyOneof{}, "NX0332"},
+		{ErrNullableOneofField{}, "NX0333"},
+		{ErrOneofDefaultAlreadyDefined{}, "NX0334"},
+		{ErrReservedName{}, "NX0335"},
+		{ErrNameCollision{}, "NX0336"},
+	}
+
+	for _, tt := range tests {
+		code := NewAnalyzerError(tt.kind, tokenizer.Pos{}).Code()
+		require.Equal(t, tt.want, code)
+		require.NotNil(t, diagnostic.Explain(code), "%s does not have an explanation", code)
+	}
+}
+
+func TestErrTypeNotFound_Message(t *testing.T) {
+	require.Equal(t, `type "geo.Adress" not found, are you missing an import?`, ErrTypeNotFound{Name: "Adress", Alias: "geo"}.Message())
+	require.Equal(t, `type "strng" not found, did you mean "string"?`, ErrTypeNotFound{Name: "strng", Suggestion: "string"}.Message())
+	require.Equal(t,
+		`type "Adress" not found, did you mean "geo.Address"? add use "common/geo" as geo to import it`,
+		ErrTypeNotFound{Name: "Adress", Suggestion: "geo.Address", Import: `use "common/geo" as geo`}.Message(),
+	)
+}
+
+func TestErrUnknownEnumMember_Message(t *testing.T) {
+	require.Equal(t, `"red" is not a member declared before in the enum`, ErrUnknownEnumMember{Name: "red", Modifier: token.Enum}.Message())
+	require.Equal(t, `"z" is not a member declared before in the flags`, ErrUnknownEnumMember{Name: "z", Modifier: token.Flags}.Message())
+}
+
+func TestErrWrongDefaultLength_Message(t *testing.T) {
+	require.Equal(t, "default value can have at most 3 characters, got 4", ErrWrongDefaultLength{definition.String, 3, 4}.Message())
+	require.Equal(t, "default value must have exactly 3 elements, got 2", ErrWrongDefaultLength{definition.Array, 3, 2}.Message())
+}
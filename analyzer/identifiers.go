@@ -0,0 +1,210 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"tomasweigenast.com/nexema/tool/parser"
+	"tomasweigenast.com/nexema/tool/scope"
+	"tomasweigenast.com/nexema/tool/utils"
+)
+
+// Target is a language code is generated for. Names of types cannot be any of its reserved words, since
+// generators keep them as they are written when they already follow the conventions of the language. Names of
+// fields, members and oneofs cannot be either, unless the language accepts reserved words as member names.
+type Target struct {
+	Name     string
+	reserved map[string]bool
+	members  bool // true if reserved words are not valid member names either
+}
+
+// targets contains every known target, sorted by name. Fields are exported in Go, so they never clash with a
+// keyword, and any word can name a property in TypeScript
+var targets = []*Target{
+	newTarget("csharp", true, `abstract as base bool break byte case catch char checked class const continue decimal default
+		delegate do double else enum event explicit extern false finally fixed float for foreach goto if implicit in int
+		interface internal is lock long namespace new null object operator out override params private protected public
+		readonly ref return sbyte sealed short sizeof stackalloc static string struct switch this throw true try typeof
+		uint ulong unchecked unsafe ushort using virtual void volatile while`),
+	newTarget("dart", true, `assert break case catch class const continue default do else enum extends false final finally
+		for if in is new null rethrow return super switch this throw true try var void while with`),
+	newTarget("go", false, `break case chan const continue default defer else fallthrough for func go goto if import
+		interface map package range return select struct switch type var`),
+	newTarget("java", true, `abstract assert boolean break byte case catch char class const continue default do double else
+		enum extends false final finally float for goto if implements import instanceof int interface long native new
+		null package private protected public return short static strictfp super switch synchronized this throw throws
+		transient true try void volatile while`),
+	newTarget("kotlin", true, `as break class continue do else false for fun if in interface is null object package return
+		super this throw true try typealias typeof val var when while`),
+	newTarget("python", true, `False None True and as assert async await break class continue def del elif else except
+		finally for from global if import in is lambda nonlocal not or pass raise return try while with yield`),
+	newTarget("rust", true, `abstract as async await become box break const continue crate do dyn else enum extern false
+		final fn for if impl in let loop macro match mod move mut override priv pub ref return self Self static struct
+		super trait true try type typeof unsafe unsized use virtual where while yield`),
+	newTarget("swift", true, `Any as associatedtype await break case catch class continue default defer deinit do else enum
+		extension fallthrough false fileprivate for func guard if import in init inout internal is let nil open
+		operator precedencegroup private protocol public repeat rethrows return self Self static struct subscript super
+		switch throw throws true try typealias var where while`),
+	newTarget("typescript", false, `break case catch class const continue debugger default delete do else enum export
+		extends false finally for function if implements import in instanceof interface let new null package private
+		protected public return static super switch this throw true try typeof var void while with yield`),
+}
+
+func newTarget(name string, members bool, reserved string) *Target {
+	target := &Target{Name: name, reserved: map[string]bool{}, members: members}
+	for _, word := range strings.Fields(reserved) {
+		target.reserved[word] = true
+	}
+
+	return target
+}
+
+// Targets returns every known target, sorted by name
+func Targets() []*Target {
+	return targets
+}
+
+// FindTargets returns the targets called names. An error is returned if any of them is not known
+func FindTargets(names []string) ([]*Target, error) {
+	out := make([]*Target, 0, len(names))
+	for _, name := range names {
+		target := findTarget(strings.ToLower(name))
+		if target == nil {
+			known := make([]string, len(targets))
+			for i, target := range targets {
+				known[i] = target.Name
+			}
+
+			return nil, fmt.Errorf("unknown target %q in nexema.yaml, expected one of: %s", name, strings.Join(known, ", "))
+		}
+
+		out = append(out, target)
+	}
+
+	return out, nil
+}
+
+func findTarget(name string) *Target {
+	for _, target := range targets {
+		if target.Name == name {
+			return target
+		}
+	}
+
+	return nil
+}
+
+// IsReserved returns true if name is a reserved word of the target
+func (self *Target) IsReserved(name string) bool {
+	return self.reserved[name]
+}
+
+// IsReservedMember returns true if name is a reserved word of the target that cannot name a field, a member
+// or a oneof
+func (self *Target) IsReservedMember(name string) bool {
+	return self.members && self.reserved[name]
+}
+
+// nameCases are the conversions generators apply to names, in the order they are checked for collisions
+var nameCases = []struct {
+	name    string
+	convert func(string) string
+}{
+	{"snake_case", utils.ToSnakeCase},
+	{"camelCase", utils.ToCamelCase},
+	{"PascalCase", utils.ToPascalCase},
+}
+
+// SetTargets sets the targets whose reserved words cannot be used as names
+func (self *Analyzer) SetTargets(targets []*Target) {
+	self.targets = targets
+}
+
+// validateReservedName reports name if it is a reserved word in any of the checked targets. member tells if it
+// is the name of a field, a member or a oneof, instead of a type
+func (self *Analyzer) validateReservedName(name parser.IdentStmt, member bool) {
+	var reservedIn []string
+	for _, target := range self.targets {
+		reserved := target.IsReserved(name.Token.Literal)
+		if member {
+			reserved = target.IsReservedMember(name.Token.Literal)
+		}
+
+		if reserved {
+			reservedIn = append(reservedIn, target.Name)
+		}
+	}
+
+	if len(reservedIn) > 0 {
+		self.report(ErrReservedName{name.Token.Literal, reservedIn}, name.Pos)
+	}
+}
+
+// validateNameCollisions reports the names that become the same name as a previous one once converted to
+// snake_case, camelCase or PascalCase. Names that are exactly the same are reported by other rules
+func (self *Analyzer) validateNameCollisions(kind string, names []parser.IdentStmt) {
+	for i, name := range names {
+		for _, previous := range names[:i] {
+			if caseName, converted, ok := collide(previous.Token.Literal, name.Token.Literal); ok {
+				self.report(ErrNameCollision{kind, name.Token.Literal, previous.Token.Literal, caseName, converted}, name.Pos).
+					note("previously defined here", previous.Pos)
+				break
+			}
+		}
+	}
+}
+
+// validateTypeNameCollisions reports the types of a package whose name collides with the name of another type of
+// the package once converted, even if they are declared in different files
+func (self *Analyzer) validateTypeNameCollisions(localScopes []*scope.LocalScope) {
+	type declaredType struct {
+		localScope *scope.LocalScope
+		name       parser.IdentStmt
+	}
+
+	types := make([]declaredType, 0)
+	for _, localScope := range localScopes {
+		for _, obj := range *localScope.Objects() {
+			types = append(types, declaredType{localScope, obj.Source().Name})
+		}
+	}
+
+	sort.Slice(types, func(i, j int) bool {
+		a, b := types[i], types[j]
+		if a.localScope != b.localScope {
+			return a.localScope.File().SourcePath() < b.localScope.File().SourcePath()
+		}
+
+		return a.name.Pos.Start < b.name.Pos.Start
+	})
+
+	for i, declared := range types {
+		for _, previous := range types[:i] {
+			caseName, converted, ok := collide(previous.name.Token.Literal, declared.name.Token.Literal)
+			if !ok {
+				continue
+			}
+
+			self.currLocalScope = declared.localScope
+			self.report(ErrNameCollision{"type", declared.name.Token.Literal, previous.name.Token.Literal, caseName, converted}, declared.name.Pos).
+				noteIn(previous.localScope.File(), "previously defined here", previous.name.Pos)
+			break
+		}
+	}
+}
+
+// collide returns the first case in which a and b are converted to the same name, if they are not the same name
+func collide(a, b string) (caseName, converted string, ok bool) {
+	if a == b {
+		return "", "", false
+	}
+
+	for _, nameCase := range nameCases {
+		if converted := nameCase.convert(a); converted == nameCase.convert(b) {
+			return nameCase.name, converted, true
+		}
+	}
+
+	return "", "", false
+}
@@ -0,0 +1,227 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/require"
+	"tomasweigenast.com/nexema/tool/diagnostic"
+	"tomasweigenast.com/nexema/tool/parser"
+	"tomasweigenast.com/nexema/tool/scope"
+	"tomasweigenast.com/nexema/tool/token"
+	"tomasweigenast.com/nexema/tool/tokenizer"
+)
+
+func TestFindTargets(t *testing.T) {
+	got, err := FindTargets([]string{"dart", "Go"})
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	require.Equal(t, "dart", got[0].Name)
+	require.Equal(t, "go", got[1].Name)
+	require.True(t, got[0].IsReserved("class"))
+	require.False(t, got[0].IsReserved("Class"))
+	require.True(t, got[1].IsReserved("type"))
+	require.True(t, got[0].IsReservedMember("class"))
+	require.False(t, got[1].IsReservedMember("type"))
+
+	_, err = FindTargets([]string{"cobol"})
+	require.EqualError(t, err, `unknown target "cobol" in nexema.yaml, expected one of: csharp, dart, go, java, kotlin, python, rust, swift, typescript`)
+}
+
+func TestAnalyzer_ValidateNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    parser.TypeStmt
+		targets  []string
+		wantErrs *AnalyzerErrorCollection
+	}{
+		{
+			name:    "reserved words of other targets",
+			targets: []string{"python"},
+			input: parser.TypeStmt{
+				Name:     parser.IdentStmt{Token: *token.NewToken(token.Ident, "Item")},
+				Modifier: token.Struct,
+				Fields: []parser.FieldStmt{
+					newStringField("type"),
+					newStringField("default"),
+				},
+			},
+			wantErrs: newAnalyzerErrorCollection(),
+		},
+		{
+			name:    "reserved words",
+			targets: []string{"dart", "go", "typescript"},
+			input: parser.TypeStmt{
+				Name:     parser.IdentStmt{Token: *token.NewToken(token.Ident, "Item")},
+				Modifier: token.Struct,
+				Fields: []parser.FieldStmt{
+					newStringField("class"),
+					newStringField("type"),
+					newStringField("name"),
+				},
+				Oneofs: []parser.OneofStmt{
+					{Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "default")}},
+				},
+			},
+			wantErrs: &AnalyzerErrorCollection{
+				NewAnalyzerError(ErrEmptyOneof{Name: "default"}, *tokenizer.NewPos()),
+				NewAnalyzerError(ErrReservedName{Name: "class", Targets: []string{"dart"}}, *tokenizer.NewPos()),
+				NewAnalyzerError(ErrReservedName{Name: "default", Targets: []string{"dart"}}, *tokenizer.NewPos()),
+			},
+		},
+		{
+			name:    "reserved words are valid member names in go and typescript",
+			targets: []string{"go", "typescript"},
+			input: parser.TypeStmt{
+				Name:     parser.IdentStmt{Token: *token.NewToken(token.Ident, "Item")},
+				Modifier: token.Struct,
+				Fields: []parser.FieldStmt{
+					newStringField("class"),
+					newStringField("type"),
+					newStringField("map"),
+				},
+			},
+			wantErrs: newAnalyzerErrorCollection(),
+		},
+		{
+			name:    "reserved type name in typescript",
+			targets: []string{"go", "typescript"},
+			input: parser.TypeStmt{
+				Name:     parser.IdentStmt{Token: *token.NewToken(token.Ident, "interface")},
+				Modifier: token.Struct,
+			},
+			wantErrs: &AnalyzerErrorCollection{
+				NewAnalyzerError(ErrReservedName{Name: "interface", Targets: []string{"go", "typescript"}}, *tokenizer.NewPos()),
+			},
+		},
+		{
+			name:    "reserved type name",
+			targets: []string{"rust"},
+			input: parser.TypeStmt{
+				Name:     parser.IdentStmt{Token: *token.NewToken(token.Ident, "Self")},
+				Modifier: token.Struct,
+			},
+			wantErrs: &AnalyzerErrorCollection{
+				NewAnalyzerError(ErrReservedName{Name: "Self", Targets: []string{"rust"}}, *tokenizer.NewPos()),
+			},
+		},
+		{
+			name: "names that collide once converted",
+			input: parser.TypeStmt{
+				Name:     parser.IdentStmt{Token: *token.NewToken(token.Ident, "User")},
+				Modifier: token.Struct,
+				Fields: []parser.FieldStmt{
+					newStringField("user_id"),
+					newStringField("userId"),
+					newStringField("UserID"),
+					newStringField("first_name"),
+					newStringField("firstname"),
+				},
+			},
+			wantErrs: &AnalyzerErrorCollection{
+				NewAnalyzerError(ErrNameCollision{Kind: "field", Name: "userId", Other: "user_id", Case: "snake_case", Converted: "user_id"}, *tokenizer.NewPos()).note("previously defined here", *tokenizer.NewPos()),
+				NewAnalyzerError(ErrNameCollision{Kind: "field", Name: "UserID", Other: "user_id", Case: "snake_case", Converted: "user_id"}, *tokenizer.NewPos()).note("previously defined here", *tokenizer.NewPos()),
+			},
+		},
+		{
+			name: "enum members that collide once converted",
+			input: parser.TypeStmt{
+				Name:     parser.IdentStmt{Token: *token.NewToken(token.Ident, "Status")},
+				Modifier: token.Enum,
+				Fields: []parser.FieldStmt{
+					{Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "unknown")}},
+					{Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "in_progress")}},
+					{Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "IN_PROGRESS")}},
+				},
+			},
+			wantErrs: &AnalyzerErrorCollection{
+				NewAnalyzerError(ErrNameCollision{Kind: "field", Name: "IN_PROGRESS", Other: "in_progress", Case: "snake_case", Converted: "in_progress"}, *tokenizer.NewPos()).note("previously defined here", *tokenizer.NewPos()),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			targets, err := FindTargets(test.targets)
+			require.NoError(t, err)
+
+			analyzer := NewAnalyzer([]*scope.Scope{})
+			analyzer.SetTargets(targets)
+			analyzer.currScope = &scope.Scope{}
+			analyzer.currLocalScope = scope.NewLocalScope(nil, nil, nil)
+
+			analyzer.analyzeTypeStmt(&test.input)
+			if diff := cmp.Diff(test.wantErrs, analyzer.errors, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("TestAnalyzer_ValidateNames: %s: wantErrs mismatch (-want +got):\n%s", test.name, diff)
+			}
+		})
+	}
+}
+
+func TestAnalyzer_ValidateTypeNameCollisions(t *testing.T) {
+	newType := func(name string, line int) *scope.Object {
+		return scope.NewObject(&parser.TypeStmt{
+			Name:     parser.IdentStmt{Token: *token.NewToken(token.Ident, name), Pos: tokenizer.Pos{Start: line * 10, End: line*10 + len(name), Line: line, Endline: line, Column: 5, EndColumn: 5 + len(name)}},
+			Modifier: token.Struct,
+			Fields:   []parser.FieldStmt{newStringField("id")},
+		})
+	}
+
+	accounts := &parser.File{Path: "identity", FileName: "accounts.nex"}
+	users := &parser.File{Path: "identity", FileName: "users.nex"}
+
+	s := scope.NewScope("identity", "identity")
+	s.PushLocalScope(scope.NewLocalScope(users, map[string]*scope.Import{}, map[string]*scope.Object{
+		"user_account": newType("user_account", 1),
+		"UserId":       newType("UserId", 5),
+	}))
+	s.PushLocalScope(scope.NewLocalScope(accounts, map[string]*scope.Import{}, map[string]*scope.Object{
+		"UserAccount": newType("UserAccount", 1),
+		"Account":     newType("Account", 3),
+		"USER_ID":     newType("USER_ID", 7),
+	}))
+
+	analyzer := NewAnalyzer([]*scope.Scope{s})
+	analyzer.Analyze()
+
+	want := diagnostic.Diagnostics{
+		{
+			Severity: diagnostic.Error,
+			Code:     "NX0336",
+			Message:  `type "user_account" collides with "UserAccount" once converted to snake_case, since both become "user_account"`,
+			File:     "identity/users.nex",
+			At:       tokenizer.Pos{Start: 10, End: 22, Line: 1, Endline: 1, Column: 5, EndColumn: 17},
+			Notes: []diagnostic.Note{
+				{File: "identity/accounts.nex", At: tokenizer.Pos{Start: 10, End: 21, Line: 1, Endline: 1, Column: 5, EndColumn: 16}, Message: "previously defined here"},
+			},
+		},
+		{
+			Severity: diagnostic.Error,
+			Code:     "NX0336",
+			Message:  `type "UserId" collides with "USER_ID" once converted to snake_case, since both become "user_id"`,
+			File:     "identity/users.nex",
+			At:       tokenizer.Pos{Start: 50, End: 56, Line: 5, Endline: 5, Column: 5, EndColumn: 11},
+			Notes: []diagnostic.Note{
+				{File: "identity/accounts.nex", At: tokenizer.Pos{Start: 70, End: 77, Line: 7, Endline: 7, Column: 5, EndColumn: 12}, Message: "previously defined here"},
+			},
+		},
+	}
+
+	got := analyzer.Errors().Diagnostics()
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("TestAnalyzer_ValidateTypeNameCollisions: mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestErrReservedName_Message(t *testing.T) {
+	require.Equal(t, `"class" is a reserved word in dart, so the generated code would not compile`, ErrReservedName{"class", []string{"dart"}}.Message())
+	require.Equal(t, `"default" is a reserved word in dart, go and typescript, so the generated code would not compile`, ErrReservedName{"default", []string{"dart", "go", "typescript"}}.Message())
+}
+
+func newStringField(name string) parser.FieldStmt {
+	return parser.FieldStmt{
+		Name:      parser.IdentStmt{Token: *token.NewToken(token.Ident, name)},
+		ValueType: &parser.DeclStmt{Token: *token.NewToken(token.Ident, "string")},
+	}
+}
@@ -14,9 +14,12 @@ import (
 	"gopkg.in/yaml.v3"
 	"tomasweigenast.com/nexema/tool/analyzer"
 	"tomasweigenast.com/nexema/tool/definition"
+	"tomasweigenast.com/nexema/tool/diagnostic"
 	"tomasweigenast.com/nexema/tool/linker"
+	"tomasweigenast.com/nexema/tool/lint"
 	"tomasweigenast.com/nexema/tool/nexema"
 	"tomasweigenast.com/nexema/tool/parser"
+	"tomasweigenast.com/nexema/tool/scope"
 )
 
 const nexExtension = ".nex"
@@ -26,20 +29,33 @@ const builderVersion = 1
 type Builder struct {
 	inputPath string // the path to the input folder
 
-	config   *nexema.NexemaProjectConfig
-	snapshot *definition.NexemaSnapshot // the generated snapshot
+	config       *nexema.NexemaProjectConfig
+	lintSettings lint.Settings              // the lint rules enabled in config
+	targets      []*analyzer.Target         // the targets listed in config
+	snapshot     *definition.NexemaSnapshot // the generated snapshot
+	scopes       []*scope.Scope             // the scopes linked in the last build
 
 	parserErrors parser.ParserErrorCollection
 	parseTree    *parser.ParseTree
+	warnings     diagnostic.Diagnostics // warnings reported while linking
+	sources      diagnostic.Sources     // the contents of the parsed files, to render diagnostics
+	paths        map[string]string      // the path in disk of the parsed files, keyed by the path used in diagnostics
 }
 
 func NewBuilder(inputPath string) *Builder {
 	return &Builder{
 		inputPath: inputPath,
 		parseTree: parser.NewParseTree(),
+		sources:   diagnostic.Sources{},
+		paths:     map[string]string{},
 	}
 }
 
+// Root returns the path to the folder of the project, which the files of the diagnostics are relative to
+func (self *Builder) Root() string {
+	return self.inputPath
+}
+
 // Config returns the discovered nexema.yaml file
 func (self *Builder) Config() *nexema.NexemaProjectConfig {
 	return self.config
@@ -55,7 +71,10 @@ func (self *Builder) Discover() error {
 	return nil
 }
 
-// Build builds a Nexema snapshot. It does not generates files
+// Build builds a Nexema snapshot. It does not generates files.
+//
+// If the project contains errors, the returned error is a diagnostic.Diagnostics with every error
+// found in the first failing stage, sorted by file and position
 func (self *Builder) Build() error {
 	if self.config == nil {
 		panic("this must be called after Discover method")
@@ -86,33 +105,42 @@ func (self *Builder) Build() error {
 
 	// at this moment, if any parse error is encountered, return as error
 	if len(self.parserErrors) > 0 {
-		return self.parserErrors.AsError()
+		return sorted(self.parserErrors.Diagnostics())
 	}
 
 	// link
 	linker := linker.NewLinker(self.parseTree)
 	linker.Link()
+	self.warnings = sorted(linker.Warnings().Diagnostics())
 
 	if linker.HasLinkErrors() {
-		return linker.Errors().AsError()
+		return sorted(linker.Errors().Diagnostics())
 	}
 
 	// run analyzer
-	analyzer := analyzer.NewAnalyzer(linker.LinkedScopes())
+	self.scopes = linker.LinkedScopes()
+	analyzer := analyzer.NewAnalyzer(self.scopes)
+	analyzer.SetTargets(self.targets)
 	analyzer.Analyze()
 
 	if analyzer.HasAnalysisErrors() {
-		return analyzer.Errors().AsError()
+		return sorted(analyzer.Errors().Diagnostics())
 	}
 
 	// build snapshot
 	files := make([]definition.NexemaFile, 0)
+	packages := make([]definition.NexemaPackage, 0)
 	ids := make([]string, 0)
 	for _, file := range analyzer.Files() {
 		files = append(files, file)
 		ids = append(ids, file.Id)
 	}
 
+	for _, pkg := range analyzer.Packages() {
+		packages = append(packages, pkg)
+		ids = append(ids, pkg.Id)
+	}
+
 	snapshotHashcode, err := hashstructure.Hash(&ids, hashstructure.FormatV2, &hashstructure.HashOptions{})
 	if err != nil {
 		return err
@@ -120,6 +148,7 @@ func (self *Builder) Build() error {
 
 	self.snapshot = &definition.NexemaSnapshot{
 		Version:  builderVersion,
+		Packages: packages,
 		Files:    files,
 		Hashcode: fmt.Sprint(snapshotHashcode),
 	}
@@ -127,6 +156,28 @@ func (self *Builder) Build() error {
 	return nil
 }
 
+// Lint checks the lint rules enabled in nexema.yaml and returns the diagnostics they reported, sorted by file
+// and position. This method must be called after a successful self.Build
+func (self *Builder) Lint() (diagnostic.Diagnostics, error) {
+	return self.LintWith(self.lintSettings, false)
+}
+
+// LintWith checks the rules of settings, instead of the ones enabled in nexema.yaml, and returns the diagnostics
+// they reported, sorted by file and position. If ignoreNolint is true, the diagnostics suppressed by nolint
+// annotations are reported too. This method must be called after a successful self.Build
+func (self *Builder) LintWith(settings lint.Settings, ignoreNolint bool) (diagnostic.Diagnostics, error) {
+	if self.snapshot == nil {
+		return nil, errors.New("definition not build")
+	}
+
+	linter := lint.NewLinter(self.scopes, self.snapshot, self.sources, settings)
+	if ignoreNolint {
+		linter.IgnoreNolint()
+	}
+
+	return linter.Lint(), nil
+}
+
 // SaveSnapshot generates and saves a snapshot file for a previously built definition.
 // This method must be called after self.Build
 func (self *Builder) SaveSnapshot(outFolder string) (filename string, err error) {
@@ -161,6 +212,22 @@ func (self *Builder) HasOutput() bool {
 	return self.snapshot != nil && self.snapshot.Hashcode != "0"
 }
 
+// Warnings returns the warnings reported during the last build, if any
+func (self *Builder) Warnings() diagnostic.Diagnostics {
+	return self.warnings
+}
+
+// Sources returns the contents of the files parsed during the last build, keyed by the path used in diagnostics
+func (self *Builder) Sources() diagnostic.Sources {
+	return self.sources
+}
+
+// FilePath returns the path in disk of a file parsed during the last build, given the path used in diagnostics,
+// or an empty string if it was not parsed
+func (self *Builder) FilePath(file string) string {
+	return self.paths[file]
+}
+
 // Snapshot returns the built NexemaSnapshot
 func (self *Builder) Snapshot() *definition.NexemaSnapshot {
 	return self.snapshot
@@ -187,6 +254,16 @@ func (self *Builder) scanProject() error {
 		return fmt.Errorf("you must specify at least one generator in nexema.yaml")
 	}
 
+	self.lintSettings, err = lint.NewSettings(self.config.Lint)
+	if err != nil {
+		return err
+	}
+
+	self.targets, err = analyzer.FindTargets(self.config.Targets)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -203,10 +280,14 @@ func (self *Builder) parseFile(p string) error {
 		packagePath = "root"
 	}
 
-	parser := parser.NewParser(bytes.NewBuffer(fileContents), &parser.File{
+	file := &parser.File{
 		FileName: path.Base(p),
 		Path:     packagePath,
-	})
+	}
+	self.sources[file.SourcePath()] = fileContents
+	self.paths[file.SourcePath()] = p
+
+	parser := parser.NewParser(bytes.NewBuffer(fileContents), file)
 
 	parser.Begin()
 
@@ -217,3 +298,9 @@ func (self *Builder) parseFile(p string) error {
 
 	return nil
 }
+
+// sorted sorts diagnostics by file and position and returns them
+func sorted(diagnostics diagnostic.Diagnostics) diagnostic.Diagnostics {
+	diagnostics.Sort()
+	return diagnostics
+}
@@ -1,11 +1,16 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
+	"os"
+
 	"github.com/sirupsen/logrus"
 	"tomasweigenast.com/nexema/tool/builder"
+	"tomasweigenast.com/nexema/tool/diagnostic"
 )
 
-func buildCmd(path, snapshotOut string) error {
+func buildCmd(path, snapshotOut string, format diagnostic.Format) error {
 	builder := builder.NewBuilder(path)
 	err := builder.Discover()
 	if err != nil {
@@ -15,9 +20,11 @@ func buildCmd(path, snapshotOut string) error {
 	logrus.Infof("Building project...")
 	err = builder.Build()
 	if err != nil {
-		return err
+		return buildFailed(builder, err, format)
 	}
 
+	printDiagnostics(builder.Warnings(), builder.Sources(), builder.Root(), format)
+
 	if !builder.HasOutput() {
 		logrus.Infoln("Nothing to build")
 		return nil
@@ -37,3 +44,40 @@ func buildCmd(path, snapshotOut string) error {
 
 	return nil
 }
+
+// buildFailed prints the warnings and errors of a failed build, if err contains them, and returns the error to report
+func buildFailed(builder *builder.Builder, err error, format diagnostic.Format) error {
+	var errs diagnostic.Diagnostics
+	if !errors.As(err, &errs) {
+		return err
+	}
+
+	diagnostics := make(diagnostic.Diagnostics, 0, len(builder.Warnings())+len(errs))
+	diagnostics = append(diagnostics, builder.Warnings()...)
+	diagnostics = append(diagnostics, errs...)
+	printDiagnostics(diagnostics, builder.Sources(), builder.Root(), format)
+
+	return fmt.Errorf("build failed with %d error(s)", len(errs))
+}
+
+// printDiagnostics writes diagnostics, whose files are relative to root, to stdout in the given format. Text is
+// colored if stdout is a terminal
+func printDiagnostics(diagnostics diagnostic.Diagnostics, sources diagnostic.Sources, root string, format diagnostic.Format) {
+	if len(diagnostics) == 0 && !format.IsMachineReadable() {
+		return
+	}
+
+	if err := diagnostic.Write(os.Stdout, format, diagnostics, sources, root, isTerminal(os.Stdout)); err != nil {
+		logrus.Errorln(err)
+	}
+}
+
+// isTerminal returns true if file is a terminal and not a pipe or a regular file
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"fmt"
+
+	"tomasweigenast.com/nexema/tool/diagnostic"
+)
+
+// explainCmd prints the explanation of code, or the code and title of every explanation if code is empty
+func explainCmd(code string) error {
+	if len(code) == 0 {
+		for _, explanation := range diagnostic.Explanations() {
+			fmt.Printf("%s  %s\n", explanation.Code, explanation.Title)
+		}
+
+		return nil
+	}
+
+	explanation := diagnostic.Explain(code)
+	if explanation == nil {
+		return fmt.Errorf("there is no explanation for %q, run \"nexema explain\" to list every code", code)
+	}
+
+	fmt.Print(explanation.Text)
+	return nil
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+	"tomasweigenast.com/nexema/tool/builder"
+	"tomasweigenast.com/nexema/tool/diagnostic"
+	"tomasweigenast.com/nexema/tool/lint"
+)
+
+// fixCmd builds the project at path and rewrites its files applying the chosen fixes. It fails if the build fails.
+//
+// If pinIndexes is true, every field that does not declare its index gets the one it has now written before its
+// name, so adding a field before it does not change it, and so does every flags member that does not declare its
+// value. Fields are pinned even if a nolint annotation suppresses the implicit-index rule for them, since the fix
+// is chosen explicitly. The rest of the files are left as they are
+func fixCmd(path string, pinIndexes bool, format diagnostic.Format) error {
+	if !pinIndexes {
+		return cli.Exit("nothing to fix, choose a fix like --pin-indexes", 1)
+	}
+
+	builder := builder.NewBuilder(path)
+	err := builder.Discover()
+	if err != nil {
+		return err
+	}
+
+	err = builder.Build()
+	if err != nil {
+		return buildFailed(builder, err, format)
+	}
+
+	// the implicit-index rule reports the fields that do not declare their index, with an edit that writes it
+	diagnostics, err := builder.LintWith(lint.Settings{lint.FindRule("implicit-index"): diagnostic.Warning}, true)
+	if err != nil {
+		return err
+	}
+
+	fixed, err := applyFixes(builder, diagnostics)
+	if err != nil {
+		return err
+	}
+
+	logrus.Infof("Pinned the index or value of %d field(s)", fixed)
+	return nil
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/karrick/godirwalk"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+	"tomasweigenast.com/nexema/tool/diagnostic"
+	"tomasweigenast.com/nexema/tool/format"
+	"tomasweigenast.com/nexema/tool/parser"
+)
+
+// formatCmd formats every .nex file at path, which can be a project folder or a single file.
+//
+// If check or diff are true, files are not written. check fails if any file is not formatted, printing its
+// name, and diff prints the changes formatting would make
+func formatCmd(path string, check, diff bool) error {
+	root, files, err := nexFiles(path)
+	if err != nil {
+		return err
+	}
+
+	sources := diagnostic.Sources{}
+	errs := make(diagnostic.Diagnostics, 0)
+	failed, unformatted := 0, 0
+	for _, file := range files {
+		contents, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("could not read file %s. %s", file, err)
+		}
+
+		name, _ := filepath.Rel(root, file)
+		name = filepath.ToSlash(name)
+		sources[name] = contents
+
+		formatted, err := format.Source(contents, &parser.File{Path: filepath.ToSlash(filepath.Dir(name)), FileName: filepath.Base(name)})
+		if err != nil {
+			var fileErrs diagnostic.Diagnostics
+			if !errors.As(err, &fileErrs) {
+				return fmt.Errorf("could not format %s. %s", name, err)
+			}
+
+			errs = append(errs, fileErrs...)
+			failed++
+			continue
+		}
+
+		if bytes.Equal(contents, formatted) {
+			continue
+		}
+
+		unformatted++
+		switch {
+		case diff:
+			unified, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+				A:        difflib.SplitLines(string(contents)),
+				B:        difflib.SplitLines(string(formatted)),
+				FromFile: "a/" + name,
+				ToFile:   "b/" + name,
+				Context:  3,
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Print(unified)
+
+		case check:
+			fmt.Println(name)
+
+		default:
+			if err := os.WriteFile(file, formatted, os.ModePerm); err != nil {
+				return fmt.Errorf("could not write file %s. %s", file, err)
+			}
+
+			logrus.Infof("Formatted %s", name)
+		}
+	}
+
+	errs.Sort()
+	printDiagnostics(errs, sources, root, diagnostic.FormatText)
+
+	if failed > 0 {
+		return cli.Exit(fmt.Sprintf("%d file(s) could not be formatted", failed), 1)
+	}
+
+	if check && unformatted > 0 {
+		return cli.Exit(fmt.Sprintf("%d file(s) are not formatted, run \"nexema format\" to format them", unformatted), 1)
+	}
+
+	return nil
+}
+
+// nexFiles returns the .nex files at path, sorted, and the folder their names are relative to. If path is a
+// file, it is the only one returned
+func nexFiles(path string) (root string, files []string, err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if !info.IsDir() {
+		return filepath.Dir(path), []string{path}, nil
+	}
+
+	files = make([]string, 0)
+	err = godirwalk.Walk(path, &godirwalk.Options{
+		Callback: func(osPathname string, de *godirwalk.Dirent) error {
+			if !de.IsDir() && filepath.Ext(osPathname) == ".nex" {
+				files = append(files, osPathname)
+			}
+
+			return nil
+		},
+		Unsorted: true,
+	})
+
+	sort.Strings(files)
+	return path, files, err
+}
@@ -8,11 +8,13 @@ import (
 	"strings"
 
 	jsoniter "github.com/json-iterator/go"
+	"github.com/sirupsen/logrus"
 	"tomasweigenast.com/nexema/tool/builder"
 	"tomasweigenast.com/nexema/tool/definition"
+	"tomasweigenast.com/nexema/tool/diagnostic"
 )
 
-func generateCmd(path, snapshotFile string, generateFor []string) error {
+func generateCmd(path, snapshotFile string, generateFor []string, format diagnostic.Format) error {
 	builder := builder.NewBuilder(path)
 	err := builder.Discover()
 	if err != nil {
@@ -33,9 +35,10 @@ func generateCmd(path, snapshotFile string, generateFor []string) error {
 	} else {
 		err = builder.Build()
 		if err != nil {
-			return err
+			return buildFailed(builder, err, format)
 		}
 
+		printDiagnostics(builder.Warnings(), builder.Sources(), builder.Root(), format)
 		snapshot = builder.Snapshot()
 	}
 
@@ -104,7 +107,7 @@ func generateCmd(path, snapshotFile string, generateFor []string) error {
 		}
 	}
 
-	fmt.Printf("Wrote %d files successfully.\n", wroteCount)
+	logrus.Infof("Wrote %d files successfully.", wroteCount)
 
 	return nil
 }
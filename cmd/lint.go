@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"tomasweigenast.com/nexema/tool/builder"
+	"tomasweigenast.com/nexema/tool/diagnostic"
+	"tomasweigenast.com/nexema/tool/lint"
+)
+
+// lintCmd builds the project at path and checks the lint rules enabled in its nexema.yaml.
+// It fails if the build fails or if any rule is configured as an error and reports something.
+//
+// If fix is true, the diagnostics that can be fixed automatically are fixed instead of reported, and the project
+// is linted again to report the rest
+func lintCmd(path string, format diagnostic.Format, fix bool) error {
+	builder := builder.NewBuilder(path)
+	err := builder.Discover()
+	if err != nil {
+		return err
+	}
+
+	logrus.Infof("Linting project...")
+	err = builder.Build()
+	if err != nil {
+		return buildFailed(builder, err, format)
+	}
+
+	lintDiagnostics, err := builder.Lint()
+	if err != nil {
+		return err
+	}
+
+	if fix {
+		fixed, err := applyFixes(builder, lintDiagnostics)
+		if err != nil {
+			return err
+		}
+
+		if fixed > 0 {
+			logrus.Infof("Fixed %d problem(s)", fixed)
+			return lintCmd(path, format, false)
+		}
+	}
+
+	diagnostics := make(diagnostic.Diagnostics, 0, len(builder.Warnings())+len(lintDiagnostics))
+	diagnostics = append(diagnostics, builder.Warnings()...)
+	diagnostics = append(diagnostics, lintDiagnostics...)
+	diagnostics.Sort()
+	printDiagnostics(diagnostics, builder.Sources(), builder.Root(), format)
+
+	var errorCount int
+	for _, lintDiagnostic := range lintDiagnostics {
+		if lintDiagnostic.Severity == diagnostic.Error {
+			errorCount++
+		}
+	}
+
+	if errorCount > 0 {
+		return fmt.Errorf("lint failed with %d error(s)", errorCount)
+	}
+
+	return nil
+}
+
+// lintRulesCmd prints every lint rule, if it is enabled by default and what it checks
+func lintRulesCmd() {
+	for _, rule := range lint.Rules() {
+		state := "off"
+		if rule.Enabled {
+			state = rule.Severity.String()
+		}
+
+		description := rule.Description
+		if rule.Fixable {
+			description += " (fixable)"
+		}
+
+		fmt.Printf("%s  %-20s %-8s %s\n", rule.Code, rule.Name, state, description)
+	}
+}
+
+// applyFixes writes the edits of diagnostics to the files they belong to, returning the number of diagnostics fixed
+func applyFixes(builder *builder.Builder, diagnostics diagnostic.Diagnostics) (int, error) {
+	edits := map[string][]diagnostic.Edit{}
+	fixed := 0
+	for _, lintDiagnostic := range diagnostics {
+		if len(lintDiagnostic.Edits) > 0 {
+			edits[lintDiagnostic.File] = append(edits[lintDiagnostic.File], lintDiagnostic.Edits...)
+			fixed++
+		}
+	}
+
+	for file, fileEdits := range edits {
+		contents, err := diagnostic.ApplyEdits(builder.Sources()[file], fileEdits)
+		if err != nil {
+			return 0, fmt.Errorf("could not fix %s. %s", file, err)
+		}
+
+		if err := os.WriteFile(builder.FilePath(file), contents, os.ModePerm); err != nil {
+			return 0, fmt.Errorf("could not write file %s. %s", file, err)
+		}
+	}
+
+	return fixed, nil
+}
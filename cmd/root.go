@@ -1,10 +1,12 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/urfave/cli/v2"
+	"tomasweigenast.com/nexema/tool/diagnostic"
 	"tomasweigenast.com/nexema/tool/nexema"
 )
 
@@ -28,11 +30,20 @@ About:
 
 var app *cli.App
 
+// diagnosticsFormatFlag selects how build warnings and errors are printed
+var diagnosticsFormatFlag = &cli.StringFlag{
+	Name:  "diagnostics-format",
+	Value: string(diagnostic.FormatText),
+	Usage: "The format of the build diagnostics: text, json or sarif",
+}
+
 func init() {
 	app = &cli.App{
 		CustomAppHelpTemplate: helpText,
 		CommandNotFound:       cli.ShowCommandCompletions,
-		Flags:                 []cli.Flag{
+		// errors are printed by run, which returns their exit status, so the files of nexema are closed before exiting
+		ExitErrHandler: func(*cli.Context, error) {},
+		Flags:          []cli.Flag{
 			// &cli.BoolFlag{
 			// 	Name:        "verbose",
 			// 	Required:    false,
@@ -109,14 +120,19 @@ func init() {
 					Name:  "out",
 					Usage: "The path to the output folder where to write the snapshot file",
 				},
+				diagnosticsFormatFlag,
 			},
 			Action: func(c *cli.Context) error {
 				path := c.Args().First()
 				if len(path) == 0 {
 					return cli.NewExitError("path is required", 1)
 				}
+				format, err := diagnostic.ParseFormat(c.String(diagnosticsFormatFlag.Name))
+				if err != nil {
+					return cli.NewExitError(err.Error(), 1)
+				}
 				outputPath := c.String("out")
-				return buildCmd(path, outputPath)
+				return buildCmd(path, outputPath, format)
 			},
 		},
 		{
@@ -133,6 +149,7 @@ func init() {
 					Name:     "for",
 					Usage:    "the generators to use and their output path",
 				},
+				diagnosticsFormatFlag,
 			},
 			Action: func(c *cli.Context) error {
 
@@ -142,27 +159,112 @@ func init() {
 					return cli.NewExitError("path is required", 1)
 				}
 
+				format, err := diagnostic.ParseFormat(c.String(diagnosticsFormatFlag.Name))
+				if err != nil {
+					return cli.NewExitError(err.Error(), 1)
+				}
+
 				generateFor := c.StringSlice("for")
 
-				return generateCmd(path, snapshotPath, generateFor)
+				return generateCmd(path, snapshotPath, generateFor, format)
+			},
+		},
+		{
+			Name:      "lint",
+			Usage:     "Builds a project and checks the lint rules enabled in nexema.yaml",
+			ArgsUsage: "[the path to the project]",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "rules",
+					Usage: "List every lint rule instead of linting a project",
+				},
+				&cli.BoolFlag{
+					Name:  "fix",
+					Usage: "Fix the problems that can be fixed automatically, like unused imports",
+				},
+				diagnosticsFormatFlag,
+			},
+			Action: func(c *cli.Context) error {
+				if c.Bool("rules") {
+					lintRulesCmd()
+					return nil
+				}
+
+				path := c.Args().First()
+				if len(path) == 0 {
+					return cli.NewExitError("path is required", 1)
+				}
+
+				format, err := diagnostic.ParseFormat(c.String(diagnosticsFormatFlag.Name))
+				if err != nil {
+					return cli.NewExitError(err.Error(), 1)
+				}
+
+				return lintCmd(path, format, c.Bool("fix"))
 			},
 		},
 		{
-			Name:  "format",
-			Usage: "Format all .nex files in the specified project",
+			Name:      "fix",
+			Usage:     "Builds a project and rewrites its files applying the chosen fixes",
+			ArgsUsage: "[the path to the project]",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "pin-indexes",
+					Usage: "Write the index of every field that does not declare it, so adding a field before it does not change it",
+				},
+				diagnosticsFormatFlag,
+			},
+			Action: func(c *cli.Context) error {
+				path := c.Args().First()
+				if len(path) == 0 {
+					return cli.NewExitError("path is required", 1)
+				}
+
+				format, err := diagnostic.ParseFormat(c.String(diagnosticsFormatFlag.Name))
+				if err != nil {
+					return cli.NewExitError(err.Error(), 1)
+				}
+
+				return fixCmd(path, c.Bool("pin-indexes"), format)
+			},
+		},
+		{
+			Name:      "explain",
+			Usage:     "Explains a diagnostic code, with examples, or lists every code if none is given",
+			ArgsUsage: "[code, like NX0102]",
+			Action: func(c *cli.Context) error {
+				return explainCmd(c.Args().First())
+			},
+		},
+		{
+			Name:      "format",
+			Usage:     "Formats the .nex files of a project, or a single file",
+			ArgsUsage: "[the path to the project or file]",
 			Flags: []cli.Flag{
 				&cli.StringFlag{
 					Name:  "path",
-					Usage: "Path to the project directory",
+					Usage: "Path to the project directory, if it is not given as argument",
+				},
+				&cli.BoolFlag{
+					Name:  "check",
+					Usage: "Do not write the files, fail if any of them is not formatted",
+				},
+				&cli.BoolFlag{
+					Name:  "diff",
+					Usage: "Do not write the files, print the changes formatting would make",
 				},
 			},
 			Action: func(c *cli.Context) error {
-				path := c.String("path")
-				if path == "" {
+				path := c.Args().First()
+				if len(path) == 0 {
+					path = c.String("path")
+				}
+
+				if len(path) == 0 {
 					return cli.NewExitError("path is required", 1)
 				}
-				fmt.Printf("Formatting code for the project at %s...\n", path)
-				return nil
+
+				return formatCmd(path, c.Bool("check"), c.Bool("diff"))
 			},
 		},
 		{
@@ -192,11 +294,28 @@ func init() {
 
 func Execute() {
 	nexema.Run()
+	status := run(os.Args)
+	nexema.Exit()
+	os.Exit(status)
+}
 
-	err := app.Run(os.Args)
-	if err != nil {
-		fmt.Println(err)
+// run runs the command given in args and returns the exit status, which is 1 if the command fails, like when a
+// build reports errors, or the one of the error if it is a cli.ExitCoder
+func run(args []string) int {
+	err := app.Run(args)
+	if err == nil {
+		return 0
 	}
 
-	nexema.Exit()
+	// errors go to stderr, so stdout only contains the diagnostics when they are machine readable
+	if len(err.Error()) > 0 {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	var exitErr cli.ExitCoder
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+
+	return 1
 }
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun_ExitStatus(t *testing.T) {
+	valid := writeProject(t, "type A struct {\n\ta string\n}\n")
+	invalid := writeProject(t, "type A struct {\n\ta strng\n}\n")
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"build succeeds", []string{"nexema", "build", valid}, 0},
+		{"build fails", []string{"nexema", "build", invalid}, 1},
+		{"build without path", []string{"nexema", "build"}, 1},
+		{"generate fails", []string{"nexema", "generate", "--for", "js=out", invalid}, 1},
+		{"lint fails", []string{"nexema", "lint", invalid}, 1},
+		{"format check fails", []string{"nexema", "format", "--check", writeProject(t, "type A struct { a string }")}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, run(tt.args))
+		})
+	}
+}
+
+// writeProject writes a project with a single file, whose contents are source, in a temporary directory
+func writeProject(t *testing.T, source string) string {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "nexema.yaml"), []byte("version: 1\ngenerators:\n  js:\n"), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "foo"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "foo", "a.nex"), []byte(source), 0644))
+	return dir
+}
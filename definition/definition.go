@@ -9,11 +9,22 @@ type NexemaFile struct {
 	Types       []TypeDefinition `json:"types"`       // The list of types defined
 }
 
+// NexemaPackage represents a folder of .nex files, its documentation and annotations
+type NexemaPackage struct {
+	Id            string      `json:"id"`            // The id of the package
+	Name          string      `json:"name"`          // The name of the package
+	Path          string      `json:"path"`          // The path to the package, relative to nexema.yaml
+	Documentation []string    `json:"documentation"` // The documentation of the package
+	Annotations   Assignments `json:"annotations"`   // The annotations of the package
+	Files         []string    `json:"files"`         // The ids of the files declared in the package
+}
+
 // NexemaSnapshot represents a generated project definition
 type NexemaSnapshot struct {
-	Version  int          `json:"version"` // The Nexema version, at the moment, always 1
-	Hashcode string       `json:"hashcode"`
-	Files    []NexemaFile `json:"files"`
+	Version  int             `json:"version"` // The Nexema version, at the moment, always 1
+	Hashcode string          `json:"hashcode"`
+	Packages []NexemaPackage `json:"packages"`
+	Files    []NexemaFile    `json:"files"`
 }
 
 func (s *NexemaSnapshot) FindFile(id string) *NexemaFile {
@@ -25,3 +36,13 @@ func (s *NexemaSnapshot) FindFile(id string) *NexemaFile {
 
 	return nil
 }
+
+func (s *NexemaSnapshot) FindPackage(path string) *NexemaPackage {
+	for _, pkg := range s.Packages {
+		if pkg.Path == path {
+			return &pkg
+		}
+	}
+
+	return nil
+}
@@ -1,11 +1,17 @@
 package definition
 
 type FieldDefinition struct {
-	Name          string        `json:"name"`
-	Index         int           `json:"index"`
-	Type          BaseValueType `json:"type"`
-	Documentation []string      `json:"documentation"`
-	Annotations   Assignments   `json:"annotations"`
+	Name          string              `json:"name"`
+	Index         int                 `json:"index"`
+	Type          BaseValueType       `json:"type"`
+	Documentation []string            `json:"documentation"`
+	Links         []DocumentationLink `json:"links,omitempty"`
+	Annotations   Assignments         `json:"annotations"`
+	AliasOf       string              `json:"aliasOf,omitempty"`  // The name of the enum member this one is an alias of
+	Combines      []string            `json:"combines,omitempty"` // The names of the flags members this one is a combination of
+	Value         uint64              `json:"value,omitempty"`    // The bitmask of a flags member
+	Oneof         string              `json:"oneof,omitempty"`    // The name of the oneof group the field belongs to
+	Tag           string              `json:"tag,omitempty"`      // The value that identifies a union member
 }
 
 type BaseValueTypeKind string
@@ -23,6 +29,8 @@ type PrimitiveValueType struct {
 	Primitive ValuePrimitive  `json:"primitive"`
 	Nullable  bool            `json:"nullable"`
 	Arguments []BaseValueType `json:"arguments"`
+	Length    int             `json:"length,omitempty"`    // The number of elements of an array
+	MaxLength int             `json:"maxLength,omitempty"` // The max number of elements of a list, or characters of a string, if bounded
 }
 
 type CustomValueType struct {
@@ -45,6 +53,15 @@ func (self PrimitiveValueType) MarshalJSON() ([]byte, error) {
 		"nullable":  self.Nullable,
 		"arguments": self.Arguments,
 	}
+
+	if self.Length > 0 {
+		m["length"] = self.Length
+	}
+
+	if self.MaxLength > 0 {
+		m["maxLength"] = self.MaxLength
+	}
+
 	return json.Marshal(m)
 }
 
@@ -1,5 +1,7 @@
 package definition
 
+import "sort"
+
 type ValuePrimitive string
 
 const (
@@ -20,6 +22,7 @@ const (
 	Float64   ValuePrimitive = "float64"
 	Map       ValuePrimitive = "map"
 	List      ValuePrimitive = "list"
+	Array     ValuePrimitive = "array"
 	Custom    ValuePrimitive = "custom" // User defined types
 	// Timestamp ValueType = "timestamp" moved to "builtin" package
 	// Duration  ValueType = "duration"
@@ -42,9 +45,21 @@ var mapping map[string]ValuePrimitive = map[string]ValuePrimitive{
 	"float64": Float64,
 	"map":     Map,
 	"list":    List,
+	"array":   Array,
 }
 
 func ParsePrimitive(name string) (ValuePrimitive, bool) {
 	value, ok := mapping[name]
 	return value, ok
 }
+
+// PrimitiveNames returns the name of every primitive that can be written in a field's type, sorted
+func PrimitiveNames() []string {
+	names := make([]string, 0, len(mapping))
+	for name := range mapping {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
@@ -4,12 +4,33 @@ import "tomasweigenast.com/nexema/tool/token"
 
 // TypeDefinition represents a Nexema's type
 type TypeDefinition struct {
-	Id            string             `json:"id"`
-	Name          string             `json:"name"`
-	Documentation []string           `json:"documentation"`
-	Annotations   Assignments        `json:"annotations"`
-	Modifier      token.TokenKind    `json:"modifier"`
-	BaseType      *string            `json:"baseType"`
-	Fields        []*FieldDefinition `json:"fields"`
-	Defaults      Assignments        `json:"defaults"`
+	Id            string              `json:"id"`
+	Name          string              `json:"name"`
+	Documentation []string            `json:"documentation"`
+	Links         []DocumentationLink `json:"links,omitempty"`
+	Annotations   Assignments         `json:"annotations"`
+	Modifier      token.TokenKind     `json:"modifier"`
+	BaseType      *string             `json:"baseType"`
+	Fields        []*FieldDefinition  `json:"fields"`
+	Oneofs        []*OneofDefinition  `json:"oneofs,omitempty"` // The oneof groups declared in a struct
+	Defaults      Assignments         `json:"defaults"`
+	DefaultMember string              `json:"defaultMember,omitempty"` // The name of the default member, only for enums and unions
+	Discriminator string              `json:"discriminator,omitempty"` // The name of the field that holds the tag of the active member, only for unions
+
+	UnderlyingType ValuePrimitive `json:"underlyingType,omitempty"` // The primitive that holds the value, only for flags
+}
+
+// DocumentationLink is a cross-reference written in documentation, resolved to the type or field it points to
+type DocumentationLink struct {
+	Text   string `json:"text"`            // The reference as written, without brackets
+	TypeId string `json:"typeId"`          // The id of the referenced type
+	Field  string `json:"field,omitempty"` // The name of the referenced field, if any
+}
+
+// OneofDefinition is a group of fields of a struct where at most one of them can be set at a time
+type OneofDefinition struct {
+	Name          string      `json:"name"`
+	Documentation []string    `json:"documentation"`
+	Annotations   Assignments `json:"annotations"`
+	Fields        []string    `json:"fields"` // The names of the fields in the group, in declaration order
 }
@@ -0,0 +1,129 @@
+package diagnostic
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"tomasweigenast.com/nexema/tool/tokenizer"
+)
+
+// Severity indicates if a Diagnostic stops the build or not
+type Severity int8
+
+const (
+	Error Severity = iota
+	Warning
+)
+
+func (self Severity) String() string {
+	switch self {
+	case Error:
+		return "error"
+	case Warning:
+		return "warning"
+	}
+
+	return fmt.Sprintf("Severity(%d)", self)
+}
+
+// Diagnostic is an error or a warning reported by any stage of the build, located in a source file
+type Diagnostic struct {
+	File     string        // The path of the file, relative to the root of the project. Empty if it is unknown
+	At       tokenizer.Pos // The span of the source the diagnostic points to
+	Severity Severity
+	Code     string // Identifies the kind of diagnostic, like NX0101 for parser, NX0201 for linker or NX0301 for analyzer ones
+	Message  string
+	Notes    []Note // Related locations, like the previous definition of a name that is defined twice
+	Edits    []Edit // Changes to File that fix the diagnostic, if it can be fixed automatically
+}
+
+// Note is a location related to a Diagnostic
+type Note struct {
+	File    string
+	At      tokenizer.Pos
+	Message string
+}
+
+// New creates a new Diagnostic
+func New(severity Severity, code, message, file string, at tokenizer.Pos) *Diagnostic {
+	return &Diagnostic{
+		File:     file,
+		At:       at,
+		Severity: severity,
+		Code:     code,
+		Message:  message,
+	}
+}
+
+// Location returns where the diagnostic points to, in the form file:line:column. Unknown parts are omitted
+func (self *Diagnostic) Location() string {
+	return location(self.File, self.At)
+}
+
+// Location returns where the note points to, in the same form as Diagnostic.Location
+func (self *Note) Location() string {
+	return location(self.File, self.At)
+}
+
+func location(file string, at tokenizer.Pos) string {
+	var parts []string
+	if len(file) > 0 {
+		parts = append(parts, file)
+	}
+
+	if at.Line > 0 {
+		parts = append(parts, fmt.Sprint(at.Line), fmt.Sprint(at.Column))
+	}
+
+	return strings.Join(parts, ":")
+}
+
+// String returns the diagnostic in the form file:line:column: severity[code]: message
+func (self *Diagnostic) String() string {
+	out := fmt.Sprintf("%s[%s]: %s", self.Severity, self.Code, self.Message)
+	if location := self.Location(); len(location) > 0 {
+		out = location + ": " + out
+	}
+
+	return out
+}
+
+// Diagnostics is a list of diagnostics. It implements error, so a failed build can return every diagnostic it found
+type Diagnostics []*Diagnostic
+
+// HasErrors returns true if any of the diagnostics is an error
+func (self Diagnostics) HasErrors() bool {
+	for _, diagnostic := range self {
+		if diagnostic.Severity == Error {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Sort sorts the diagnostics by file and then by position
+func (self Diagnostics) Sort() {
+	sort.SliceStable(self, func(i, j int) bool {
+		a, b := self[i], self[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+
+		return a.At.Start < b.At.Start
+	})
+}
+
+func (self Diagnostics) String() string {
+	out := make([]string, len(self))
+	for i, diagnostic := range self {
+		out[i] = diagnostic.String()
+	}
+
+	return strings.Join(out, "\n")
+}
+
+func (self Diagnostics) Error() string {
+	return self.String()
+}
@@ -0,0 +1,60 @@
+package diagnostic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"tomasweigenast.com/nexema/tool/tokenizer"
+)
+
+func TestDiagnostic_String(t *testing.T) {
+	tests := []struct {
+		name  string
+		input *Diagnostic
+		want  string
+	}{
+		{
+			name:  "file and position",
+			input: New(Error, "NX0101", "unexpected end of file", "foo/sample.nex", tokenizer.Pos{Start: 20, End: 21, Line: 3, Endline: 3, Column: 5, EndColumn: 6}),
+			want:  "foo/sample.nex:3:5: error[NX0101]: unexpected end of file",
+		},
+		{
+			name:  "without position",
+			input: New(Error, "NX0204", "circular dependency", "foo/sample.nex", *tokenizer.NewPos()),
+			want:  "foo/sample.nex: error[NX0204]: circular dependency",
+		},
+		{
+			name:  "without file",
+			input: New(Warning, "NX0206", "unknown reference", "", *tokenizer.NewPos(2, 4)),
+			want:  "1:3: warning[NX0206]: unknown reference",
+		},
+		{
+			name:  "without file and position",
+			input: New(Error, "NX0301", "unknown modifier", "", *tokenizer.NewPos()),
+			want:  "error[NX0301]: unknown modifier",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.input.String())
+		})
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	diagnostics := Diagnostics{
+		New(Warning, "NX0206", "second", "b.nex", *tokenizer.NewPos(5, 6)),
+		New(Error, "NX0101", "third", "b.nex", *tokenizer.NewPos(10, 11)),
+		New(Error, "NX0102", "first", "a.nex", *tokenizer.NewPos(30, 31)),
+	}
+
+	require.True(t, diagnostics.HasErrors())
+	require.False(t, diagnostics[:1].HasErrors())
+
+	diagnostics.Sort()
+	require.Equal(t, "a.nex:1:31: error[NX0102]: first\nb.nex:1:6: warning[NX0206]: second\nb.nex:1:11: error[NX0101]: third", diagnostics.Error())
+
+	var err error = diagnostics
+	require.EqualError(t, err, diagnostics.String())
+}
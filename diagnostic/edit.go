@@ -0,0 +1,77 @@
+package diagnostic
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+// Edit replaces a span of a source file with Text
+type Edit struct {
+	Start int // the byte offset where the replaced span starts
+	End   int // the byte offset where the replaced span ends, exclusive
+	Text  string
+}
+
+// ApplyEdits returns source with edits applied. Edits can be given in any order, but they cannot overlap
+func ApplyEdits(source []byte, edits []Edit) ([]byte, error) {
+	sorted := make([]Edit, len(edits))
+	copy(sorted, edits)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Start < sorted[j].Start
+	})
+
+	var out bytes.Buffer
+	offset := 0
+	for _, edit := range sorted {
+		if edit.Start < offset || edit.End < edit.Start || edit.End > len(source) {
+			return nil, fmt.Errorf("edit of bytes %d to %d overlaps another edit or is out of the source", edit.Start, edit.End)
+		}
+
+		out.Write(source[offset:edit.Start])
+		out.WriteString(edit.Text)
+		offset = edit.End
+	}
+
+	out.Write(source[offset:])
+	return out.Bytes(), nil
+}
+
+// DeleteLine returns an Edit that deletes the span of source between the start and end offsets. If nothing else
+// but blanks and a trailing comment is written in the lines of the span, the whole lines are deleted
+func DeleteLine(source []byte, start, end int) Edit {
+	lineStart := start
+	for lineStart > 0 && isBlank(source[lineStart-1]) {
+		lineStart--
+	}
+
+	lineEnd := end
+	for lineEnd < len(source) && isBlank(source[lineEnd]) {
+		lineEnd++
+	}
+
+	if bytes.HasPrefix(source[lineEnd:], []byte("//")) {
+		for lineEnd < len(source) && source[lineEnd] != '\n' {
+			lineEnd++
+		}
+	}
+
+	startsLine := lineStart == 0 || source[lineStart-1] == '\n'
+	endsLine := lineEnd == len(source) || source[lineEnd] == '\n' || source[lineEnd] == '\r'
+	if !startsLine || !endsLine {
+		return Edit{Start: start, End: end}
+	}
+
+	// remove the line break too
+	if bytes.HasPrefix(source[lineEnd:], []byte("\r\n")) {
+		lineEnd += 2
+	} else if lineEnd < len(source) {
+		lineEnd++
+	}
+
+	return Edit{Start: lineStart, End: lineEnd}
+}
+
+func isBlank(b byte) bool {
+	return b == ' ' || b == '\t'
+}
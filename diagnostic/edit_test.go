@@ -0,0 +1,116 @@
+package diagnostic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyEdits(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		edits   []Edit
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "no edits",
+			source: "use \"foo\"\n",
+			want:   "use \"foo\"\n",
+		},
+		{
+			name:   "unordered edits",
+			source: "abcdef",
+			edits:  []Edit{{Start: 4, End: 5, Text: "E"}, {Start: 0, End: 1, Text: "A"}, {Start: 2, End: 2, Text: "-"}},
+			want:   "Ab-cdEf",
+		},
+		{
+			name:    "overlapping edits",
+			source:  "abcdef",
+			edits:   []Edit{{Start: 0, End: 3}, {Start: 2, End: 4}},
+			wantErr: true,
+		},
+		{
+			name:    "out of range",
+			source:  "abc",
+			edits:   []Edit{{Start: 2, End: 5}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyEdits([]byte(tt.source), tt.edits)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestDeleteLine(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		span   string // the text deleted
+		want   string
+	}{
+		{
+			name:   "whole line",
+			source: "use \"foo\"\nuse \"bar\"\n",
+			span:   "use \"foo\"",
+			want:   "use \"bar\"\n",
+		},
+		{
+			name:   "indented line with trailing comment",
+			source: "use \"foo\"\n  use \"bar\" // unused\ntype A struct {}\n",
+			span:   "use \"bar\"",
+			want:   "use \"foo\"\ntype A struct {}\n",
+		},
+		{
+			name:   "crlf line break",
+			source: "use \"foo\"\r\nuse \"bar\"\r\n",
+			span:   "use \"foo\"",
+			want:   "use \"bar\"\r\n",
+		},
+		{
+			name:   "last line without line break",
+			source: "use \"foo\"\nuse \"bar\"",
+			span:   "use \"bar\"",
+			want:   "use \"foo\"\n",
+		},
+		{
+			name:   "line shared with other statements",
+			source: "use \"foo\" use \"bar\"\n",
+			span:   "use \"foo\"",
+			want:   " use \"bar\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := indexOf(t, tt.source, tt.span)
+			edit := DeleteLine([]byte(tt.source), start, start+len(tt.span))
+
+			got, err := ApplyEdits([]byte(tt.source), []Edit{edit})
+			require.NoError(t, err)
+			require.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func indexOf(t *testing.T, source, span string) int {
+	for i := 0; i+len(span) <= len(source); i++ {
+		if source[i:i+len(span)] == span {
+			return i
+		}
+	}
+
+	t.Fatalf("%q not found in %q", span, source)
+	return -1
+}
@@ -0,0 +1,127 @@
+package diagnostic
+
+import (
+	"embed"
+	"path"
+	"sort"
+	"strings"
+)
+
+// explanations contains a markdown file for each diagnostic code, named after it, like NX0101.md.
+//
+// Every file starts with a "# CODE: Title" line, followed by the explanation and, at least, one erroneous and one
+// correct example, written in code blocks tagged "nex bad" and "nex good" respectively. An erroneous example that
+// is caught by an earlier stage under another code is tagged "nex bad OTHERCODE". If an example needs more than
+// one file, each of them starts with a "// file: path/to/file.nex" line. Otherwise, it is written to
+// defaultExampleFile.
+//
+//go:embed explanations/*.md
+var explanations embed.FS
+
+const (
+	defaultExampleFile = "foo/example.nex"
+	exampleFilePrefix  = "// file: "
+)
+
+// Explanation is the long-form documentation of a diagnostic code
+type Explanation struct {
+	Code  string
+	Title string
+	Text  string // markdown, including the title
+}
+
+// Example is a .nex snippet written in an Explanation
+type Example struct {
+	Valid bool              // false if the example shows the mistake the explanation is about
+	Code  string            // the code an erroneous example reports, usually the one of the explanation
+	Files map[string]string // the contents of each file, by its path relative to the root of the project
+}
+
+// Explain returns the explanation of the given code, or nil if there is no one. Codes are case insensitive
+func Explain(code string) *Explanation {
+	code = strings.ToUpper(code)
+	contents, err := explanations.ReadFile(path.Join("explanations", code+".md"))
+	if err != nil {
+		return nil
+	}
+
+	text := string(contents)
+	title := strings.SplitN(text, "\n", 2)[0]
+	title = strings.TrimPrefix(title, "# "+code+":")
+
+	return &Explanation{
+		Code:  code,
+		Title: strings.TrimSpace(title),
+		Text:  text,
+	}
+}
+
+// Explanations returns the explanation of every code, sorted by code
+func Explanations() []*Explanation {
+	entries, _ := explanations.ReadDir("explanations")
+
+	out := make([]*Explanation, 0, len(entries))
+	for _, entry := range entries {
+		out = append(out, Explain(strings.TrimSuffix(entry.Name(), ".md")))
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Code < out[j].Code
+	})
+
+	return out
+}
+
+// Examples returns the examples written in the explanation, in order
+func (self *Explanation) Examples() []Example {
+	var out []Example
+	var current *Example
+	var file string
+	var lines []string
+
+	for _, line := range strings.Split(self.Text, "\n") {
+		if current == nil {
+			tags := strings.Fields(line)
+			if len(tags) < 2 || tags[0] != "```nex" {
+				continue
+			}
+
+			switch {
+			case tags[1] == "good" && len(tags) == 2:
+				current = &Example{Valid: true, Files: map[string]string{}}
+			case tags[1] == "bad" && len(tags) == 2:
+				current = &Example{Valid: false, Code: self.Code, Files: map[string]string{}}
+			case tags[1] == "bad" && len(tags) == 3:
+				current = &Example{Valid: false, Code: tags[2], Files: map[string]string{}}
+			default:
+				continue
+			}
+
+			file = defaultExampleFile
+			lines = nil
+			continue
+		}
+
+		if strings.HasPrefix(line, exampleFilePrefix) {
+			// lines before the first file marker, if any, belong to the default file
+			if text := strings.Join(lines, "\n"); len(strings.TrimSpace(text)) > 0 {
+				current.Files[file] = text + "\n"
+			}
+
+			file = strings.TrimSpace(strings.TrimPrefix(line, exampleFilePrefix))
+			lines = nil
+			continue
+		}
+
+		if strings.TrimSpace(line) == "```" {
+			current.Files[file] = strings.Join(lines, "\n") + "\n"
+			out = append(out, *current)
+			current = nil
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	return out
+}
@@ -0,0 +1,60 @@
+package diagnostic
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplain(t *testing.T) {
+	explanation := Explain("nx0102")
+	require.NotNil(t, explanation)
+	require.Equal(t, "NX0102", explanation.Code)
+	require.Equal(t, "Unexpected token", explanation.Title)
+	require.Contains(t, explanation.Text, "# NX0102: Unexpected token\n")
+
+	require.Nil(t, Explain("NX9999"))
+	require.Nil(t, Explain("../diagnostic"))
+}
+
+func TestExplanations(t *testing.T) {
+	explanations := Explanations()
+	require.NotEmpty(t, explanations)
+	require.True(t, sort.SliceIsSorted(explanations, func(i, j int) bool {
+		return explanations[i].Code < explanations[j].Code
+	}))
+
+	for _, explanation := range explanations {
+		require.NotEmpty(t, explanation.Title, "%s does not have a title", explanation.Code)
+	}
+}
+
+func TestExplanation_Examples(t *testing.T) {
+	explanation := &Explanation{
+		Code: "NX0205",
+		Text: "# NX0205: Alias already defined\n\n" +
+			"```nex bad\n" +
+			"type A struct {}\n" +
+			"```\n\n" +
+			"```nex bad NX0107\n" +
+			"type A strct {}\n" +
+			"```\n\n" +
+			"```nex good\n" +
+			"// file: foo/a.nex\n" +
+			"use \"bar\"\n" +
+			"\n" +
+			"// file: bar/b.nex\n" +
+			"type B struct {}\n" +
+			"```\n\n" +
+			"```yaml\n" +
+			"version: 1\n" +
+			"```\n",
+	}
+
+	require.Equal(t, []Example{
+		{Valid: false, Code: "NX0205", Files: map[string]string{"foo/example.nex": "type A struct {}\n"}},
+		{Valid: false, Code: "NX0107", Files: map[string]string{"foo/example.nex": "type A strct {}\n"}},
+		{Valid: true, Files: map[string]string{"foo/a.nex": "use \"bar\"\n\n", "bar/b.nex": "type B struct {}\n"}},
+	}, explanation.Examples())
+}
@@ -0,0 +1,57 @@
+package diagnostic
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Format is the way diagnostics are written to the output
+type Format string
+
+const (
+	FormatText  Format = "text"  // human readable, see Renderer
+	FormatJSON  Format = "json"  // a JSON document, see WriteJSON
+	FormatSARIF Format = "sarif" // a SARIF 2.1.0 log, see WriteSARIF
+)
+
+// Formats contains every supported Format
+var Formats = []Format{FormatText, FormatJSON, FormatSARIF}
+
+// ParseFormat returns the Format named s
+func ParseFormat(s string) (Format, error) {
+	for _, format := range Formats {
+		if string(format) == s {
+			return format, nil
+		}
+	}
+
+	names := make([]string, len(Formats))
+	for i, format := range Formats {
+		names[i] = string(format)
+	}
+
+	return "", fmt.Errorf("unknown diagnostics format %q, expected one of: %s", s, strings.Join(names, ", "))
+}
+
+// IsMachineReadable returns true if the format is meant to be read by other tools. Those formats are always written,
+// even if there are no diagnostics, so tools can tell a successful build from a crash.
+func (self Format) IsMachineReadable() bool {
+	return self == FormatJSON || self == FormatSARIF
+}
+
+// Write writes diagnostics to w in the given format. sources and color are only used by FormatText, and root, the
+// folder the files of the diagnostics are relative to, by FormatSARIF.
+func Write(w io.Writer, format Format, diagnostics Diagnostics, sources Sources, root string, color bool) error {
+	switch format {
+	case FormatJSON:
+		return WriteJSON(w, diagnostics)
+
+	case FormatSARIF:
+		return WriteSARIF(w, diagnostics, root)
+
+	default:
+		renderer := &Renderer{Sources: sources, Color: color}
+		return renderer.Render(w, diagnostics)
+	}
+}
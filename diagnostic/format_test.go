@@ -0,0 +1,174 @@
+package diagnostic
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"tomasweigenast.com/nexema/tool/tokenizer"
+)
+
+func TestParseFormat(t *testing.T) {
+	for _, format := range Formats {
+		got, err := ParseFormat(string(format))
+		require.NoError(t, err)
+		require.Equal(t, format, got)
+	}
+
+	_, err := ParseFormat("xml")
+	require.EqualError(t, err, `unknown diagnostics format "xml", expected one of: text, json, sarif`)
+}
+
+func formatTestDiagnostics() Diagnostics {
+	return Diagnostics{
+		{
+			File:     "foo/user.nex",
+			At:       tokenizer.Pos{Start: 37, End: 39, Line: 3, Endline: 3, Column: 4, EndColumn: 6, Column16: 3, EndColumn16: 5},
+			Severity: Error,
+			Code:     "NX0305",
+			Message:  `"id" is already defined`,
+			Notes:    []Note{{File: "foo/user.nex", At: tokenizer.Pos{Start: 22, End: 24, Line: 2, Endline: 2, Column: 4, EndColumn: 6, Column16: 4, EndColumn16: 6}, Message: "previously defined here"}},
+		},
+		New(Warning, "NX0206", "unknown reference", "", *tokenizer.NewPos()),
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	require.NoError(t, WriteJSON(buf, formatTestDiagnostics()))
+	require.JSONEq(t, `{
+		"diagnostics": [
+			{
+				"file": "foo/user.nex",
+				"range": {
+					"start": {"offset": 37, "line": 3, "column": 4, "column16": 3},
+					"end": {"offset": 39, "line": 3, "column": 6, "column16": 5}
+				},
+				"severity": "error",
+				"code": "NX0305",
+				"message": "\"id\" is already defined",
+				"notes": [
+					{
+						"file": "foo/user.nex",
+						"range": {
+							"start": {"offset": 22, "line": 2, "column": 4, "column16": 4},
+							"end": {"offset": 24, "line": 2, "column": 6, "column16": 6}
+						},
+						"message": "previously defined here"
+					}
+				]
+			},
+			{
+				"file": "",
+				"range": null,
+				"severity": "warning",
+				"code": "NX0206",
+				"message": "unknown reference",
+				"notes": []
+			}
+		]
+	}`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, WriteJSON(buf, nil))
+	require.JSONEq(t, `{"diagnostics": []}`, buf.String())
+}
+
+func TestWriteSARIF(t *testing.T) {
+	buf := new(bytes.Buffer)
+	require.NoError(t, WriteSARIF(buf, formatTestDiagnostics(), "/home/me/project"))
+	require.JSONEq(t, `{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": [
+			{
+				"tool": {
+					"driver": {
+						"name": "nexema",
+						"informationUri": "https://github.com/nexema/nexema",
+						"rules": [{"id": "NX0206"}, {"id": "NX0305"}]
+					}
+				},
+				"originalUriBaseIds": {
+					"%SRCROOT%": {"uri": "file:///home/me/project/"}
+				},
+				"results": [
+					{
+						"ruleId": "NX0305",
+						"level": "error",
+						"message": {"text": "\"id\" is already defined"},
+						"locations": [
+							{
+								"physicalLocation": {
+									"artifactLocation": {"uri": "foo/user.nex", "uriBaseId": "%SRCROOT%"},
+									"region": {"startLine": 3, "startColumn": 3, "endLine": 3, "endColumn": 5}
+								}
+							}
+						],
+						"relatedLocations": [
+							{
+								"id": 0,
+								"physicalLocation": {
+									"artifactLocation": {"uri": "foo/user.nex", "uriBaseId": "%SRCROOT%"},
+									"region": {"startLine": 2, "startColumn": 4, "endLine": 2, "endColumn": 6}
+								},
+								"message": {"text": "previously defined here"}
+							}
+						]
+					},
+					{
+						"ruleId": "NX0206",
+						"level": "warning",
+						"message": {"text": "unknown reference"}
+					}
+				]
+			}
+		]
+	}`, buf.String())
+
+	// without the folder of the project, code scanning services resolve %SRCROOT% themselves
+	buf.Reset()
+	require.NoError(t, WriteSARIF(buf, formatTestDiagnostics()[:1], ""))
+	require.NotContains(t, buf.String(), "originalUriBaseIds")
+	require.Contains(t, buf.String(), `"uriBaseId": "%SRCROOT%"`)
+}
+
+// The output is read by people in CI logs, so its layout is checked byte by byte
+func TestWriteSARIF_Indentation(t *testing.T) {
+	buf := new(bytes.Buffer)
+	require.NoError(t, WriteSARIF(buf, formatTestDiagnostics()[1:], "/home/me/project"))
+	require.Equal(t, `{
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "version": "2.1.0",
+  "runs": [
+    {
+      "tool": {
+        "driver": {
+          "name": "nexema",
+          "informationUri": "https://github.com/nexema/nexema",
+          "rules": [
+            {
+              "id": "NX0206"
+            }
+          ]
+        }
+      },
+      "originalUriBaseIds": {
+        "%SRCROOT%": {
+          "uri": "file:///home/me/project/"
+        }
+      },
+      "results": [
+        {
+          "ruleId": "NX0206",
+          "level": "warning",
+          "message": {
+            "text": "unknown reference"
+          }
+        }
+      ]
+    }
+  ]
+}
+`, buf.String())
+}
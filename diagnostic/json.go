@@ -0,0 +1,93 @@
+package diagnostic
+
+import (
+	"encoding/json"
+	"io"
+
+	"tomasweigenast.com/nexema/tool/tokenizer"
+)
+
+type jsonReport struct {
+	Diagnostics []jsonDiagnostic `json:"diagnostics"`
+}
+
+type jsonDiagnostic struct {
+	File     string     `json:"file"`
+	Range    *jsonRange `json:"range"`
+	Severity string     `json:"severity"`
+	Code     string     `json:"code"`
+	Message  string     `json:"message"`
+	Notes    []jsonNote `json:"notes"`
+}
+
+type jsonNote struct {
+	File    string     `json:"file"`
+	Range   *jsonRange `json:"range"`
+	Message string     `json:"message"`
+}
+
+// jsonRange is a span of source. The end is exclusive
+type jsonRange struct {
+	Start jsonPosition `json:"start"`
+	End   jsonPosition `json:"end"`
+}
+
+type jsonPosition struct {
+	Offset   int `json:"offset"`   // byte offset from the start of the file, starting from 0
+	Line     int `json:"line"`     // starting from 1
+	Column   int `json:"column"`   // in bytes, starting from 1
+	Column16 int `json:"column16"` // in UTF-16 code units, starting from 1
+}
+
+// WriteJSON writes diagnostics to w as a JSON document in the form:
+//
+//	{
+//	  "diagnostics": [
+//	    {
+//	      "file": "foo/user.nex",
+//	      "range": {
+//	        "start": {"offset": 35, "line": 3, "column": 4, "column16": 4},
+//	        "end": {"offset": 37, "line": 3, "column": 6, "column16": 6}
+//	      },
+//	      "severity": "error",
+//	      "code": "NX0305",
+//	      "message": "\"id\" is already defined",
+//	      "notes": [{"file": "foo/user.nex", "range": {...}, "message": "previously defined here"}]
+//	    }
+//	  ]
+//	}
+//
+// range is null if the position is unknown.
+func WriteJSON(w io.Writer, diagnostics Diagnostics) error {
+	report := jsonReport{Diagnostics: make([]jsonDiagnostic, len(diagnostics))}
+	for i, diagnostic := range diagnostics {
+		notes := make([]jsonNote, len(diagnostic.Notes))
+		for j, note := range diagnostic.Notes {
+			notes[j] = jsonNote{File: note.File, Range: newJSONRange(note.At), Message: note.Message}
+		}
+
+		report.Diagnostics[i] = jsonDiagnostic{
+			File:     diagnostic.File,
+			Range:    newJSONRange(diagnostic.At),
+			Severity: diagnostic.Severity.String(),
+			Code:     diagnostic.Code,
+			Message:  diagnostic.Message,
+			Notes:    notes,
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+func newJSONRange(at tokenizer.Pos) *jsonRange {
+	if at.Line == 0 {
+		return nil
+	}
+
+	return &jsonRange{
+		Start: jsonPosition{Offset: at.Start, Line: at.Line, Column: at.Column, Column16: at.Column16},
+		End:   jsonPosition{Offset: at.End, Line: at.Endline, Column: at.EndColumn, Column16: at.EndColumn16},
+	}
+}
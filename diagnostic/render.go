@@ -0,0 +1,219 @@
+package diagnostic
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"tomasweigenast.com/nexema/tool/tokenizer"
+)
+
+// Sources maps the path of a file, as it is written in Diagnostic.File, to its contents
+type Sources map[string][]byte
+
+// tabWidth is the number of spaces a tab is expanded to when printing a source line
+const tabWidth = 4
+
+const (
+	colorReset  = "\x1b[0m"
+	colorBold   = "\x1b[1m"
+	colorRed    = "\x1b[1;31m"
+	colorYellow = "\x1b[1;33m"
+	colorBlue   = "\x1b[1;34m"
+	colorCyan   = "\x1b[1;36m"
+)
+
+// Renderer writes diagnostics like compilers do: the location, the line of source the diagnostic points to, with its
+// span underlined, and the related notes.
+//
+// For example:
+//
+//	error[NX0305]: "id" is already defined
+//	 --> foo/user.nex:3:4
+//	  |
+//	3 |     1 id string
+//	  |       ^^
+//	note: previously defined here
+//	 --> foo/user.nex:2:4
+//	  |
+//	2 |     0 id string
+//	  |       --
+type Renderer struct {
+	Sources Sources // The contents of the files, used to print source lines. Lines of unknown files are omitted
+	Color   bool    // If true, output contains ANSI color codes
+}
+
+// Render writes every diagnostic to w, separated by a blank line
+func (self *Renderer) Render(w io.Writer, diagnostics Diagnostics) error {
+	buf := new(bytes.Buffer)
+	for i, diagnostic := range diagnostics {
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+
+		self.renderDiagnostic(buf, diagnostic)
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (self *Renderer) renderDiagnostic(buf *bytes.Buffer, diagnostic *Diagnostic) {
+	color := colorRed
+	if diagnostic.Severity == Warning {
+		color = colorYellow
+	}
+
+	// gutter is wide enough for every line number printed
+	gutter := len(fmt.Sprint(diagnostic.At.Line))
+	for _, note := range diagnostic.Notes {
+		if digits := len(fmt.Sprint(note.At.Line)); digits > gutter {
+			gutter = digits
+		}
+	}
+
+	buf.WriteString(self.paint(color, fmt.Sprintf("%s[%s]", diagnostic.Severity, diagnostic.Code)))
+	buf.WriteString(self.paint(colorBold, ": "+diagnostic.Message))
+	buf.WriteByte('\n')
+	self.renderSpan(buf, diagnostic.File, diagnostic.At, '^', color, gutter)
+
+	for _, note := range diagnostic.Notes {
+		buf.WriteString(self.paint(colorCyan, "note"))
+		buf.WriteString(": " + note.Message + "\n")
+		self.renderSpan(buf, note.File, note.At, '-', colorCyan, gutter)
+	}
+}
+
+// renderSpan writes the location of at and, if the source of file is known, the line where at starts
+// with its span underlined using mark
+func (self *Renderer) renderSpan(buf *bytes.Buffer, file string, at tokenizer.Pos, mark rune, color string, gutter int) {
+	location := location(file, at)
+	if len(location) == 0 {
+		return
+	}
+
+	padding := strings.Repeat(" ", gutter)
+	buf.WriteString(padding + self.paint(colorBlue, "-->") + " " + location + "\n")
+
+	line, ok := self.sourceLine(file, at.Line)
+	if !ok {
+		return
+	}
+
+	start := clamp(at.Column-1, 0, len(line))
+	end := len(line)
+	if at.Endline == at.Line {
+		end = clamp(at.EndColumn-1, start, len(line))
+	}
+
+	prefix := expandTabs(line[:start], 0)
+	span := expandTabs(line[start:end], width(prefix))
+	underline := width(span)
+	if underline == 0 {
+		underline = 1
+	}
+
+	bar := self.paint(colorBlue, "|")
+	buf.WriteString(padding + " " + bar + "\n")
+	buf.WriteString(self.paint(colorBlue, fmt.Sprintf("%*d", gutter, at.Line)) + " " + bar + " " + expandTabs(line, 0) + "\n")
+	buf.WriteString(padding + " " + bar + " " + strings.Repeat(" ", width(prefix)) + self.paint(color, strings.Repeat(string(mark), underline)) + "\n")
+}
+
+// sourceLine returns the line of file with the given number, starting from 1
+func (self *Renderer) sourceLine(file string, number int) (string, bool) {
+	source, ok := self.Sources[file]
+	if !ok || number < 1 {
+		return "", false
+	}
+
+	lines := strings.Split(string(source), "\n")
+	if number > len(lines) {
+		return "", false
+	}
+
+	return strings.TrimRight(lines[number-1], "\r"), true
+}
+
+func (self *Renderer) paint(color, text string) string {
+	if !self.Color {
+		return text
+	}
+
+	return color + text + colorReset
+}
+
+// expandTabs replaces the tabs of text with spaces up to the next tab stop, where column is the
+// width of what was printed before text
+func expandTabs(text string, column int) string {
+	out := new(strings.Builder)
+	for _, ch := range text {
+		if ch == '\t' {
+			spaces := tabWidth - (column % tabWidth)
+			out.WriteString(strings.Repeat(" ", spaces))
+			column += spaces
+		} else {
+			out.WriteRune(ch)
+			column += runeWidth(ch)
+		}
+	}
+
+	return out.String()
+}
+
+// width returns the number of columns text takes in a terminal
+func width(text string) int {
+	total := 0
+	for _, ch := range text {
+		total += runeWidth(ch)
+	}
+
+	return total
+}
+
+// wideRanges contains the East Asian wide and fullwidth characters, and emojis, which take two columns in a terminal
+var wideRanges = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x1100, Hi: 0x115f, Stride: 1},
+		{Lo: 0x2e80, Hi: 0x303e, Stride: 1},
+		{Lo: 0x3041, Hi: 0x33ff, Stride: 1},
+		{Lo: 0x3400, Hi: 0x4dbf, Stride: 1},
+		{Lo: 0x4e00, Hi: 0x9fff, Stride: 1},
+		{Lo: 0xa000, Hi: 0xa4cf, Stride: 1},
+		{Lo: 0xac00, Hi: 0xd7a3, Stride: 1},
+		{Lo: 0xf900, Hi: 0xfaff, Stride: 1},
+		{Lo: 0xfe30, Hi: 0xfe4f, Stride: 1},
+		{Lo: 0xff00, Hi: 0xff60, Stride: 1},
+		{Lo: 0xffe0, Hi: 0xffe6, Stride: 1},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1f300, Hi: 0x1f64f, Stride: 1},
+		{Lo: 0x1f900, Hi: 0x1f9ff, Stride: 1},
+		{Lo: 0x20000, Hi: 0x3fffd, Stride: 1},
+	},
+}
+
+// runeWidth returns the number of columns ch takes in a terminal
+func runeWidth(ch rune) int {
+	switch {
+	case unicode.In(ch, unicode.Mn, unicode.Me):
+		return 0
+	case unicode.Is(wideRanges, ch):
+		return 2
+	default:
+		return 1
+	}
+}
+
+func clamp(value, min, max int) int {
+	if value < min {
+		return min
+	}
+
+	if value > max {
+		return max
+	}
+
+	return value
+}
@@ -0,0 +1,115 @@
+package diagnostic
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"tomasweigenast.com/nexema/tool/tokenizer"
+)
+
+func TestRenderer_Render(t *testing.T) {
+	sources := Sources{
+		"foo/user.nex":  []byte("type User struct {\n\t0 id string\n\t1 id string\n}\n"),
+		"foo/names.nex": []byte("type 名前 struct {\r\n  0 x 名前?\r\n  1 y string = `multi\r\nline`\r\n}"),
+	}
+
+	tests := []struct {
+		name  string
+		input Diagnostics
+		want  []string
+	}{
+		{
+			name: "with note",
+			input: Diagnostics{
+				{
+					File:     "foo/user.nex",
+					At:       tokenizer.Pos{Start: 35, End: 37, Line: 3, Endline: 3, Column: 4, EndColumn: 6},
+					Severity: Error,
+					Code:     "NX0305",
+					Message:  `"id" is already defined`,
+					Notes:    []Note{{File: "foo/user.nex", At: tokenizer.Pos{Start: 22, End: 24, Line: 2, Endline: 2, Column: 4, EndColumn: 6}, Message: "previously defined here"}},
+				},
+			},
+			want: []string{
+				`error[NX0305]: "id" is already defined`,
+				" --> foo/user.nex:3:4",
+				"  |",
+				"3 |     1 id string",
+				"  |       ^^",
+				"note: previously defined here",
+				" --> foo/user.nex:2:4",
+				"  |",
+				"2 |     0 id string",
+				"  |       --",
+			},
+		},
+		{
+			name: "wide characters and multi-line spans",
+			input: Diagnostics{
+				{
+					File:     "foo/names.nex",
+					At:       tokenizer.Pos{Line: 2, Endline: 2, Column: 7, EndColumn: 13},
+					Severity: Error,
+					Code:     "NX0303",
+					Message:  "type not found",
+				},
+				{
+					File:     "foo/names.nex",
+					At:       tokenizer.Pos{Line: 3, Endline: 4, Column: 16, EndColumn: 6},
+					Severity: Warning,
+					Code:     "NX0300",
+					Message:  "multi-line",
+				},
+			},
+			want: []string{
+				"error[NX0303]: type not found",
+				" --> foo/names.nex:2:7",
+				"  |",
+				"2 |   0 x 名前?",
+				"  |       ^^^^",
+				"",
+				"warning[NX0300]: multi-line",
+				" --> foo/names.nex:3:16",
+				"  |",
+				"3 |   1 y string = `multi",
+				"  |                ^^^^^^",
+			},
+		},
+		{
+			name: "unknown source and position",
+			input: Diagnostics{
+				New(Error, "NX0204", "circular dependency", "foo/missing.nex", *tokenizer.NewPos(0, 1)),
+				New(Error, "NX0301", "no location", "", *tokenizer.NewPos()),
+			},
+			want: []string{
+				"error[NX0204]: circular dependency",
+				" --> foo/missing.nex:1:1",
+				"",
+				"error[NX0301]: no location",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			renderer := &Renderer{Sources: sources}
+			require.NoError(t, renderer.Render(buf, tt.input))
+			require.Equal(t, strings.Join(tt.want, "\n")+"\n", buf.String())
+		})
+	}
+}
+
+func TestRenderer_Color(t *testing.T) {
+	diagnostics := Diagnostics{New(Warning, "NX0206", "unknown reference", "a.nex", *tokenizer.NewPos(0, 1))}
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, (&Renderer{Color: true}).Render(buf, diagnostics))
+	require.Equal(t, colorYellow+"warning[NX0206]"+colorReset+colorBold+": unknown reference"+colorReset+"\n "+colorBlue+"-->"+colorReset+" a.nex:1:1\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, (&Renderer{Color: false}).Render(buf, diagnostics))
+	require.NotContains(t, buf.String(), "\x1b[")
+}
@@ -0,0 +1,197 @@
+package diagnostic
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"tomasweigenast.com/nexema/tool/tokenizer"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolName     = "nexema"
+	toolURI      = "https://github.com/nexema/nexema"
+
+	// sarifRootID is the base of the uris of the files, which are relative to the folder of the project
+	sarifRootID = "%SRCROOT%"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                        `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	Results            []sarifResult                    `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID           string          `json:"ruleId"`
+	Level            string          `json:"level"`
+	Message          sarifMessage    `json:"message"`
+	Locations        []sarifLocation `json:"locations,omitempty"`
+	RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	ID               *int                  `json:"id,omitempty"`
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage         `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+// sarifRegion uses SARIF's default column kind, UTF-16 code units. The end column is exclusive
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+}
+
+// WriteSARIF writes diagnostics to w as a SARIF 2.1.0 log with a single run, which can be uploaded to code
+// scanning services to annotate pull requests. Notes are written as related locations.
+//
+// The uris of the files are relative to %SRCROOT%, which is root, the folder of the project, if it is not empty.
+// Otherwise, code scanning services resolve it to the folder where the repository is checked out.
+func WriteSARIF(w io.Writer, diagnostics Diagnostics, root string) error {
+	rules := make(map[string]bool)
+	results := make([]sarifResult, len(diagnostics))
+	for i, diagnostic := range diagnostics {
+		rules[diagnostic.Code] = true
+
+		level := "error"
+		if diagnostic.Severity == Warning {
+			level = "warning"
+		}
+
+		result := sarifResult{
+			RuleID:  diagnostic.Code,
+			Level:   level,
+			Message: sarifMessage{diagnostic.Message},
+		}
+
+		if location := newSARIFLocation(diagnostic.File, diagnostic.At); location != nil {
+			result.Locations = []sarifLocation{*location}
+		}
+
+		for j, note := range diagnostic.Notes {
+			location := newSARIFLocation(note.File, note.At)
+			if location == nil {
+				continue
+			}
+
+			id := j
+			location.ID = &id
+			location.Message = &sarifMessage{note.Message}
+			result.RelatedLocations = append(result.RelatedLocations, *location)
+		}
+
+		results[i] = result
+	}
+
+	driver := sarifDriver{Name: toolName, InformationURI: toolURI, Rules: make([]sarifRule, 0, len(rules))}
+	for code := range rules {
+		driver.Rules = append(driver.Rules, sarifRule{ID: code})
+	}
+
+	sort.Slice(driver.Rules, func(i, j int) bool {
+		return driver.Rules[i].ID < driver.Rules[j].ID
+	})
+
+	run := sarifRun{Tool: sarifTool{driver}, Results: results}
+	if len(root) > 0 {
+		rootURI, err := sarifRootURI(root)
+		if err != nil {
+			return err
+		}
+
+		run.OriginalURIBaseIDs = map[string]sarifArtifactLocation{sarifRootID: {URI: rootURI}}
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
+
+// newSARIFLocation returns the location of at in file, or nil if the file is unknown
+func newSARIFLocation(file string, at tokenizer.Pos) *sarifLocation {
+	if len(file) == 0 {
+		return nil
+	}
+
+	location := &sarifLocation{
+		PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: file, URIBaseID: sarifRootID}},
+	}
+
+	if at.Line > 0 {
+		location.PhysicalLocation.Region = &sarifRegion{
+			StartLine:   at.Line,
+			StartColumn: at.Column16,
+			EndLine:     at.Endline,
+			EndColumn:   at.EndColumn16,
+		}
+	}
+
+	return location
+}
+
+// sarifRootURI returns the absolute file uri of the folder root, which ends with a slash as SARIF requires
+func sarifRootURI(root string) (string, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.ToSlash(abs)
+	if !strings.HasPrefix(path, "/") {
+		// a Windows path, like C:/project
+		path = "/" + path
+	}
+
+	if !strings.HasSuffix(path, "/") {
+		path += "/"
+	}
+
+	return (&url.URL{Scheme: "file", Path: path}).String(), nil
+}
@@ -0,0 +1,610 @@
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"tomasweigenast.com/nexema/tool/token"
+	"tomasweigenast.com/nexema/tool/tokenizer"
+)
+
+// The concrete syntax tree keeps what the parser.Ast drops: every comment, where it was written and the
+// blank lines between statements. Each statement is a node printed in its own lines, and comments are either
+// nodes too, or trailing comments of the statement written before them in the same line.
+
+// node is a statement of a file or of the body of a block
+type node interface {
+	base() *nodeBase
+}
+
+type nodeBase struct {
+	blankBefore bool     // true if a blank line separates the node from the previous one
+	trailing    []string // the comments written after the node, in its last line
+}
+
+func (self *nodeBase) base() *nodeBase {
+	return self
+}
+
+// commentNode is a comment written in its own line, as it is written, including its delimiters
+type commentNode struct {
+	nodeBase
+	text string
+}
+
+// assignNode is an annotation, like #obsolete = true, or an assignment of a defaults block
+type assignNode struct {
+	nodeBase
+	annotation bool
+	key        string
+	value      *literalNode
+}
+
+type useNode struct {
+	nodeBase
+	path  string // the path literal, with its quotes
+	alias string
+}
+
+// block is the body of a type, oneof or defaults block
+type block struct {
+	nodes    []node
+	trailing []string // the comments written in the line of the opening brace
+}
+
+type typeNode struct {
+	nodeBase
+	name       string
+	modifier   string    // struct, enum, union, base or flags
+	baseType   *declNode // the type it extends, if any
+	underlying string    // the underlying type of flags, if declared
+	body       block
+}
+
+type oneofNode struct {
+	nodeBase
+	name string
+	body block
+}
+
+type defaultsNode struct {
+	nodeBase
+	body block
+}
+
+type fieldNode struct {
+	nodeBase
+	index     string
+	name      string
+	valueType *declNode
+	aliasOf   string   // the member an enum member is an alias of
+	combines  []string // the members a flags member is a combination of
+}
+
+type declNode struct {
+	name     string // the type name, or the length if it is a length argument
+	alias    string
+	args     []*declNode
+	nullable bool
+}
+
+type literalNode struct {
+	text    string         // the literal as it is written, if it is not a list or map
+	list    []*literalNode // the elements of a list
+	entries [][2]*literalNode
+	isList  bool
+	isMap   bool
+}
+
+// sourceToken is a token and the comments written between the previous token and it
+type sourceToken struct {
+	kind     token.TokenKind
+	text     string // as it is written in the source
+	newlines int    // the number of line breaks between the previous token or comment and this one
+	comments []*sourceComment
+}
+
+// keyword returns the kind of the token, or contextual if the token is an identifier spelled as it, since
+// contextual keywords are read as identifiers
+func (self *sourceToken) keyword(contextual token.TokenKind) token.TokenKind {
+	if self.kind == token.Ident && self.text == contextual.String() {
+		return contextual
+	}
+
+	return self.kind
+}
+
+type sourceComment struct {
+	text     string
+	newlines int
+}
+
+// tokenize reads every token of source, the last one being token.EOF with the comments at the end of the file
+func tokenize(source []byte) ([]*sourceToken, error) {
+	t := tokenizer.NewTokenizer(bytes.NewReader(source))
+	tokens := make([]*sourceToken, 0)
+	comments := make([]*sourceComment, 0)
+	end := 0
+	for {
+		tok, pos, err := t.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		if tok.IsEOF() {
+			tokens = append(tokens, &sourceToken{kind: token.EOF, newlines: bytes.Count(source[end:], []byte{'\n'}), comments: comments})
+			return tokens, nil
+		}
+
+		newlines := bytes.Count(source[end:pos.Start], []byte{'\n'})
+		text := string(source[pos.Start:pos.End])
+		end = pos.End
+
+		switch tok.Kind {
+		case token.Comment, token.DocComment, token.CommentMultiline, token.DocCommentMultiline:
+			comments = append(comments, &sourceComment{strings.TrimRight(text, " \t\r"), newlines})
+
+		default:
+			tokens = append(tokens, &sourceToken{kind: tok.Kind, text: text, newlines: newlines, comments: comments})
+			comments = make([]*sourceComment, 0)
+		}
+	}
+}
+
+// cstParser builds the concrete syntax tree of a file. The file must be valid, since it is parsed by the
+// parser first, so errors are only returned for unexpected tokens
+type cstParser struct {
+	tokens []*sourceToken
+	index  int
+	inner  []*sourceComment // the comments written between the tokens of the statement being parsed
+}
+
+// parseFile returns the statements of the file
+func (self *cstParser) parseFile() ([]node, error) {
+	nodes := make([]node, 0)
+	for {
+		tok := self.leading(&nodes, nil)
+		var stmt node
+		var err error
+		switch tok.kind {
+		case token.EOF:
+			return nodes, nil
+		case token.Use:
+			stmt, err = self.parseUse()
+		case token.Type:
+			stmt, err = self.parseType()
+		case token.Hash:
+			stmt, err = self.parseAssign()
+		default:
+			err = self.unexpected(tok)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		self.push(&nodes, stmt, tok)
+	}
+}
+
+// parseBlock parses the statements between braces. The current token must be the opening brace
+func (self *cstParser) parseBlock(parseStmt func(tok *sourceToken) (node, error)) (block, error) {
+	if _, err := self.expect(token.Lbrace); err != nil {
+		return block{}, err
+	}
+
+	// the comments written in the declaration of the block go before it, not before its first statement
+	header := self.inner
+	self.inner = nil
+
+	body := block{nodes: make([]node, 0)}
+	for {
+		tok := self.leading(&body.nodes, &body.trailing)
+		if tok.kind == token.Rbrace {
+			self.next()
+			self.inner = append(header, self.inner...)
+			return body, nil
+		}
+
+		stmt, err := parseStmt(tok)
+		if err != nil {
+			return block{}, err
+		}
+
+		self.push(&body.nodes, stmt, tok)
+	}
+}
+
+// leading converts the comments before the current token in nodes, or in trailing comments of the last node
+// if they are written in its line, and returns the current token. headerTrailing receives the comments
+// written in the line of the opening brace of a block, if it does not have nodes yet
+func (self *cstParser) leading(nodes *[]node, headerTrailing *[]string) *sourceToken {
+	tok := self.current()
+	comments := tok.comments
+	tok.comments = nil
+
+	for len(comments) > 0 && comments[0].newlines == 0 {
+		if len(*nodes) > 0 {
+			last := (*nodes)[len(*nodes)-1].base()
+			last.trailing = append(last.trailing, comments[0].text)
+		} else if headerTrailing != nil {
+			*headerTrailing = append(*headerTrailing, comments[0].text)
+		} else {
+			break
+		}
+
+		comments = comments[1:]
+	}
+
+	for _, comment := range comments {
+		*nodes = append(*nodes, &commentNode{nodeBase: nodeBase{blankBefore: comment.newlines > 1}, text: comment.text})
+	}
+
+	return tok
+}
+
+// push appends stmt to nodes, after the comments written between its tokens
+func (self *cstParser) push(nodes *[]node, stmt node, first *sourceToken) {
+	for _, comment := range self.inner {
+		*nodes = append(*nodes, &commentNode{text: comment.text})
+	}
+	self.inner = nil
+
+	stmt.base().blankBefore = first.newlines > 1
+	*nodes = append(*nodes, stmt)
+}
+
+func (self *cstParser) current() *sourceToken {
+	return self.tokens[self.index]
+}
+
+// peek returns the token offset positions after the current one, or the end of the file
+func (self *cstParser) peek(offset int) *sourceToken {
+	if self.index+offset >= len(self.tokens) {
+		return self.tokens[len(self.tokens)-1]
+	}
+
+	return self.tokens[self.index+offset]
+}
+
+// atOneof returns true if the current token starts a oneof group, which is the oneof keyword followed by the
+// name of the group and an opening brace. Otherwise, oneof is the name of a field
+func (self *cstParser) atOneof() bool {
+	return self.current().keyword(token.Oneof) == token.Oneof && self.peek(1).kind == token.Ident && self.peek(2).kind == token.Lbrace
+}
+
+// next returns the current token and moves to the next one. The comments before the current token, if any,
+// are saved as inner comments, since the ones before the first token of a statement are taken by leading
+func (self *cstParser) next() *sourceToken {
+	tok := self.current()
+	self.inner = append(self.inner, tok.comments...)
+	tok.comments = nil
+	if tok.kind != token.EOF {
+		self.index++
+	}
+
+	return tok
+}
+
+// expect returns the current token and moves to the next one if it is of the given kind
+func (self *cstParser) expect(kind token.TokenKind) (*sourceToken, error) {
+	tok := self.current()
+	if tok.kind != kind {
+		return nil, self.unexpected(tok)
+	}
+
+	return self.next(), nil
+}
+
+func (self *cstParser) is(kind token.TokenKind) bool {
+	return self.current().kind == kind
+}
+
+func (self *cstParser) unexpected(tok *sourceToken) error {
+	if tok.kind == token.EOF {
+		return fmt.Errorf("unexpected end of file")
+	}
+
+	return fmt.Errorf("unexpected %q", tok.text)
+}
+
+// parseUse parses: use "path" (as alias)
+func (self *cstParser) parseUse() (node, error) {
+	self.next()
+	path, err := self.expect(token.String)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := &useNode{path: path.text}
+	if self.is(token.As) {
+		self.next()
+		alias, err := self.expect(token.Ident)
+		if err != nil {
+			return nil, err
+		}
+
+		stmt.alias = alias.text
+	}
+
+	return stmt, nil
+}
+
+// parseType parses: type name [modifier | extends base | flags(underlying)] { body }
+func (self *cstParser) parseType() (node, error) {
+	self.next()
+	name, err := self.expect(token.Ident)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := &typeNode{name: name.text}
+	tok := self.next()
+	switch tok.keyword(token.Flags) {
+	case token.Extends:
+		stmt.modifier = "struct"
+		stmt.baseType, err = self.parseDecl()
+		if err != nil {
+			return nil, err
+		}
+
+	case token.Struct, token.Enum, token.Union, token.Base:
+		stmt.modifier = tok.text
+
+	case token.Flags:
+		stmt.modifier = tok.text
+		if self.is(token.Lparen) {
+			self.next()
+			underlying, err := self.expect(token.Ident)
+			if err != nil {
+				return nil, err
+			}
+
+			if _, err := self.expect(token.Rparen); err != nil {
+				return nil, err
+			}
+
+			stmt.underlying = underlying.text
+		}
+
+	default:
+		return nil, self.unexpected(tok)
+	}
+
+	isEnum := stmt.modifier == "enum" || stmt.modifier == "flags"
+	stmt.body, err = self.parseBlock(func(tok *sourceToken) (node, error) {
+		switch tok.kind {
+		case token.Hash:
+			return self.parseAssign()
+		case token.Defaults:
+			return self.parseDefaults()
+		}
+
+		if self.atOneof() {
+			return self.parseOneof()
+		}
+
+		return self.parseField(isEnum)
+	})
+
+	return stmt, err
+}
+
+// parseOneof parses: oneof name { fields }
+func (self *cstParser) parseOneof() (node, error) {
+	self.next()
+	name, err := self.expect(token.Ident)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := &oneofNode{name: name.text}
+	stmt.body, err = self.parseBlock(func(tok *sourceToken) (node, error) {
+		if tok.kind == token.Hash {
+			return self.parseAssign()
+		}
+
+		return self.parseField(false)
+	})
+
+	return stmt, err
+}
+
+// parseDefaults parses: defaults { assignments }
+func (self *cstParser) parseDefaults() (node, error) {
+	self.next()
+
+	var err error
+	stmt := &defaultsNode{}
+	stmt.body, err = self.parseBlock(func(tok *sourceToken) (node, error) {
+		return self.parseAssign()
+	})
+
+	return stmt, err
+}
+
+// parseField parses: (index) name type, or, for enums and flags: (index) name (= member (| member))
+func (self *cstParser) parseField(isEnum bool) (node, error) {
+	stmt := &fieldNode{}
+	if self.is(token.Integer) {
+		stmt.index = self.next().text
+	}
+
+	name, err := self.expect(token.Ident)
+	if err != nil {
+		return nil, err
+	}
+	stmt.name = name.text
+
+	if !isEnum {
+		stmt.valueType, err = self.parseDecl()
+		return stmt, err
+	}
+
+	if !self.is(token.Assign) {
+		return stmt, nil
+	}
+
+	self.next()
+	members := make([]string, 0)
+	for {
+		member, err := self.expect(token.Ident)
+		if err != nil {
+			return nil, err
+		}
+
+		members = append(members, member.text)
+		if !self.is(token.Pipe) {
+			break
+		}
+
+		self.next()
+	}
+
+	if len(members) == 1 {
+		stmt.aliasOf = members[0]
+	} else {
+		stmt.combines = members
+	}
+
+	return stmt, nil
+}
+
+// parseAssign parses: (#) key = literal
+func (self *cstParser) parseAssign() (node, error) {
+	stmt := &assignNode{}
+	if self.is(token.Hash) {
+		stmt.annotation = true
+		self.next()
+	}
+
+	key, err := self.expect(token.Ident)
+	if err != nil {
+		return nil, err
+	}
+	stmt.key = key.text
+
+	if _, err := self.expect(token.Assign); err != nil {
+		return nil, err
+	}
+
+	stmt.value, err = self.parseLiteral()
+	return stmt, err
+}
+
+// parseDecl parses: (alias.)name((args))(?), where args are declarations or lengths
+func (self *cstParser) parseDecl() (*declNode, error) {
+	name, err := self.expect(token.Ident)
+	if err != nil {
+		return nil, err
+	}
+
+	decl := &declNode{name: name.text}
+	if self.is(token.Period) {
+		self.next()
+		name, err := self.expect(token.Ident)
+		if err != nil {
+			return nil, err
+		}
+
+		decl.alias = decl.name
+		decl.name = name.text
+	}
+
+	if self.is(token.Lparen) {
+		self.next()
+		for !self.is(token.Rparen) {
+			var arg *declNode
+			if self.is(token.Integer) {
+				arg = &declNode{name: self.next().text}
+			} else if arg, err = self.parseDecl(); err != nil {
+				return nil, err
+			}
+
+			decl.args = append(decl.args, arg)
+			if self.is(token.Comma) {
+				self.next()
+			} else if !self.is(token.Rparen) {
+				return nil, self.unexpected(self.current())
+			}
+		}
+
+		self.next()
+	}
+
+	if self.is(token.QuestionMark) {
+		self.next()
+		decl.nullable = true
+	}
+
+	return decl, nil
+}
+
+// parseLiteral parses a string, number, boolean, list or map literal
+func (self *cstParser) parseLiteral() (*literalNode, error) {
+	switch tok := self.next(); tok.kind {
+	case token.String, token.Integer, token.Decimal, token.Ident:
+		return &literalNode{text: tok.text}, nil
+
+	case token.Lbrack:
+		literal := &literalNode{isList: true}
+		for !self.is(token.Rbrack) {
+			elem, err := self.parseLiteral()
+			if err != nil {
+				return nil, err
+			}
+
+			literal.list = append(literal.list, elem)
+			if err := self.separator(token.Rbrack); err != nil {
+				return nil, err
+			}
+		}
+
+		self.next()
+		return literal, nil
+
+	case token.Lbrace:
+		literal := &literalNode{isMap: true}
+		for !self.is(token.Rbrace) {
+			key, err := self.parseLiteral()
+			if err != nil {
+				return nil, err
+			}
+
+			if _, err := self.expect(token.Colon); err != nil {
+				return nil, err
+			}
+
+			value, err := self.parseLiteral()
+			if err != nil {
+				return nil, err
+			}
+
+			literal.entries = append(literal.entries, [2]*literalNode{key, value})
+			if err := self.separator(token.Rbrace); err != nil {
+				return nil, err
+			}
+		}
+
+		self.next()
+		return literal, nil
+
+	default:
+		return nil, self.unexpected(tok)
+	}
+}
+
+// separator skips the comma between the elements of a list or map, which is optional after the last one
+func (self *cstParser) separator(closing token.TokenKind) error {
+	if self.is(token.Comma) {
+		self.next()
+		return nil
+	}
+
+	if !self.is(closing) {
+		return self.unexpected(self.current())
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+// Package format formats .nex files in the canonical format, keeping their comments
+package format
+
+import (
+	"bytes"
+
+	"tomasweigenast.com/nexema/tool/parser"
+)
+
+// Source returns source formatted in the canonical format. Formatting a formatted file does not change it.
+//
+// The file must be valid. If it cannot be parsed, the returned error is a diagnostic.Diagnostics with the syntax
+// errors found in it, reported in file
+func Source(source []byte, file *parser.File) ([]byte, error) {
+	p := parser.NewParser(bytes.NewReader(source), file)
+	p.Begin()
+	p.Parse()
+	if !p.Errors().IsEmpty() {
+		return nil, p.Errors().Diagnostics()
+	}
+
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+
+	cst := &cstParser{tokens: tokens}
+	nodes, err := cst.parseFile()
+	if err != nil {
+		return nil, err
+	}
+
+	printer := new(printer)
+	printer.printFile(nodes)
+	return []byte(printer.out.String()), nil
+}
@@ -0,0 +1,152 @@
+package format
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/require"
+	"tomasweigenast.com/nexema/tool/diagnostic"
+	"tomasweigenast.com/nexema/tool/parser"
+	"tomasweigenast.com/nexema/tool/tokenizer"
+)
+
+var testFile = &parser.File{Path: "foo", FileName: "bar.nex"}
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "empty file",
+			input: "",
+			want:  "",
+		},
+		{
+			name:  "spacing and indentation",
+			input: "type   A   struct{\n  a   string\n        b list( int32 )?\n}",
+			want:  "type A struct {\n\ta string\n\tb list(int32)?\n}\n",
+		},
+		{
+			name:  "empty types",
+			input: "type A struct {\n\n}\ntype B base {}",
+			want:  "type A struct {}\n\ntype B base {}\n",
+		},
+		{
+			name:  "aligned fields",
+			input: "type A struct {\n1 id string // the id\n10 display_name string? // the name\n2 tags list(string)\n}\n",
+			want:  "type A struct {\n\t 1 id           string       // the id\n\t10 display_name string?      // the name\n\t 2 tags         list(string)\n}\n",
+		},
+		{
+			name:  "fields aligned by section",
+			input: "type A struct {\n\tid string\n\n\tdisplay_name string\n}\n",
+			want:  "type A struct {\n\tid string\n\n\tdisplay_name string\n}\n",
+		},
+		{
+			name:  "enum",
+			input: "type Status enum {\nunknown\n   active\ninactive = active\n}\n",
+			want:  "type Status enum {\n\tunknown\n\tactive\n\tinactive = active\n}\n",
+		},
+		{
+			name:  "flags",
+			input: "type Perm flags(uint8) {\nread\nwrite\nall = read|write\n}\n",
+			want:  "type Perm flags(uint8) {\n\tread\n\twrite\n\tall = read | write\n}\n",
+		},
+		{
+			name:  "fields named flags",
+			input: "type flags struct {\nflags   uint32\n}\n",
+			want:  "type flags struct {\n\tflags uint32\n}\n",
+		},
+		{
+			name:  "fields named oneof",
+			input: "type A struct {\n0 oneof string\noneof   bool\noneof value {\na string\n}\n}\n",
+			want:  "type A struct {\n\t0 oneof string\n\t  oneof bool\n\toneof value {\n\t\ta string\n\t}\n}\n",
+		},
+		{
+			name:  "extends",
+			input: "type Base base {\nid string\n}\ntype A extends   Base {\nname string\n}\n",
+			want:  "type Base base {\n\tid string\n}\n\ntype A extends Base {\n\tname string\n}\n",
+		},
+		{
+			name:  "oneof and defaults",
+			input: "type A struct {\nname string\noneof value {\ntext string\n  number int64\n}\ndefaults {\nname = \"x\"\n}\n}\n",
+			want:  "type A struct {\n\tname string\n\toneof value {\n\t\ttext   string\n\t\tnumber int64\n\t}\n\n\tdefaults {\n\t\tname = \"x\"\n\t}\n}\n",
+		},
+		{
+			name:  "literals",
+			input: "type A struct {\n#tags=[ \"a\",\"b\" ]\n#limits = {\"max\" :10,\"min\":0x1}\na string\n}\n",
+			want:  "type A struct {\n\t#tags = [\"a\", \"b\"]\n\t#limits = {\"max\": 10, \"min\": 0x1}\n\ta string\n}\n",
+		},
+		{
+			name:  "sorted uses",
+			input: "use \"zeta\"\nuse \"alpha\" as a\n// the common types\nuse \"common\"\ntype A struct {\na a.B\n}\n",
+			want:  "use \"alpha\" as a\n// the common types\nuse \"common\"\nuse \"zeta\"\n\ntype A struct {\n\ta a.B\n}\n",
+		},
+		{
+			name:  "comments of the use that becomes first",
+			input: "use \"zeta\"\n// the common types\nuse \"common\"\n",
+			want:  "use \"common\" // the common types\nuse \"zeta\"\n",
+		},
+		{
+			name:  "header, documentation and trailing comments",
+			input: "// Package foo\n#version = 1\nuse \"common\"\n\n\n// A is documented\n#obsolete = true\ntype A struct { // starts here\n\t// the name\n\tname string // trailing\n\n\n\t// a loose comment\n}\n// end of file\n",
+			want:  "// Package foo\n#version = 1\n\nuse \"common\"\n\n// A is documented\n#obsolete = true\ntype A struct { // starts here\n\t// the name\n\tname string // trailing\n\n\t// a loose comment\n}\n// end of file\n",
+		},
+		{
+			name:  "windows line endings",
+			input: "type A struct {\r\n\ta string\r\n}\r\n",
+			want:  "type A struct {\n\ta string\n}\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			formatted, err := Source([]byte(test.input), testFile)
+			require.NoError(t, err)
+			require.Equal(t, test.want, string(formatted))
+
+			again, err := Source(formatted, testFile)
+			require.NoError(t, err)
+			require.Equal(t, string(formatted), string(again), "formatting is not idempotent")
+
+			want, got := parse(t, test.input), parse(t, string(formatted))
+			if diff := cmp.Diff(want, got, astOptions...); diff != "" {
+				t.Errorf("TestSource: %s: formatting changed the meaning of the file (-want +got):\n%s", test.name, diff)
+			}
+		})
+	}
+}
+
+func TestSource_Invalid(t *testing.T) {
+	_, err := Source([]byte("type A struct {\n\ta string\n"), testFile)
+
+	var errs diagnostic.Diagnostics
+	require.True(t, errors.As(err, &errs))
+	require.NotEmpty(t, errs)
+	require.Equal(t, "foo/bar.nex", errs[0].File)
+}
+
+var astOptions = []cmp.Option{
+	cmpopts.IgnoreTypes(tokenizer.Pos{}),
+	cmp.Exporter(func(reflect.Type) bool { return true }),
+	cmpopts.EquateEmpty(),
+}
+
+// parse returns the ast of input, with its use statements sorted, as the formatter does
+func parse(t *testing.T, input string) *parser.Ast {
+	p := parser.NewParser(bytes.NewReader([]byte(input)), testFile)
+	p.Begin()
+	ast := p.Parse()
+	require.True(t, p.Errors().IsEmpty())
+
+	sort.SliceStable(ast.UseStatements, func(i, j int) bool {
+		return ast.UseStatements[i].Path.Token.Literal < ast.UseStatements[j].Path.Token.Literal
+	})
+	return ast
+}
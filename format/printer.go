@@ -0,0 +1,418 @@
+package format
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// indentation is written once per level of nesting
+const indentation = "\t"
+
+// printer writes the concrete syntax tree of a file in the canonical format:
+//
+//   - the header of the file, then the use statements, sorted by path, then the types, separated by one blank line
+//   - one statement per line, indented with tabs, and at most one blank line between statements
+//   - single spaces between tokens, and around = and after :, and no spaces inside parentheses and brackets
+//   - the indexes, names and trailing comments of consecutive fields aligned in columns
+type printer struct {
+	out    strings.Builder
+	indent int
+}
+
+// printFile writes the statements of a file
+func (self *printer) printFile(nodes []node) {
+	self.printNodes(normalizeFile(nodes))
+}
+
+// printNodes writes nodes in their own lines
+func (self *printer) printNodes(nodes []node) {
+	for i := 0; i < len(nodes); i++ {
+		if i > 0 && nodes[i].base().blankBefore {
+			self.out.WriteString("\n")
+		}
+
+		if _, ok := nodes[i].(*fieldNode); ok {
+			end := fieldSection(nodes, i)
+			self.printFields(nodes[i:end])
+			i = end - 1
+			continue
+		}
+
+		self.printNode(nodes[i])
+	}
+}
+
+// fieldSection returns the index where the section of fields that starts at start ends. A section ends with a
+// blank line or a statement that is not a field, comment or annotation
+func fieldSection(nodes []node, start int) int {
+	end := start + 1
+	for end < len(nodes) && !nodes[end].base().blankBefore {
+		switch nodes[end].(type) {
+		case *fieldNode, *commentNode, *assignNode:
+			end++
+			continue
+		}
+
+		break
+	}
+
+	return end
+}
+
+// printFields writes a section of fields, whose indexes, names and trailing comments are aligned
+func (self *printer) printFields(nodes []node) {
+	indexWidth, nameWidth, lineWidth := 0, 0, 0
+	for _, n := range nodes {
+		if field, ok := n.(*fieldNode); ok {
+			indexWidth = maxInt(indexWidth, width(field.index))
+			if len(field.rest()) > 0 {
+				nameWidth = maxInt(nameWidth, width(field.name))
+			}
+		}
+	}
+
+	lines := make([]string, len(nodes))
+	for i, n := range nodes {
+		field, ok := n.(*fieldNode)
+		if !ok {
+			continue
+		}
+
+		var line strings.Builder
+		if indexWidth > 0 {
+			line.WriteString(strings.Repeat(" ", indexWidth-width(field.index)))
+			line.WriteString(field.index)
+			line.WriteString(" ")
+		}
+
+		line.WriteString(field.name)
+		if rest := field.rest(); len(rest) > 0 {
+			line.WriteString(strings.Repeat(" ", nameWidth-width(field.name)+1))
+			line.WriteString(rest)
+		}
+
+		lines[i] = line.String()
+		lineWidth = maxInt(lineWidth, width(lines[i]))
+	}
+
+	for i, n := range nodes {
+		if i > 0 && n.base().blankBefore {
+			self.out.WriteString("\n")
+		}
+
+		if _, ok := n.(*fieldNode); !ok {
+			self.printNode(n)
+			continue
+		}
+
+		self.writeIndent()
+		self.out.WriteString(lines[i])
+		if trailing := n.base().trailing; len(trailing) > 0 {
+			self.out.WriteString(strings.Repeat(" ", lineWidth-width(lines[i])+1))
+			self.out.WriteString(strings.Join(trailing, " "))
+		}
+		self.out.WriteString("\n")
+	}
+}
+
+// printNode writes a statement that is not part of a section of fields
+func (self *printer) printNode(n node) {
+	self.writeIndent()
+	switch n := n.(type) {
+	case *commentNode:
+		self.out.WriteString(n.text)
+
+	case *assignNode:
+		self.out.WriteString(n.String())
+
+	case *useNode:
+		self.out.WriteString("use ")
+		self.out.WriteString(n.path)
+		if len(n.alias) > 0 {
+			self.out.WriteString(" as ")
+			self.out.WriteString(n.alias)
+		}
+
+	case *fieldNode:
+		self.out.WriteString(n.String())
+
+	case *typeNode:
+		self.out.WriteString("type ")
+		self.out.WriteString(n.name)
+		self.out.WriteString(" ")
+		if n.baseType != nil {
+			self.out.WriteString("extends ")
+			self.out.WriteString(n.baseType.String())
+		} else {
+			self.out.WriteString(n.modifier)
+			if len(n.underlying) > 0 {
+				self.out.WriteString("(" + n.underlying + ")")
+			}
+		}
+		self.printBlock(n.body)
+
+	case *oneofNode:
+		self.out.WriteString("oneof ")
+		self.out.WriteString(n.name)
+		self.printBlock(n.body)
+
+	case *defaultsNode:
+		self.out.WriteString("defaults")
+		self.printBlock(n.body)
+	}
+
+	self.writeTrailing(n.base().trailing)
+	self.out.WriteString("\n")
+}
+
+// printBlock writes body between braces, after a space. Empty blocks are written in the same line
+func (self *printer) printBlock(body block) {
+	self.out.WriteString(" {")
+	if len(body.nodes) == 0 && len(body.trailing) == 0 {
+		self.out.WriteString("}")
+		return
+	}
+
+	self.writeTrailing(body.trailing)
+	self.out.WriteString("\n")
+
+	self.indent++
+	self.printNodes(normalizeBlock(body))
+	self.indent--
+
+	self.writeIndent()
+	self.out.WriteString("}")
+}
+
+func (self *printer) writeIndent() {
+	self.out.WriteString(strings.Repeat(indentation, self.indent))
+}
+
+func (self *printer) writeTrailing(comments []string) {
+	if len(comments) > 0 {
+		self.out.WriteString(" ")
+		self.out.WriteString(strings.Join(comments, " "))
+	}
+}
+
+// normalizeFile sorts the use statements of the file, and separates the header, the use statements and every
+// type, with its comments and annotations, by one blank line
+func normalizeFile(nodes []node) []node {
+	first, last := -1, -1
+	for i, n := range nodes {
+		if _, ok := n.(*useNode); ok {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+
+	if first >= 0 {
+		out := make([]node, 0, len(nodes))
+		out = append(out, nodes[:first]...)
+		for i, group := range sortUses(nodes[first : last+1]) {
+			for j, n := range group {
+				n.base().blankBefore = i == 0 && j == 0 && first > 0
+			}
+
+			out = append(out, group...)
+		}
+
+		if last+1 < len(nodes) {
+			nodes[last+1].base().blankBefore = true
+		}
+		nodes = append(out, nodes[last+1:]...)
+	}
+
+	for i, n := range nodes {
+		if _, ok := n.(*typeNode); ok {
+			separate(nodes, i)
+		}
+	}
+
+	if len(nodes) > 0 {
+		nodes[0].base().blankBefore = false
+	}
+
+	return nodes
+}
+
+// sortUses returns the use statements in nodes sorted by path, each one in a group with the comments written
+// before it.
+//
+// Comments before the first use statement are the documentation of the file, so the comments of the statement
+// that becomes the first one are moved to its line. Annotations between use statements would become annotations
+// of the file too, so if there is any, the statements are not sorted
+func sortUses(nodes []node) [][]node {
+	groups := make([][]node, 0)
+	start := 0
+	sortable := true
+	for i, n := range nodes {
+		switch n.(type) {
+		case *useNode:
+			groups = append(groups, nodes[start:i+1])
+			start = i + 1
+		case *assignNode:
+			sortable = false
+		}
+	}
+
+	if !sortable {
+		return groups
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return lastUse(groups[i]).importPath() < lastUse(groups[j]).importPath()
+	})
+
+	if first := groups[0]; len(first) > 1 {
+		use := lastUse(first)
+		for _, n := range first[:len(first)-1] {
+			use.trailing = append(use.trailing, n.(*commentNode).text)
+			use.trailing = append(use.trailing, n.base().trailing...)
+		}
+
+		groups[0] = []node{use}
+	}
+
+	return groups
+}
+
+func lastUse(group []node) *useNode {
+	return group[len(group)-1].(*useNode)
+}
+
+// normalizeBlock removes the blank lines at the start of body and separates its defaults block from the
+// statements before it
+func normalizeBlock(body block) []node {
+	nodes := body.nodes
+	for i, n := range nodes {
+		if _, ok := n.(*defaultsNode); ok {
+			separate(nodes, i)
+		}
+	}
+
+	// a comment in the line of the opening brace is separated from the first statement as it was written
+	if len(nodes) > 0 && len(body.trailing) == 0 {
+		nodes[0].base().blankBefore = false
+	}
+
+	return nodes
+}
+
+// separate adds a blank line before the statement at index, and before the comments and annotations written
+// right before it
+func separate(nodes []node, index int) {
+	start := index
+	for start > 0 && !nodes[start].base().blankBefore {
+		switch nodes[start-1].(type) {
+		case *commentNode, *assignNode:
+			start--
+			continue
+		}
+
+		break
+	}
+
+	nodes[start].base().blankBefore = start > 0
+}
+
+// rest returns what is written after the name of the field, if anything
+func (self *fieldNode) rest() string {
+	switch {
+	case self.valueType != nil:
+		return self.valueType.String()
+	case len(self.aliasOf) > 0:
+		return "= " + self.aliasOf
+	case len(self.combines) > 0:
+		return "= " + strings.Join(self.combines, " | ")
+	}
+
+	return ""
+}
+
+func (self *fieldNode) String() string {
+	out := self.name
+	if len(self.index) > 0 {
+		out = self.index + " " + out
+	}
+
+	if rest := self.rest(); len(rest) > 0 {
+		out += " " + rest
+	}
+
+	return out
+}
+
+func (self *assignNode) String() string {
+	out := self.key + " = " + self.value.String()
+	if self.annotation {
+		out = "#" + out
+	}
+
+	return out
+}
+
+// importPath returns the path of the use statement, without quotes
+func (self *useNode) importPath() string {
+	return self.path[1 : len(self.path)-1]
+}
+
+func (self *declNode) String() string {
+	out := self.name
+	if len(self.alias) > 0 {
+		out = self.alias + "." + out
+	}
+
+	if len(self.args) > 0 {
+		args := make([]string, len(self.args))
+		for i, arg := range self.args {
+			args[i] = arg.String()
+		}
+
+		out += "(" + strings.Join(args, ", ") + ")"
+	}
+
+	if self.nullable {
+		out += "?"
+	}
+
+	return out
+}
+
+func (self *literalNode) String() string {
+	switch {
+	case self.isList:
+		elems := make([]string, len(self.list))
+		for i, elem := range self.list {
+			elems[i] = elem.String()
+		}
+
+		return "[" + strings.Join(elems, ", ") + "]"
+
+	case self.isMap:
+		entries := make([]string, len(self.entries))
+		for i, entry := range self.entries {
+			entries[i] = entry[0].String() + ": " + entry[1].String()
+		}
+
+		return "{" + strings.Join(entries, ", ") + "}"
+	}
+
+	return self.text
+}
+
+// width returns the number of characters of s
+func width(s string) int {
+	return utf8.RuneCountInString(s)
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,73 @@
+package integration_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"tomasweigenast.com/nexema/tool/builder"
+	"tomasweigenast.com/nexema/tool/diagnostic"
+)
+
+// TestExplanations_Examples builds every example written in the explanations, ensuring erroneous ones report
+// the code they explain and correct ones build and lint without diagnostics
+func TestExplanations_Examples(t *testing.T) {
+	explanations := diagnostic.Explanations()
+	require.NotEmpty(t, explanations)
+
+	for _, explanation := range explanations {
+		explanation := explanation
+		t.Run(explanation.Code, func(t *testing.T) {
+			examples := explanation.Examples()
+			require.NotEmpty(t, examples, "explanation without examples")
+
+			var bad, good int
+			for i, example := range examples {
+				diagnostics := buildExample(t, example)
+				if example.Valid {
+					good++
+					require.Empty(t, diagnostics, "correct example %d reports diagnostics", i)
+					continue
+				}
+
+				bad++
+				codes := make([]string, len(diagnostics))
+				for j, diagnostic := range diagnostics {
+					codes[j] = diagnostic.Code
+				}
+				require.Contains(t, codes, example.Code, "erroneous example %d does not report %s:\n%s", i, example.Code, diagnostics)
+			}
+
+			require.NotZero(t, bad, "explanation without erroneous examples")
+			require.NotZero(t, good, "explanation without correct examples")
+		})
+	}
+}
+
+// buildExample writes the files of example in a new project, builds and lints it, returning its errors and warnings.
+// An example can declare its own nexema.yaml, for example, to enable a lint rule
+func buildExample(t *testing.T, example diagnostic.Example) diagnostic.Diagnostics {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "nexema.yaml"), []byte("version: 1\ngenerators:\n  js:\n"), os.ModePerm))
+	for name, contents := range example.Files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), os.ModePerm))
+		require.NoError(t, os.WriteFile(path, []byte(contents), os.ModePerm))
+	}
+
+	builder := builder.NewBuilder(root)
+	require.NoError(t, builder.Discover())
+
+	if err := builder.Build(); err != nil {
+		var errs diagnostic.Diagnostics
+		require.True(t, errors.As(err, &errs), "unexpected build error: %s", err)
+		return append(builder.Warnings(), errs...)
+	}
+
+	lintDiagnostics, err := builder.Lint()
+	require.NoError(t, err)
+
+	return append(builder.Warnings(), lintDiagnostics...)
+}
@@ -0,0 +1,47 @@
+package integration_test
+
+import (
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"tomasweigenast.com/nexema/tool/diagnostic"
+	"tomasweigenast.com/nexema/tool/format"
+	"tomasweigenast.com/nexema/tool/parser"
+)
+
+// TestFormat_Examples formats every correct example written in the explanations, ensuring the formatted files
+// are formatted again without changes and still build and lint without diagnostics
+func TestFormat_Examples(t *testing.T) {
+	for _, explanation := range diagnostic.Explanations() {
+		explanation := explanation
+		t.Run(explanation.Code, func(t *testing.T) {
+			for i, example := range explanation.Examples() {
+				if !example.Valid {
+					continue
+				}
+
+				formatted := diagnostic.Example{Valid: true, Files: map[string]string{}}
+				for name, contents := range example.Files {
+					if !strings.HasSuffix(name, ".nex") {
+						formatted.Files[name] = contents
+						continue
+					}
+
+					file := &parser.File{Path: path.Dir(name), FileName: path.Base(name)}
+					got, err := format.Source([]byte(contents), file)
+					require.NoError(t, err, "example %d: %s", i, name)
+
+					again, err := format.Source(got, file)
+					require.NoError(t, err, "example %d: %s", i, name)
+					require.Equal(t, string(got), string(again), "example %d: formatting %s is not idempotent", i, name)
+
+					formatted.Files[name] = string(got)
+				}
+
+				require.Empty(t, buildExample(t, formatted), "formatted example %d reports diagnostics", i)
+			}
+		})
+	}
+}
@@ -22,22 +22,38 @@ func TestBuilder_Build(t *testing.T) {
 	snapshot := builder.Snapshot()
 	want := &definition.NexemaSnapshot{
 		Version:  1,
-		Hashcode: "10820260093162658565",
+		Hashcode: "6310334532366012536",
+		Packages: []definition.NexemaPackage{
+			{
+				Id:            "7716715097846811050",
+				Name:          "foo",
+				Path:          "foo",
+				Documentation: []string{"Package foo contains the types used to test the builder."},
+				Annotations:   definition.Assignments{"version": int64(1)},
+				Files:         []string{"9342573490249171227"},
+			},
+		},
 		Files: []definition.NexemaFile{
 			{
 				FileName:    "sample.nex",
 				PackageName: "foo",
 				Path:        "foo",
-				Id:          "14449249460196398142",
+				Id:          "9342573490249171227",
 				Types: []definition.TypeDefinition{
 					{
-						Name:     "Sample",
+						Id:            "6108176246570064049",
+						Name:          "Sample",
+						Documentation: []string{"Sample is a type identified by [Sample.id]."},
+						Links: []definition.DocumentationLink{
+							{Text: "Sample.id", TypeId: "6108176246570064049", Field: "id"},
+						},
 						Modifier: token.Struct,
 						Fields: []*definition.FieldDefinition{
 							{
-								Name:  "id",
-								Index: 0,
-								Type:  definition.PrimitiveValueType{Primitive: definition.String},
+								Name:          "id",
+								Index:         0,
+								Type:          definition.PrimitiveValueType{Primitive: definition.String},
+								Documentation: []string{"The id of the sample"},
 							},
 							{
 								Name:  "name",
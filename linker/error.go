@@ -1,17 +1,19 @@
 package linker
 
 import (
-	"errors"
 	"fmt"
 	"strings"
 
+	"tomasweigenast.com/nexema/tool/diagnostic"
 	"tomasweigenast.com/nexema/tool/parser"
 	"tomasweigenast.com/nexema/tool/tokenizer"
 )
 
 type LinkerError struct {
-	At   tokenizer.Pos
-	Kind LinkerErrorKind
+	At    tokenizer.Pos
+	Kind  LinkerErrorKind
+	File  *parser.File      // the file where the error was found, if known
+	Notes []diagnostic.Note // related locations, in the same or in other files
 }
 
 type LinkerErrorKind interface {
@@ -26,17 +28,21 @@ type (
 	ErrSelfImport struct{}
 
 	ErrPackageNotFound struct {
-		Name string
+		Name       string
+		Suggestion string // the path of a known package with a similar name, if any
 	}
 
 	ErrCircularDependency struct {
-		Src  *parser.File
-		Dest *parser.File
+		Chain []*parser.File // the files that import the next one, ending with the first one again
 	}
 
 	ErrAliasAlreadyDefined struct {
 		Alias string
 	}
+
+	ErrUnresolvedDocLink struct {
+		Reference string
+	}
 )
 
 func (e ErrAlreadyDefined) Message() string {
@@ -48,19 +54,86 @@ func (ErrSelfImport) Message() string {
 }
 
 func (e ErrPackageNotFound) Message() string {
+	if len(e.Suggestion) > 0 {
+		return fmt.Sprintf("package %q not found, did you mean %q?", e.Name, e.Suggestion)
+	}
+
 	return fmt.Sprintf("package %q not found", e.Name)
 }
 
 func (e ErrCircularDependency) Message() string {
-	return fmt.Sprintf("%q imports %q package which generates a not allowed circular dependency", e.Src.Path, e.Dest.Path)
+	files := make([]string, len(e.Chain))
+	for i, file := range e.Chain {
+		files[i] = file.SourcePath()
+	}
+
+	return fmt.Sprintf("circular dependency not allowed: %s", strings.Join(files, " -> "))
 }
 
 func (e ErrAliasAlreadyDefined) Message() string {
 	return fmt.Sprintf("alias %q already defined", e.Alias)
 }
 
+func (e ErrUnresolvedDocLink) Message() string {
+	return fmt.Sprintf("documentation references [%s], which is not a known type or field", e.Reference)
+}
+
 func NewLinkerErr(err LinkerErrorKind, at tokenizer.Pos) *LinkerError {
-	return &LinkerError{at, err}
+	return &LinkerError{At: at, Kind: err}
+}
+
+// in sets the file where the error was found
+func (self *LinkerError) in(file *parser.File) *LinkerError {
+	self.File = file
+	return self
+}
+
+// Code returns the code that identifies the kind of the error in diagnostics
+func (self *LinkerError) Code() string {
+	switch self.Kind.(type) {
+	case ErrAlreadyDefined:
+		return "NX0201"
+	case ErrSelfImport:
+		return "NX0202"
+	case ErrPackageNotFound:
+		return "NX0203"
+	case ErrCircularDependency:
+		return "NX0204"
+	case ErrAliasAlreadyDefined:
+		return "NX0205"
+	case ErrUnresolvedDocLink:
+		return "NX0206"
+	}
+
+	return "NX0200"
+}
+
+// Severity returns diagnostic.Warning for unresolved documentation links, which do not stop the build,
+// and diagnostic.Error for anything else
+func (self *LinkerError) Severity() diagnostic.Severity {
+	if _, ok := self.Kind.(ErrUnresolvedDocLink); ok {
+		return diagnostic.Warning
+	}
+
+	return diagnostic.Error
+}
+
+// note adds a related location, in the same file as the error
+func (self *LinkerError) note(message string, at tokenizer.Pos) *LinkerError {
+	return self.noteIn(self.File, message, at)
+}
+
+// noteIn adds a related location in another file
+func (self *LinkerError) noteIn(file *parser.File, message string, at tokenizer.Pos) *LinkerError {
+	self.Notes = append(self.Notes, diagnostic.Note{File: file.SourcePath(), At: at, Message: message})
+	return self
+}
+
+// Diagnostic converts the error to a diagnostic.Diagnostic
+func (self *LinkerError) Diagnostic() *diagnostic.Diagnostic {
+	out := diagnostic.New(self.Severity(), self.Code(), self.Kind.Message(), self.File.SourcePath(), self.At)
+	out.Notes = self.Notes
+	return out
 }
 
 type LinkerErrorCollection []*LinkerError
@@ -81,12 +154,23 @@ func (self *LinkerErrorCollection) IsEmpty() bool {
 func (self *LinkerErrorCollection) Display() string {
 	out := make([]string, len(*self))
 	for i, err := range *self {
-		out[i] = fmt.Sprintf("%d:%d -> %s", err.At.Line, err.At.Start, err.Kind.Message())
+		out[i] = fmt.Sprintf("%d:%d -> %s", err.At.Line, err.At.Column, err.Kind.Message())
 	}
 
 	return strings.Join(out, "\n")
 }
 
+// Diagnostics converts every error in the collection to a diagnostic.Diagnostic
+func (self *LinkerErrorCollection) Diagnostics() diagnostic.Diagnostics {
+	out := make(diagnostic.Diagnostics, len(*self))
+	for i, err := range *self {
+		out[i] = err.Diagnostic()
+	}
+
+	return out
+}
+
+// AsError returns the errors in the collection as diagnostic.Diagnostics
 func (self *LinkerErrorCollection) AsError() error {
-	return errors.New(self.Display())
+	return self.Diagnostics()
 }
@@ -0,0 +1,30 @@
+package linker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"tomasweigenast.com/nexema/tool/diagnostic"
+	"tomasweigenast.com/nexema/tool/tokenizer"
+)
+
+// Codes are documented and can be suppressed, so they must never change
+func TestLinkerError_Code(t *testing.T) {
+	tests := []struct {
+		kind LinkerErrorKind
+		want string
+	}{
+		{ErrAlreadyDefined{}, "NX0201"},
+		{ErrSelfImport{}, "NX0202"},
+		{ErrPackageNotFound{}, "NX0203"},
+		{ErrCircularDependency{}, "NX0204"},
+		{ErrAliasAlreadyDefined{}, "NX0205"},
+		{ErrUnresolvedDocLink{}, "NX0206"},
+	}
+
+	for _, tt := range tests {
+		code := NewLinkerErr(tt.kind, tokenizer.Pos{}).Code()
+		require.Equal(t, tt.want, code)
+		require.NotNil(t, diagnostic.Explain(code), "%s does not have an explanation", code)
+	}
+}
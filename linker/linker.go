@@ -1,9 +1,11 @@
 package linker
 
 import (
+	"fmt"
+	"sort"
+
 	"tomasweigenast.com/nexema/tool/parser"
 	"tomasweigenast.com/nexema/tool/scope"
-	"tomasweigenast.com/nexema/tool/tokenizer"
 	"tomasweigenast.com/nexema/tool/utils"
 )
 
@@ -12,17 +14,21 @@ import (
 // - Struct names are not duplicated in the current LocalScope
 // - Imports points to valid packages
 // - Imported types are valid and names does not collide
+//
+// Also, it reports as warnings cross-references in documentation that cannot be resolved.
 type Linker struct {
-	src    *parser.ParseTree
-	scopes []*scope.Scope
-	errors *LinkerErrorCollection
+	src      *parser.ParseTree
+	scopes   []*scope.Scope
+	errors   *LinkerErrorCollection
+	warnings *LinkerErrorCollection
 }
 
 func NewLinker(parseTree *parser.ParseTree) *Linker {
 	return &Linker{
-		src:    parseTree,
-		scopes: make([]*scope.Scope, 0),
-		errors: newLinkerErrorCollection(),
+		src:      parseTree,
+		scopes:   make([]*scope.Scope, 0),
+		errors:   newLinkerErrorCollection(),
+		warnings: newLinkerErrorCollection(),
 	}
 }
 
@@ -38,11 +44,45 @@ func (self *Linker) Errors() *LinkerErrorCollection {
 	return self.errors
 }
 
+func (self *Linker) Warnings() *LinkerErrorCollection {
+	return self.warnings
+}
+
 func (self *Linker) Link() {
 	self.buildScopes()
 	self.resolveImports()
 	self.verifyCircularDependencies()
 	self.verifyObjects()
+	self.verifyDocLinks()
+}
+
+// verifyDocLinks reports a warning for every cross-reference in documentation that does not point to a visible type or field
+func (self *Linker) verifyDocLinks() {
+	for _, s := range self.scopes {
+		for _, ls := range *s.LocalScopes() {
+			objects := *ls.Objects()
+			names := make([]string, 0, len(objects))
+			for name := range objects {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			links := parser.FindDocLinks(ls.Documentation)
+			for _, name := range names {
+				obj := objects[name]
+				links = append(links, parser.FindDocLinks(obj.Source().Documentation)...)
+				for _, field := range obj.Source().Fields {
+					links = append(links, parser.FindDocLinks(field.Documentation)...)
+				}
+			}
+
+			for _, link := range links {
+				if _, _, ok := ls.ResolveReference(link.Text); !ok {
+					self.warnings.push(NewLinkerErr(ErrUnresolvedDocLink{link.Text}, link.Pos).in(ls.File()))
+				}
+			}
+		}
+	}
 }
 
 // verifyObjects checks if there are no type names which can collide with imported ones, or between them
@@ -54,10 +94,10 @@ func (self *Linker) verifyObjects() {
 			// verify objects between imports
 			for resolvedScope, imp := range *ls.ResolvedScopes() {
 				for _, obj := range resolvedScope.GetAllObjects() {
-					if _, ok := m[obj.Name]; ok {
+					if previous, ok := m[obj.Name]; ok {
 						// found another object and this import does not has an alias
 						if !imp.HasAlias() {
-							self.errors.push(NewLinkerErr(ErrAlreadyDefined{obj.Name}, imp.Source().Path.Pos))
+							self.errors.push(NewLinkerErr(ErrAlreadyDefined{obj.Name}, imp.Source().Path.Pos).in(ls.File()).note("previously imported here", previous.Source().Path.Pos))
 							continue
 						}
 					}
@@ -68,11 +108,11 @@ func (self *Linker) verifyObjects() {
 
 			// verify local objects against imports
 			// objects are not verified against other in local scope because they are already verified at discover stage
-			for objName := range *ls.Objects() {
+			for objName, obj := range *ls.Objects() {
 				if imp, ok := m[objName]; ok {
 					// if the imported object does not have an alias, report error
 					if !imp.HasAlias() {
-						self.errors.push(NewLinkerErr(ErrAlreadyDefined{objName}, imp.Source().Path.Pos))
+						self.errors.push(NewLinkerErr(ErrAlreadyDefined{objName}, imp.Source().Path.Pos).in(ls.File()).note("defined here", obj.Source().Name.Pos))
 						continue
 					}
 				}
@@ -81,61 +121,105 @@ func (self *Linker) verifyObjects() {
 	}
 }
 
+// verifyCircularDependencies reports every import cycle between packages, once
 func (self *Linker) verifyCircularDependencies() {
-	graph := map[*scope.Scope][]*scope.Scope{}
+	scopes := make([]*scope.Scope, len(self.scopes))
+	copy(scopes, self.scopes)
+	sort.Slice(scopes, func(i, j int) bool {
+		return scopes[i].Path() < scopes[j].Path()
+	})
 
-	// for each scope, add edges to the graph from the current scope
-	for _, s := range self.scopes {
-		graph[s] = make([]*scope.Scope, 0)
+	index := make(map[*scope.Scope]int, len(scopes))
+	for i, s := range scopes {
+		index[s] = i
+	}
+
+	// for each scope, add an edge to every scope it imports, sorted by path
+	graph := make(map[*scope.Scope][]*scope.Scope, len(scopes))
+	for _, s := range scopes {
+		edges := make([]*scope.Scope, 0)
 		for _, localScope := range *s.LocalScopes() {
 			for resolvedScope := range *localScope.ResolvedScopes() {
-				graph[s] = append(graph[s], resolvedScope)
+				if !utils.Contains(&edges, resolvedScope) {
+					edges = append(edges, resolvedScope)
+				}
 			}
 		}
+
+		sort.Slice(edges, func(i, j int) bool {
+			return index[edges[i]] < index[edges[j]]
+		})
+		graph[s] = edges
 	}
 
-	// perform a topological sort on the graph
-	visited := map[*scope.Scope]bool{}
-	stack := []*scope.Scope{}
+	// a cycle is only looked up from its scope with the lowest path, walking through scopes with a greater one,
+	// so it is found once
+	for _, start := range scopes {
+		path := []*scope.Scope{start}
+		self.findCycles(&path, graph, index)
+	}
+}
 
-	for _, scope := range self.scopes {
-		if _, ok := visited[scope]; !ok {
-			src, dest, hasCircular := self.hasCircularDependencies(scope, &graph, &visited, &stack)
-			if hasCircular {
-				self.errors.push(NewLinkerErr(ErrCircularDependency{src.File(), dest.File()}, *tokenizer.NewPos()))
-			}
+// findCycles reports every cycle that goes back to the first scope of path after walking through it, without
+// visiting scopes with a lower path than the first one nor twice the same scope
+func (self *Linker) findCycles(path *[]*scope.Scope, graph map[*scope.Scope][]*scope.Scope, index map[*scope.Scope]int) {
+	start := (*path)[0]
+	node := (*path)[len(*path)-1]
+
+	for _, neighbor := range graph[node] {
+		if neighbor == start {
+			self.reportCycle(*path)
+			continue
+		}
+
+		if index[neighbor] < index[start] || utils.Contains(path, neighbor) {
+			continue
 		}
+
+		*path = append(*path, neighbor)
+		self.findCycles(path, graph, index)
+		*path = (*path)[:len(*path)-1]
 	}
 }
 
-func (self *Linker) hasCircularDependencies(node *scope.Scope, graph *map[*scope.Scope][]*scope.Scope, visited *map[*scope.Scope]bool, stack *[]*scope.Scope) (*scope.LocalScope, *scope.LocalScope, bool) {
-	(*visited)[node] = true
-	*stack = append(*stack, node)
+// reportCycle reports the import cycle that goes through packages, in order, and back to the first one.
+// For every package, the file with the lowest path that imports the next one is used, so the report
+// does not depend on the order in which files were read
+func (self *Linker) reportCycle(packages []*scope.Scope) {
+	chain := make([]*parser.File, len(packages), len(packages)+1)
+	imports := make([]*scope.Import, len(packages))
+	for i, pkg := range packages {
+		next := packages[(i+1)%len(packages)]
+		for _, localScope := range *pkg.LocalScopes() {
+			imp, ok := (*localScope.ResolvedScopes())[next]
+			if !ok {
+				continue
+			}
 
-	for _, neighbor := range (*graph)[node] {
-		if _, ok := (*visited)[neighbor]; !ok {
-			src, dest, hasCircular := self.hasCircularDependencies(neighbor, graph, visited, stack)
-			if hasCircular {
-				return src, dest, true
+			if chain[i] == nil || importsBefore(localScope.File(), imp, chain[i], imports[i]) {
+				chain[i] = localScope.File()
+				imports[i] = imp
 			}
-		} else if utils.Contains(stack, neighbor) {
-			scope1 := utils.Find(node.LocalScopes(), func(t **scope.LocalScope) bool {
-				_, ok := (*(*t).ResolvedScopes())[neighbor]
-				return ok
-			})
-
-			scope2 := utils.Find(neighbor.LocalScopes(), func(t **scope.LocalScope) bool {
-				_, ok := (*(*t).ResolvedScopes())[node]
-				return ok
-			})
-
-			return *scope1, *scope2, true
 		}
 	}
+	chain = append(chain, chain[0])
 
-	*stack = (*stack)[:len(*stack)-1]
+	err := NewLinkerErr(ErrCircularDependency{chain}, imports[0].Source().Path.Pos).in(chain[0])
+	for i := 1; i < len(imports); i++ {
+		err.noteIn(chain[i], fmt.Sprintf("imports %q", imports[i].Path), imports[i].Source().Path.Pos)
+	}
 
-	return nil, nil, false
+	self.errors.push(err)
+}
+
+// importsBefore reports whether the import imp of file sorts before the import other of otherFile,
+// by source path and then by position
+func importsBefore(file *parser.File, imp *scope.Import, otherFile *parser.File, other *scope.Import) bool {
+	if file.SourcePath() != otherFile.SourcePath() {
+		return file.SourcePath() < otherFile.SourcePath()
+	}
+
+	return imp.Source().Path.Pos.Start < other.Source().Path.Pos.Start
 }
 
 // resolveImports resolves an use statement for every LocalScope
@@ -148,7 +232,7 @@ func (self *Linker) resolveImports() {
 				// alias already defined
 				if imp.HasAlias() {
 					if _, ok := aliases[imp.Alias]; ok {
-						self.errors.push(NewLinkerErr(ErrAliasAlreadyDefined{imp.Alias}, imp.Source().Alias.Pos))
+						self.errors.push(NewLinkerErr(ErrAliasAlreadyDefined{imp.Alias}, imp.Source().Alias.Pos).in(localScope.File()))
 						continue
 					}
 
@@ -158,14 +242,15 @@ func (self *Linker) resolveImports() {
 				// check if impPath is not equal to pkgScope.Path
 				// it would be a self import
 				if impPath == pkgScope.Path() {
-					self.errors.push(NewLinkerErr(ErrSelfImport{}, imp.Source().Path.Pos))
+					self.errors.push(NewLinkerErr(ErrSelfImport{}, imp.Source().Path.Pos).in(localScope.File()))
 					continue
 				}
 
 				// find scope
 				resolvedScope := self.findScope(impPath)
 				if resolvedScope == nil {
-					self.errors.push(NewLinkerErr(ErrPackageNotFound{impPath}, imp.Source().Path.Pos))
+					err := ErrPackageNotFound{Name: impPath, Suggestion: self.suggestScope(impPath, pkgScope)}
+					self.errors.push(NewLinkerErr(err, imp.Source().Path.Pos).in(localScope.File()))
 					continue
 				}
 
@@ -185,6 +270,20 @@ func (self *Linker) findScope(path string) *scope.Scope {
 	return nil
 }
 
+// suggestScope returns the path of the package, other than from, whose path is the closest to path, or an empty
+// string if none of them is close enough
+func (self *Linker) suggestScope(path string, from *scope.Scope) string {
+	candidates := make([]string, 0, len(self.scopes))
+	for _, scopePkg := range self.scopes {
+		if scopePkg != from && len(*scopePkg.LocalScopes()) > 0 {
+			candidates = append(candidates, scopePkg.Path())
+		}
+	}
+
+	suggestion, _, _ := utils.Closest(path, candidates)
+	return suggestion
+}
+
 func (self *Linker) buildScopes() {
 	self.src.Root().Iter(func(pkgName string, node *parser.ParseNode) {
 		self.createScope(pkgName, node)
@@ -199,24 +298,28 @@ func (self *Linker) createScope(packageName string, node *parser.ParseNode) {
 		objects := make(map[string]*scope.Object)
 
 		// validate "use" statements
-		for _, stmt := range ast.UseStatements {
-			imp := scope.NewImport(&stmt)
+		for i := range ast.UseStatements {
+			imp := scope.NewImport(&ast.UseStatements[i])
 			imports[imp.Path] = imp
 		}
 
 		// push types
-		for _, stmt := range ast.TypeStatements {
-			obj := scope.NewObject(&stmt)
+		for i := range ast.TypeStatements {
+			obj := scope.NewObject(&ast.TypeStatements[i])
 
-			if _, ok := objects[obj.Name]; ok {
-				self.errors.push(NewLinkerErr(ErrAlreadyDefined{obj.Name}, obj.Source().Name.Pos))
+			if previous, ok := objects[obj.Name]; ok {
+				self.errors.push(NewLinkerErr(ErrAlreadyDefined{obj.Name}, obj.Source().Name.Pos).in(ast.File).note("previously defined here", previous.Source().Name.Pos))
 				continue
 			}
 
 			objects[obj.Name] = obj
 		}
 
-		newScope.PushLocalScope(scope.NewLocalScope(ast.File, imports, objects))
+		localScope := scope.NewLocalScope(ast.File, imports, objects)
+		localScope.Documentation = ast.Documentation
+		localScope.Annotations = ast.Annotations
+
+		newScope.PushLocalScope(localScope)
 	}
 
 	self.scopes = append(self.scopes, newScope)
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"tomasweigenast.com/nexema/tool/diagnostic"
 	"tomasweigenast.com/nexema/tool/parser"
 	"tomasweigenast.com/nexema/tool/token"
 	"tomasweigenast.com/nexema/tool/tokenizer"
@@ -35,7 +36,7 @@ func TestLinker_Link(t *testing.T) {
 				return tree
 			},
 			wantErrs: LinkerErrorCollection{
-				NewLinkerErr(ErrSelfImport{}, *tokenizer.NewPos(0, 0)),
+				NewLinkerErr(ErrSelfImport{}, *tokenizer.NewPos()).in(&parser.File{Path: "common", FileName: "address.nex"}),
 			},
 		},
 		{
@@ -48,9 +49,125 @@ func TestLinker_Link(t *testing.T) {
 			},
 			wantErrs: LinkerErrorCollection{
 				NewLinkerErr(ErrCircularDependency{
-					Src:  &parser.File{Path: "identity/user", FileName: "user.nex"},
-					Dest: &parser.File{Path: "common", FileName: "address.nex"},
-				}, *tokenizer.NewPos(0, 0)),
+					Chain: []*parser.File{
+						{Path: "common", FileName: "address.nex"},
+						{Path: "identity/user", FileName: "user.nex"},
+						{Path: "common", FileName: "address.nex"},
+					},
+				}, *tokenizer.NewPos()).
+					in(&parser.File{Path: "common", FileName: "address.nex"}).
+					noteIn(&parser.File{Path: "identity/user", FileName: "user.nex"}, `imports "common"`, *tokenizer.NewPos()),
+			},
+		},
+		{
+			name: "circular dependency imported by several files of a package",
+			input: func() *parser.ParseTree {
+				tree := parser.NewParseTree()
+				tree.Insert("a", newAst("a/z.nex", []string{"Z"}, []string{"b"}))
+				tree.Insert("a", newAst("a/m.nex", []string{"M"}, []string{"b"}))
+				tree.Insert("b", newAst("b/b.nex", []string{"B"}, []string{"a"}))
+				return tree
+			},
+			wantErrs: LinkerErrorCollection{
+				NewLinkerErr(ErrCircularDependency{
+					Chain: []*parser.File{
+						{Path: "a", FileName: "m.nex"},
+						{Path: "b", FileName: "b.nex"},
+						{Path: "a", FileName: "m.nex"},
+					},
+				}, *tokenizer.NewPos()).
+					in(&parser.File{Path: "a", FileName: "m.nex"}).
+					noteIn(&parser.File{Path: "b", FileName: "b.nex"}, `imports "a"`, *tokenizer.NewPos()),
+			},
+		},
+		{
+			name: "circular dependency of three packages",
+			input: func() *parser.ParseTree {
+				tree := parser.NewParseTree()
+				tree.Insert("a", newAst("a/a.nex", []string{"A"}, []string{"b"}))
+				tree.Insert("b", newAst("b/x.nex", []string{"X"}, []string{"c"}))
+				tree.Insert("c", newAst("c/y.nex", []string{"Y"}, []string{"a"}))
+				return tree
+			},
+			wantErrs: LinkerErrorCollection{
+				NewLinkerErr(ErrCircularDependency{
+					Chain: []*parser.File{
+						{Path: "a", FileName: "a.nex"},
+						{Path: "b", FileName: "x.nex"},
+						{Path: "c", FileName: "y.nex"},
+						{Path: "a", FileName: "a.nex"},
+					},
+				}, *tokenizer.NewPos()).
+					in(&parser.File{Path: "a", FileName: "a.nex"}).
+					noteIn(&parser.File{Path: "b", FileName: "x.nex"}, `imports "c"`, *tokenizer.NewPos()).
+					noteIn(&parser.File{Path: "c", FileName: "y.nex"}, `imports "a"`, *tokenizer.NewPos()),
+			},
+		},
+		{
+			name: "circular dependency of four packages starting from any of them",
+			input: func() *parser.ParseTree {
+				tree := parser.NewParseTree()
+				tree.Insert("d", newAst("d/d.nex", []string{"D"}, []string{"b"}))
+				tree.Insert("b", newAst("b/b.nex", []string{"B"}, []string{"c"}))
+				tree.Insert("c", newAst("c/c.nex", []string{"C"}, []string{"a"}))
+				tree.Insert("a", newAst("a/a.nex", []string{"A"}, []string{"d"}))
+				return tree
+			},
+			wantErrs: LinkerErrorCollection{
+				NewLinkerErr(ErrCircularDependency{
+					Chain: []*parser.File{
+						{Path: "a", FileName: "a.nex"},
+						{Path: "d", FileName: "d.nex"},
+						{Path: "b", FileName: "b.nex"},
+						{Path: "c", FileName: "c.nex"},
+						{Path: "a", FileName: "a.nex"},
+					},
+				}, *tokenizer.NewPos()).
+					in(&parser.File{Path: "a", FileName: "a.nex"}).
+					noteIn(&parser.File{Path: "d", FileName: "d.nex"}, `imports "b"`, *tokenizer.NewPos()).
+					noteIn(&parser.File{Path: "b", FileName: "b.nex"}, `imports "c"`, *tokenizer.NewPos()).
+					noteIn(&parser.File{Path: "c", FileName: "c.nex"}, `imports "a"`, *tokenizer.NewPos()),
+			},
+		},
+		{
+			name: "every distinct circular dependency is reported",
+			input: func() *parser.ParseTree {
+				tree := parser.NewParseTree()
+				tree.Insert("a", newAst("a/a.nex", []string{"A"}, []string{"b", "c"}))
+				tree.Insert("b", newAst("b/b.nex", []string{"B"}, []string{"a", "c"}))
+				tree.Insert("c", newAst("c/c.nex", []string{"C"}, []string{"a"}))
+				return tree
+			},
+			wantErrs: LinkerErrorCollection{
+				NewLinkerErr(ErrCircularDependency{
+					Chain: []*parser.File{
+						{Path: "a", FileName: "a.nex"},
+						{Path: "b", FileName: "b.nex"},
+						{Path: "a", FileName: "a.nex"},
+					},
+				}, *tokenizer.NewPos()).
+					in(&parser.File{Path: "a", FileName: "a.nex"}).
+					noteIn(&parser.File{Path: "b", FileName: "b.nex"}, `imports "a"`, *tokenizer.NewPos()),
+				NewLinkerErr(ErrCircularDependency{
+					Chain: []*parser.File{
+						{Path: "a", FileName: "a.nex"},
+						{Path: "b", FileName: "b.nex"},
+						{Path: "c", FileName: "c.nex"},
+						{Path: "a", FileName: "a.nex"},
+					},
+				}, *tokenizer.NewPos()).
+					in(&parser.File{Path: "a", FileName: "a.nex"}).
+					noteIn(&parser.File{Path: "b", FileName: "b.nex"}, `imports "c"`, *tokenizer.NewPos()).
+					noteIn(&parser.File{Path: "c", FileName: "c.nex"}, `imports "a"`, *tokenizer.NewPos()),
+				NewLinkerErr(ErrCircularDependency{
+					Chain: []*parser.File{
+						{Path: "a", FileName: "a.nex"},
+						{Path: "c", FileName: "c.nex"},
+						{Path: "a", FileName: "a.nex"},
+					},
+				}, *tokenizer.NewPos()).
+					in(&parser.File{Path: "a", FileName: "a.nex"}).
+					noteIn(&parser.File{Path: "c", FileName: "c.nex"}, `imports "a"`, *tokenizer.NewPos()),
 			},
 		},
 		{
@@ -63,7 +180,7 @@ func TestLinker_Link(t *testing.T) {
 			wantErrs: LinkerErrorCollection{
 				NewLinkerErr(ErrAlreadyDefined{
 					Name: "Address",
-				}, *tokenizer.NewPos(0, 0)),
+				}, *tokenizer.NewPos()).in(&parser.File{Path: "common", FileName: "address.nex"}).note("previously defined here", *tokenizer.NewPos()),
 			},
 		},
 		{
@@ -77,7 +194,7 @@ func TestLinker_Link(t *testing.T) {
 			wantErrs: LinkerErrorCollection{
 				NewLinkerErr(ErrAlreadyDefined{
 					Name: "Address",
-				}, *tokenizer.NewPos(0, 0)),
+				}, *tokenizer.NewPos()).in(&parser.File{Path: "common", FileName: "address.nex"}).note("defined here", *tokenizer.NewPos()),
 			},
 		},
 		{
@@ -112,7 +229,7 @@ func TestLinker_Link(t *testing.T) {
 			wantErrs: LinkerErrorCollection{
 				NewLinkerErr(ErrAlreadyDefined{
 					Name: "Address",
-				}, *tokenizer.NewPos(0, 0)),
+				}, *tokenizer.NewPos()).in(&parser.File{Path: "foo", FileName: "bar.nex"}).note("previously imported here", *tokenizer.NewPos()),
 			},
 		},
 		{
@@ -127,7 +244,7 @@ func TestLinker_Link(t *testing.T) {
 			wantErrs: LinkerErrorCollection{
 				NewLinkerErr(ErrAliasAlreadyDefined{
 					Alias: "foo",
-				}, *tokenizer.NewPos(0, 0)),
+				}, *tokenizer.NewPos()).in(&parser.File{Path: "common", FileName: "address.nex"}),
 			},
 		},
 		{
@@ -140,7 +257,22 @@ func TestLinker_Link(t *testing.T) {
 			wantErrs: LinkerErrorCollection{
 				NewLinkerErr(ErrPackageNotFound{
 					Name: "identity",
-				}, *tokenizer.NewPos(0, 0)),
+				}, *tokenizer.NewPos()).in(&parser.File{Path: "common", FileName: "address.nex"}),
+			},
+		},
+		{
+			name: "package not found suggests similar package",
+			input: func() *parser.ParseTree {
+				tree := parser.NewParseTree()
+				tree.Insert("common", newAst("common/address.nex", []string{"Address"}, []string{}))
+				tree.Insert("identity", newAst("identity/user.nex", []string{"User"}, []string{"comon"}))
+				return tree
+			},
+			wantErrs: LinkerErrorCollection{
+				NewLinkerErr(ErrPackageNotFound{
+					Name:       "comon",
+					Suggestion: "common",
+				}, *tokenizer.NewPos()).in(&parser.File{Path: "identity", FileName: "user.nex"}),
 			},
 		},
 	}
@@ -159,6 +291,69 @@ func TestLinker_Link(t *testing.T) {
 	}
 }
 
+func TestLinker_Warnings(t *testing.T) {
+	docs := func(text string) []parser.CommentStmt {
+		return []parser.CommentStmt{{Token: *token.NewToken(token.DocComment, text), Pos: *tokenizer.NewPos(0, len(text)+3)}}
+	}
+
+	tests := []struct {
+		name         string
+		input        func() *parser.ParseTree
+		wantWarnings LinkerErrorCollection
+	}{
+		{
+			name: "resolved links",
+			input: func() *parser.ParseTree {
+				tree := parser.NewParseTree()
+				ast := newAst("common/address.nex", []string{"Address", "Coordinates"}, []string{"identity/user:user"})
+				ast.TypeStatements[0].Documentation = docs(" Has [Coordinates] and belongs to [user.User.name]")
+				ast.TypeStatements[1].Fields = []parser.FieldStmt{
+					{Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "latitude")}, Documentation: docs(" See [Coordinates.latitude]")},
+				}
+				tree.Insert("common", ast)
+
+				userAst := newAst("identity/user/user.nex", []string{"User"}, []string{})
+				userAst.TypeStatements[0].Fields = []parser.FieldStmt{
+					{Name: parser.IdentStmt{Token: *token.NewToken(token.Ident, "name")}},
+				}
+				tree.Insert("identity/user", userAst)
+				return tree
+			},
+			wantWarnings: nil,
+		},
+		{
+			name: "unresolved links",
+			input: func() *parser.ParseTree {
+				tree := parser.NewParseTree()
+				ast := newAst("common/address.nex", []string{"Address"}, []string{})
+				ast.Documentation = docs(" Package common uses [Unknown]")
+				ast.TypeStatements[0].Documentation = docs(" See [Address.street] and [other.Address]")
+				tree.Insert("common", ast)
+				return tree
+			},
+			wantWarnings: LinkerErrorCollection{
+				NewLinkerErr(ErrUnresolvedDocLink{"Unknown"}, *tokenizer.NewPos(24, 33)).in(&parser.File{Path: "common", FileName: "address.nex"}),
+				NewLinkerErr(ErrUnresolvedDocLink{"Address.street"}, *tokenizer.NewPos(8, 24)).in(&parser.File{Path: "common", FileName: "address.nex"}),
+				NewLinkerErr(ErrUnresolvedDocLink{"other.Address"}, *tokenizer.NewPos(29, 44)).in(&parser.File{Path: "common", FileName: "address.nex"}),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			linker := NewLinker(test.input())
+			linker.Link()
+
+			require.Empty(t, *linker.errors)
+			if test.wantWarnings == nil {
+				require.Empty(t, *linker.Warnings())
+			} else {
+				require.Equal(t, test.wantWarnings, *linker.Warnings())
+			}
+		})
+	}
+}
+
 func newAst(fileName string, typeNames []string, uses []string) *parser.Ast {
 	useStmts := []parser.UseStmt{}
 	types := []parser.TypeStmt{}
@@ -193,3 +388,35 @@ func newAst(fileName string, typeNames []string, uses []string) *parser.Ast {
 		TypeStatements: types,
 	}
 }
+
+func TestLinker_Diagnostics(t *testing.T) {
+	tree := parser.NewParseTree()
+	common := newAst("common/address.nex", []string{"Address"}, []string{"identity/user"})
+	common.Documentation = []parser.CommentStmt{{Token: *token.NewToken(token.DocComment, " See [Unknown]"), Pos: *tokenizer.NewPos(0, 17)}}
+	common.UseStatements[0].Path.Pos = *tokenizer.NewPos(4, 19)
+	tree.Insert("common", common)
+
+	user := newAst("identity/user/user.nex", []string{"User"}, []string{"common"})
+	user.UseStatements[0].Path.Pos = *tokenizer.NewPos(4, 12)
+	tree.Insert("identity/user", user)
+
+	linker := NewLinker(tree)
+	linker.Link()
+
+	require.Equal(t, diagnostic.Diagnostics{
+		{
+			File:     "common/address.nex",
+			At:       *tokenizer.NewPos(4, 19),
+			Severity: diagnostic.Error,
+			Code:     "NX0204",
+			Message:  "circular dependency not allowed: common/address.nex -> identity/user/user.nex -> common/address.nex",
+			Notes: []diagnostic.Note{
+				{File: "identity/user/user.nex", At: *tokenizer.NewPos(4, 12), Message: `imports "common"`},
+			},
+		},
+	}, linker.Errors().Diagnostics())
+
+	require.Equal(t, diagnostic.Diagnostics{
+		diagnostic.New(diagnostic.Warning, "NX0206", "documentation references [Unknown], which is not a known type or field", "common/address.nex", *tokenizer.NewPos(8, 17)),
+	}, linker.Warnings().Diagnostics())
+}
@@ -0,0 +1,183 @@
+package lint
+
+import (
+	"fmt"
+	"unicode"
+
+	"tomasweigenast.com/nexema/tool/definition"
+	"tomasweigenast.com/nexema/tool/diagnostic"
+	"tomasweigenast.com/nexema/tool/parser"
+	"tomasweigenast.com/nexema/tool/scope"
+	"tomasweigenast.com/nexema/tool/token"
+	"tomasweigenast.com/nexema/tool/utils"
+)
+
+// unknownMember is the name of the member enums are expected to declare with index 0
+const unknownMember = "unknown"
+
+// rootAnnotation marks a type that is used on its own, like a message or a request, instead of being referenced
+// by other types
+const rootAnnotation = "root"
+
+// checkTypeNaming reports types whose name is not PascalCase
+func checkTypeNaming(linter *Linter, localScope *scope.LocalScope) {
+	for _, obj := range *localScope.Objects() {
+		stmt := obj.Source()
+		name := stmt.Name.Token.Literal
+		if !isPascalCase(name) {
+			linter.report(stmt.Name.Pos, [][]parser.AnnotationStmt{stmt.Annotations}, "type name %q should be PascalCase, like %q", name, utils.ToPascalCase(name))
+		}
+	}
+}
+
+// checkFieldNaming reports fields, enum and flags members and oneof groups whose name is not snake_case
+func checkFieldNaming(linter *Linter, localScope *scope.LocalScope) {
+	for _, obj := range *localScope.Objects() {
+		stmt := obj.Source()
+		for _, field := range stmt.Fields {
+			name := field.Name.Token.Literal
+			if !isSnakeCase(name) {
+				linter.report(field.Name.Pos, [][]parser.AnnotationStmt{stmt.Annotations, field.Annotations}, "field name %q should be snake_case, like %q", name, utils.ToSnakeCase(name))
+			}
+		}
+
+		for _, oneof := range stmt.Oneofs {
+			name := oneof.Name.Token.Literal
+			if !isSnakeCase(name) {
+				linter.report(oneof.Name.Pos, [][]parser.AnnotationStmt{stmt.Annotations, oneof.Annotations}, "oneof name %q should be snake_case, like %q", name, utils.ToSnakeCase(name))
+			}
+		}
+	}
+}
+
+// checkMissingDocs reports types without documentation
+func checkMissingDocs(linter *Linter, localScope *scope.LocalScope) {
+	for _, obj := range *localScope.Objects() {
+		stmt := obj.Source()
+		if len(stmt.Documentation) == 0 {
+			linter.report(stmt.Name.Pos, [][]parser.AnnotationStmt{stmt.Annotations}, "type %q is not documented", obj.Name)
+		}
+	}
+}
+
+// checkUnusedImports reports use statements whose package is not referenced by the file, with an edit that
+// deletes them
+func checkUnusedImports(linter *Linter, localScope *scope.LocalScope) {
+	for _, imp := range localScope.UnusedImports() {
+		pos := imp.Source().Pos
+		linter.report(pos, nil, "package %q is imported but not used", imp.Path)
+		linter.fix(diagnostic.DeleteLine(linter.source(), pos.Start, pos.End))
+	}
+}
+
+// checkUnusedTypes reports types that are not referenced by any other type nor documentation comment, unless
+// they are annotated as roots
+func checkUnusedTypes(linter *Linter, localScope *scope.LocalScope) {
+	for _, obj := range *localScope.Objects() {
+		stmt := obj.Source()
+		if isRoot(stmt) || linter.isReferenced(obj) {
+			continue
+		}
+
+		linter.report(stmt.Name.Pos, [][]parser.AnnotationStmt{stmt.Annotations}, "type %q is not referenced by any other type, annotate it with #%s = true if it is used on its own", obj.Name, rootAnnotation)
+	}
+}
+
+// checkEnumUnknownMember reports enums whose member with index 0 is not called unknown, since it is the value
+// decoders use when a value is not known
+func checkEnumUnknownMember(linter *Linter, localScope *scope.LocalScope) {
+	for _, obj := range *localScope.Objects() {
+		stmt := obj.Source()
+		typeDef := linter.typeDefinition(obj)
+		if stmt.Modifier != token.Enum || typeDef == nil {
+			continue
+		}
+
+		var zero *definition.FieldDefinition
+		for _, field := range typeDef.Fields {
+			if field.Index == 0 && len(field.AliasOf) == 0 {
+				zero = field
+				break
+			}
+		}
+
+		if zero == nil {
+			linter.report(stmt.Name.Pos, [][]parser.AnnotationStmt{stmt.Annotations}, "enum %q does not declare an %q member with index 0", obj.Name, unknownMember)
+			continue
+		}
+
+		if zero.Name != unknownMember {
+			field := findField(stmt, zero.Name)
+			linter.report(field.Name.Pos, [][]parser.AnnotationStmt{stmt.Annotations, field.Annotations}, "member %q of enum %q has index 0, which should be the %q member", zero.Name, obj.Name, unknownMember)
+		}
+	}
+}
+
+// checkImplicitIndexes reports fields and enum members that do not declare their index, since adding a field
+// before them changes it, with an edit that writes the index they have now before their name
+func checkImplicitIndexes(linter *Linter, localScope *scope.LocalScope) {
+	for _, obj := range *localScope.Objects() {
+		stmt := obj.Source()
+		typeDef := linter.typeDefinition(obj)
+		if typeDef == nil {
+			continue
+		}
+
+		for _, fieldDef := range typeDef.Fields {
+			field := findField(stmt, fieldDef.Name)
+			if field.Index != nil || field.AliasOf != nil || len(field.Combines) > 0 {
+				continue
+			}
+
+			// the members of flags declare their value instead, which is the next bit if it is not declared
+			annotations := [][]parser.AnnotationStmt{stmt.Annotations, field.Annotations}
+			if stmt.Modifier == token.Flags {
+				linter.report(field.Name.Pos, annotations, "flags member %q does not declare its value, which is %d", fieldDef.Name, fieldDef.Value)
+				linter.fix(diagnostic.Edit{Start: field.Name.Pos.Start, End: field.Name.Pos.Start, Text: fmt.Sprintf("%d ", fieldDef.Value)})
+				continue
+			}
+
+			linter.report(field.Name.Pos, annotations, "field %q does not declare its index, which is %d", fieldDef.Name, fieldDef.Index)
+			linter.fix(diagnostic.Edit{Start: field.Name.Pos.Start, End: field.Name.Pos.Start, Text: fmt.Sprintf("%d ", fieldDef.Index)})
+		}
+	}
+}
+
+// isPascalCase returns true if name does not start with a lowercase letter and does not contain underscores
+func isPascalCase(name string) bool {
+	for i, r := range name {
+		if (i == 0 && unicode.IsLower(r)) || r == '_' {
+			return false
+		}
+	}
+
+	return len(name) > 0
+}
+
+// isSnakeCase returns true if name does not contain uppercase letters, and its words are separated by a single underscore
+func isSnakeCase(name string) bool {
+	return name == utils.ToSnakeCase(name)
+}
+
+// isRoot returns true if stmt is annotated as a root type
+func isRoot(stmt *parser.TypeStmt) bool {
+	for _, annotation := range stmt.Annotations {
+		if annotation.Assigment.Left.Token.Literal == rootAnnotation {
+			value, _ := annotation.Assigment.Right.Kind.Value().(bool)
+			return value
+		}
+	}
+
+	return false
+}
+
+// findField returns the field of stmt called name
+func findField(stmt *parser.TypeStmt, name string) *parser.FieldStmt {
+	for i := range stmt.Fields {
+		if stmt.Fields[i].Name.Token.Literal == name {
+			return &stmt.Fields[i]
+		}
+	}
+
+	return nil
+}
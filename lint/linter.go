@@ -0,0 +1,155 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"tomasweigenast.com/nexema/tool/definition"
+	"tomasweigenast.com/nexema/tool/diagnostic"
+	"tomasweigenast.com/nexema/tool/parser"
+	"tomasweigenast.com/nexema/tool/scope"
+	"tomasweigenast.com/nexema/tool/tokenizer"
+)
+
+// nolintAnnotation suppresses rules for the file, type or field it is declared in. Its value is a comma separated
+// list of rule names, or true to suppress every rule:
+//
+//	#nolint = "field-naming, implicit-index"
+const nolintAnnotation = "nolint"
+
+// Linter checks the linked scopes of a project, and the snapshot built from them, against the enabled rules.
+// It must run after a successful build
+type Linter struct {
+	scopes      []*scope.Scope
+	types       map[string]*definition.TypeDefinition // the types of the snapshot, by id
+	sources     diagnostic.Sources                    // the contents of the files, to compute fixes
+	settings    Settings
+	diagnostics diagnostic.Diagnostics
+	reported    bool // true if the last call to report reported a diagnostic, so it can be fixed
+
+	ignoreNolint bool // true to report the diagnostics suppressed by nolint annotations
+
+	currRule       *Rule
+	currLocalScope *scope.LocalScope
+}
+
+func NewLinter(scopes []*scope.Scope, snapshot *definition.NexemaSnapshot, sources diagnostic.Sources, settings Settings) *Linter {
+	types := map[string]*definition.TypeDefinition{}
+	for i := range snapshot.Files {
+		for j := range snapshot.Files[i].Types {
+			typeDef := &snapshot.Files[i].Types[j]
+			types[typeDef.Id] = typeDef
+		}
+	}
+
+	return &Linter{
+		scopes:      scopes,
+		types:       types,
+		sources:     sources,
+		settings:    settings,
+		diagnostics: make(diagnostic.Diagnostics, 0),
+	}
+}
+
+// IgnoreNolint makes the linter report the diagnostics suppressed by nolint annotations too, for commands that fix
+// them on request, like nexema fix
+func (self *Linter) IgnoreNolint() *Linter {
+	self.ignoreNolint = true
+	return self
+}
+
+// Lint checks every enabled rule and returns the diagnostics they reported, sorted by file and position
+func (self *Linter) Lint() diagnostic.Diagnostics {
+	for _, rule := range rules {
+		if _, ok := self.settings[rule]; !ok {
+			continue
+		}
+
+		self.currRule = rule
+		for _, pkgScope := range self.scopes {
+			for _, localScope := range *pkgScope.LocalScopes() {
+				self.currLocalScope = localScope
+				rule.check(self, localScope)
+			}
+		}
+	}
+
+	self.diagnostics.Sort()
+	return self.diagnostics
+}
+
+// report reports a diagnostic of the current rule in the current file, unless the rule is suppressed by the
+// header of the file or by any of the given annotations, which are the ones of the type and field at.
+func (self *Linter) report(at tokenizer.Pos, annotations [][]parser.AnnotationStmt, format string, args ...any) {
+	self.reported = false
+	if !self.ignoreNolint {
+		if self.isSuppressed(self.currLocalScope.Annotations) {
+			return
+		}
+
+		for _, list := range annotations {
+			if self.isSuppressed(list) {
+				return
+			}
+		}
+	}
+
+	message := fmt.Sprintf(format, args...)
+	self.diagnostics = append(self.diagnostics, diagnostic.New(self.settings[self.currRule], self.currRule.Code, message, self.currLocalScope.File().SourcePath(), at))
+	self.reported = true
+}
+
+// fix adds edits that fix the diagnostic reported by the last call to report, if it was not suppressed
+func (self *Linter) fix(edits ...diagnostic.Edit) {
+	if self.reported {
+		last := self.diagnostics[len(self.diagnostics)-1]
+		last.Edits = append(last.Edits, edits...)
+	}
+}
+
+// source returns the contents of the current file
+func (self *Linter) source() []byte {
+	return self.sources[self.currLocalScope.File().SourcePath()]
+}
+
+// isReferenced returns true if any file references obj
+func (self *Linter) isReferenced(obj *scope.Object) bool {
+	for _, pkgScope := range self.scopes {
+		for _, localScope := range *pkgScope.LocalScopes() {
+			if localScope.IsReferenced(obj) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isSuppressed returns true if annotations contains a nolint annotation that suppresses the current rule
+func (self *Linter) isSuppressed(annotations []parser.AnnotationStmt) bool {
+	for _, annotation := range annotations {
+		if annotation.Assigment.Left.Token.Literal != nolintAnnotation {
+			continue
+		}
+
+		switch value := annotation.Assigment.Right.Kind.Value().(type) {
+		case bool:
+			if value {
+				return true
+			}
+		case string:
+			for _, name := range strings.Split(value, ",") {
+				if strings.TrimSpace(name) == self.currRule.Name {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// typeDefinition returns the definition built for obj
+func (self *Linter) typeDefinition(obj *scope.Object) *definition.TypeDefinition {
+	return self.types[obj.Id]
+}
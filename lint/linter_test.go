@@ -0,0 +1,238 @@
+package lint
+
+import (
+	"bytes"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"tomasweigenast.com/nexema/tool/analyzer"
+	"tomasweigenast.com/nexema/tool/definition"
+	"tomasweigenast.com/nexema/tool/diagnostic"
+	"tomasweigenast.com/nexema/tool/linker"
+	"tomasweigenast.com/nexema/tool/parser"
+)
+
+func TestLinter_Lint(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  map[string]string
+		config map[string]string
+		want   []string
+	}{
+		{
+			name: "valid project",
+			files: map[string]string{
+				"foo/user.nex": "use \"common\"\n\n/// A user\ntype User struct {\n\t0 first_name string\n\t1 address Address\n\t2 status Status\n}\n\ntype Status enum {\n\t0 unknown\n\t1 active\n}\n",
+				"common/a.nex": "type Address struct {\n\t0 street string\n}\n",
+			},
+			config: map[string]string{"missing-docs": "off", "implicit-index": "on"},
+		},
+		{
+			name: "type and field naming",
+			files: map[string]string{
+				"foo/user.nex": "type user_account struct {\n\tfirstName string\n\toneof Contact {\n\t\temail string\n\t}\n}\n",
+			},
+			want: []string{
+				`foo/user.nex:1:6: warning[NX0401]: type name "user_account" should be PascalCase, like "UserAccount"`,
+				`foo/user.nex:2:2: warning[NX0402]: field name "firstName" should be snake_case, like "first_name"`,
+				`foo/user.nex:3:8: warning[NX0402]: oneof name "Contact" should be snake_case, like "contact"`,
+			},
+		},
+		{
+			name: "missing docs",
+			files: map[string]string{
+				"foo/user.nex": "/// A user\ntype User struct {}\n\n// An account\ntype Account struct {}\n\ntype Admin struct {}\n",
+			},
+			config: map[string]string{"missing-docs": "error"},
+			want: []string{
+				`foo/user.nex:7:6: error[NX0403]: type "Admin" is not documented`,
+			},
+		},
+		{
+			name: "unused imports",
+			files: map[string]string{
+				"foo/user.nex": "use \"common\"\nuse \"geo\" as g\nuse \"other\"\n\ntype User struct {\n\taddresses list(g.Address)\n}\n",
+				"common/a.nex": "type Account struct {}\n",
+				"geo/a.nex":    "type Address struct {}\n",
+				"other/a.nex":  "type City struct {}\n",
+			},
+			want: []string{
+				`foo/user.nex:1:1: warning[NX0404]: package "common" is imported but not used`,
+				`foo/user.nex:3:1: warning[NX0404]: package "other" is imported but not used`,
+			},
+		},
+		{
+			name: "imports used by documentation",
+			files: map[string]string{
+				"foo/user.nex": "// See [geo.Address]\n\nuse \"geo\"\nuse \"common\"\n\ntype User struct {\n\t/// Like [Account]\n\t0 id string\n}\n",
+				"common/a.nex": "type Account struct {}\n",
+				"geo/a.nex":    "type Address struct {}\n",
+			},
+		},
+		{
+			name: "unused types",
+			files: map[string]string{
+				"foo/user.nex": "#root = true\ntype User struct {\n\t0 account Account\n\t1 id string\n}\n\ntype Account struct {}\n\n/// A [Node] of a tree\ntype Node struct {\n}\n\n#root = false\ntype Admin struct {}\n",
+			},
+			config: map[string]string{"unused-type": "on"},
+			want: []string{
+				`foo/user.nex:10:6: warning[NX0407]: type "Node" is not referenced by any other type, annotate it with #root = true if it is used on its own`,
+				`foo/user.nex:14:6: warning[NX0407]: type "Admin" is not referenced by any other type, annotate it with #root = true if it is used on its own`,
+			},
+		},
+		{
+			name: "enums without unknown member",
+			files: map[string]string{
+				"foo/user.nex": "type Color enum {\n\tred\n\tgreen\n}\n\ntype Status enum {\n\t0 unknown\n\t1 active\n}\n",
+			},
+			want: []string{
+				`foo/user.nex:2:2: warning[NX0405]: member "red" of enum "Color" has index 0, which should be the "unknown" member`,
+			},
+		},
+		{
+			name: "implicit indexes",
+			files: map[string]string{
+				"foo/user.nex": "type User struct {\n\t0 id string\n\tname string\n}\n\ntype Status enum {\n\t0 unknown\n\t1 active\n\tenabled = active\n}\n\ntype Permission flags {\n\tread\n\t4 write\n\tall = read | write\n}\n",
+			},
+			config: map[string]string{"implicit-index": "warning"},
+			want: []string{
+				`foo/user.nex:3:2: warning[NX0406]: field "name" does not declare its index, which is 1`,
+				`foo/user.nex:13:2: warning[NX0406]: flags member "read" does not declare its value, which is 1`,
+			},
+		},
+		{
+			name: "suppressed rules",
+			files: map[string]string{
+				"foo/user.nex": "#nolint = \"unused-import\"\n\nuse \"common\"\n\n#nolint = \"type-naming, missing-docs\"\ntype user struct {\n\t#nolint = true\n\tfirstName string\n\tlastName string\n}\n",
+				"common/a.nex": "/// An account\ntype Account struct {}\n",
+			},
+			config: map[string]string{"missing-docs": "on"},
+			want: []string{
+				`foo/user.nex:9:2: warning[NX0402]: field name "lastName" should be snake_case, like "last_name"`,
+			},
+		},
+		{
+			name: "files of a package suppress rules on their own",
+			files: map[string]string{
+				"foo/a.nex": "#nolint = \"type-naming\"\n\ntype user struct {}\n",
+				"foo/b.nex": "#nolint = \"type-naming\"\n\ntype account struct {}\n",
+				"foo/c.nex": "type admin struct {}\n",
+			},
+			want: []string{
+				`foo/c.nex:1:6: warning[NX0401]: type name "admin" should be PascalCase, like "Admin"`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			settings, err := NewSettings(nexemaLintConfig(test.config))
+			require.NoError(t, err)
+
+			got := lintFiles(t, test.files, settings)
+			require.Equal(t, test.want, displayDiagnostics(got))
+		})
+	}
+}
+
+func TestLinter_Fix(t *testing.T) {
+	files := map[string]string{
+		"foo/user.nex": "// Users\n\nuse \"common\" // accounts\nuse \"geo\" as g\nuse \"other\"\n\ntype User struct {\n\t0 address g.Address\n}\n",
+		"common/a.nex": "type Account struct {}\n",
+		"geo/a.nex":    "type Address struct {}\n",
+		"other/a.nex":  "type City struct {}\n",
+	}
+
+	settings, err := NewSettings(nexemaLintConfig(nil))
+	require.NoError(t, err)
+
+	var edits []diagnostic.Edit
+	for _, diagnostic := range lintFiles(t, files, settings) {
+		require.Equal(t, "NX0404", diagnostic.Code)
+		edits = append(edits, diagnostic.Edits...)
+	}
+
+	got, err := diagnostic.ApplyEdits([]byte(files["foo/user.nex"]), edits)
+	require.NoError(t, err)
+	require.Equal(t, "// Users\n\nuse \"geo\" as g\n\ntype User struct {\n\t0 address g.Address\n}\n", string(got))
+}
+
+func TestLinter_FixImplicitIndexes(t *testing.T) {
+	source := "type User struct {\n\tid   string // the id\n\t5 name string\n\toneof contact {\n\t\temail string\n\t}\n}\n\n" +
+		"type Status enum {\n\tunknown\n\tactive\n\tenabled = active\n}\n\n" +
+		"type Permission flags {\n\tread\n\twrite\n\t8 admin\n\tdelete\n\tall = read | write\n}\n"
+	files := map[string]string{"foo/user.nex": source}
+
+	settings, err := NewSettings(nexemaLintConfig(map[string]string{"implicit-index": "on"}))
+	require.NoError(t, err)
+
+	var edits []diagnostic.Edit
+	for _, diagnostic := range lintFiles(t, files, settings) {
+		if diagnostic.Code == "NX0406" {
+			edits = append(edits, diagnostic.Edits...)
+		}
+	}
+
+	got, err := diagnostic.ApplyEdits([]byte(source), edits)
+	require.NoError(t, err)
+	require.Equal(t, "type User struct {\n\t0 id   string // the id\n\t5 name string\n\toneof contact {\n\t\t6 email string\n\t}\n}\n\n"+
+		"type Status enum {\n\t0 unknown\n\t1 active\n\tenabled = active\n}\n\n"+
+		"type Permission flags {\n\t1 read\n\t2 write\n\t8 admin\n\t16 delete\n\tall = read | write\n}\n", string(got))
+}
+
+func TestLinter_IgnoreNolint(t *testing.T) {
+	source := "type User struct {\n\t#nolint = \"implicit-index\"\n\tid string\n}\n"
+	settings, err := NewSettings(nexemaLintConfig(map[string]string{"implicit-index": "on"}))
+	require.NoError(t, err)
+
+	require.Empty(t, lintFiles(t, map[string]string{"foo/user.nex": source}, settings))
+
+	linter := newTestLinter(t, map[string]string{"foo/user.nex": source}, settings)
+	require.Equal(t, []string{
+		`foo/user.nex:3:2: warning[NX0406]: field "id" does not declare its index, which is 0`,
+	}, displayDiagnostics(linter.IgnoreNolint().Lint()))
+}
+
+// lintFiles parses, links, analyzes and lints files, which are keyed by their path
+func lintFiles(t *testing.T, files map[string]string, settings Settings) diagnostic.Diagnostics {
+	return newTestLinter(t, files, settings).Lint()
+}
+
+// newTestLinter parses, links and analyzes files, which are keyed by their path, and returns a linter for them
+func newTestLinter(t *testing.T, files map[string]string, settings Settings) *Linter {
+	tree := parser.NewParseTree()
+	sources := diagnostic.Sources{}
+	for filePath, contents := range files {
+		sources[filePath] = []byte(contents)
+		p := parser.NewParser(bytes.NewBufferString(contents), &parser.File{Path: path.Dir(filePath), FileName: path.Base(filePath)})
+		p.Begin()
+		ast := p.Parse()
+		require.Empty(t, *p.Errors(), "parse errors in %s", filePath)
+		tree.Insert(path.Dir(filePath), ast)
+	}
+
+	linker := linker.NewLinker(tree)
+	linker.Link()
+	require.False(t, linker.HasLinkErrors(), linker.Errors().Display())
+
+	analyzer := analyzer.NewAnalyzer(linker.LinkedScopes())
+	analyzer.Analyze()
+	require.False(t, analyzer.HasAnalysisErrors(), analyzer.Errors().Display())
+
+	snapshot := &definition.NexemaSnapshot{Files: analyzer.Files(), Packages: analyzer.Packages()}
+	return NewLinter(linker.LinkedScopes(), snapshot, sources, settings)
+}
+
+func displayDiagnostics(diagnostics diagnostic.Diagnostics) []string {
+	if len(diagnostics) == 0 {
+		return nil
+	}
+
+	out := make([]string, len(diagnostics))
+	for i, diagnostic := range diagnostics {
+		out[i] = diagnostic.String()
+	}
+
+	return out
+}
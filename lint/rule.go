@@ -0,0 +1,158 @@
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"tomasweigenast.com/nexema/tool/diagnostic"
+	"tomasweigenast.com/nexema/tool/nexema"
+	"tomasweigenast.com/nexema/tool/scope"
+)
+
+// Rule is a convention checked by the Linter. Rules do not stop the build, and they can be enabled, disabled
+// or reported with another severity in the lint section of nexema.yaml:
+//
+//	lint:
+//	  rules:
+//	    missing-docs: on
+//	    field-naming: off
+//	    unused-import: error
+type Rule struct {
+	Name        string              // the name used in nexema.yaml and in the nolint annotation
+	Code        string              // the code of the diagnostics reported by the rule
+	Description string              // what the rule checks
+	Severity    diagnostic.Severity // the severity used when the rule is enabled without declaring one
+	Enabled     bool                // true if the rule is checked when nexema.yaml does not configure it
+	Fixable     bool                // true if the diagnostics of the rule have edits that fix them
+
+	check func(linter *Linter, localScope *scope.LocalScope)
+}
+
+// States of a rule in nexema.yaml
+const (
+	ruleOn      = "on"
+	ruleOff     = "off"
+	ruleWarning = "warning"
+	ruleError   = "error"
+)
+
+// rules contains every known rule, sorted by code
+var rules = []*Rule{
+	{
+		Name:        "type-naming",
+		Code:        "NX0401",
+		Description: "type names are PascalCase",
+		Severity:    diagnostic.Warning,
+		Enabled:     true,
+		check:       checkTypeNaming,
+	},
+	{
+		Name:        "field-naming",
+		Code:        "NX0402",
+		Description: "field, member and oneof names are snake_case",
+		Severity:    diagnostic.Warning,
+		Enabled:     true,
+		check:       checkFieldNaming,
+	},
+	{
+		Name:        "missing-docs",
+		Code:        "NX0403",
+		Description: "types are documented",
+		Severity:    diagnostic.Warning,
+		Enabled:     false,
+		check:       checkMissingDocs,
+	},
+	{
+		Name:        "unused-import",
+		Code:        "NX0404",
+		Description: "imported packages are used",
+		Severity:    diagnostic.Warning,
+		Enabled:     true,
+		Fixable:     true,
+		check:       checkUnusedImports,
+	},
+	{
+		Name:        "enum-unknown-member",
+		Code:        "NX0405",
+		Description: "enums declare an \"unknown\" member with index 0",
+		Severity:    diagnostic.Warning,
+		Enabled:     true,
+		check:       checkEnumUnknownMember,
+	},
+	{
+		Name:        "implicit-index",
+		Code:        "NX0406",
+		Description: "fields declare their index, and flags members their value",
+		Severity:    diagnostic.Warning,
+		Enabled:     false,
+		Fixable:     true,
+		check:       checkImplicitIndexes,
+	},
+	{
+		Name:        "unused-type",
+		Code:        "NX0407",
+		Description: "types are referenced by other types, or annotated as roots",
+		Severity:    diagnostic.Warning,
+		Enabled:     false,
+		check:       checkUnusedTypes,
+	},
+}
+
+// Rules returns every known rule, sorted by code
+func Rules() []*Rule {
+	return rules
+}
+
+// FindRule returns the rule with the given name, or nil if there is no one
+func FindRule(name string) *Rule {
+	for _, rule := range rules {
+		if rule.Name == name {
+			return rule
+		}
+	}
+
+	return nil
+}
+
+// Settings contains the severity of every enabled rule
+type Settings map[*Rule]diagnostic.Severity
+
+// NewSettings returns the rules enabled by config, with their severity. An error is returned if config contains
+// an unknown rule or state
+func NewSettings(config nexema.NexemaLintConfig) (Settings, error) {
+	settings := Settings{}
+	for _, rule := range rules {
+		if rule.Enabled {
+			settings[rule] = rule.Severity
+		}
+	}
+
+	names := make([]string, 0, len(config.Rules))
+	for name := range config.Rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		rule := FindRule(name)
+		if rule == nil {
+			return nil, fmt.Errorf("unknown lint rule %q in nexema.yaml", name)
+		}
+
+		switch state := strings.ToLower(config.Rules[name]); state {
+		case ruleOn:
+			settings[rule] = rule.Severity
+		case ruleOff:
+			delete(settings, rule)
+		case ruleWarning:
+			settings[rule] = diagnostic.Warning
+		case ruleError:
+			settings[rule] = diagnostic.Error
+		default:
+			return nil, fmt.Errorf("invalid state %q for lint rule %q in nexema.yaml, expected one of: on, off, warning, error", config.Rules[name], name)
+		}
+	}
+
+	return settings, nil
+}
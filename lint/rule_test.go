@@ -0,0 +1,98 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"tomasweigenast.com/nexema/tool/diagnostic"
+	"tomasweigenast.com/nexema/tool/nexema"
+)
+
+// Codes and names are documented and used in nexema.yaml, so they must never change
+func TestRules(t *testing.T) {
+	want := map[string]string{
+		"type-naming":         "NX0401",
+		"field-naming":        "NX0402",
+		"missing-docs":        "NX0403",
+		"unused-import":       "NX0404",
+		"enum-unknown-member": "NX0405",
+		"implicit-index":      "NX0406",
+		"unused-type":         "NX0407",
+	}
+
+	require.Len(t, Rules(), len(want))
+	for _, rule := range Rules() {
+		require.Equal(t, want[rule.Name], rule.Code, "code of %s", rule.Name)
+		require.NotNil(t, diagnostic.Explain(rule.Code), "%s does not have an explanation", rule.Code)
+		require.Same(t, rule, FindRule(rule.Name))
+	}
+
+	require.Nil(t, FindRule("unknown"))
+}
+
+func TestNewSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]string
+		want    map[string]diagnostic.Severity
+		wantErr string
+	}{
+		{
+			name: "default rules",
+			want: map[string]diagnostic.Severity{
+				"type-naming":         diagnostic.Warning,
+				"field-naming":        diagnostic.Warning,
+				"unused-import":       diagnostic.Warning,
+				"enum-unknown-member": diagnostic.Warning,
+			},
+		},
+		{
+			name: "enabled, disabled and overridden rules",
+			config: map[string]string{
+				"missing-docs":        "on",
+				"implicit-index":      "Error",
+				"field-naming":        "off",
+				"enum-unknown-member": "error",
+				"unused-import":       "warning",
+			},
+			want: map[string]diagnostic.Severity{
+				"type-naming":         diagnostic.Warning,
+				"missing-docs":        diagnostic.Warning,
+				"implicit-index":      diagnostic.Error,
+				"unused-import":       diagnostic.Warning,
+				"enum-unknown-member": diagnostic.Error,
+			},
+		},
+		{
+			name:    "unknown rule",
+			config:  map[string]string{"field-names": "off"},
+			wantErr: `unknown lint rule "field-names" in nexema.yaml`,
+		},
+		{
+			name:    "unknown state",
+			config:  map[string]string{"field-naming": "true"},
+			wantErr: `invalid state "true" for lint rule "field-naming" in nexema.yaml, expected one of: on, off, warning, error`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			settings, err := NewSettings(nexemaLintConfig(test.config))
+			if len(test.wantErr) > 0 {
+				require.EqualError(t, err, test.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			got := map[string]diagnostic.Severity{}
+			for rule, severity := range settings {
+				got[rule.Name] = severity
+			}
+			require.Equal(t, test.want, got)
+		})
+	}
+}
+
+func nexemaLintConfig(rules map[string]string) nexema.NexemaLintConfig {
+	return nexema.NexemaLintConfig{Rules: rules}
+}
@@ -7,4 +7,11 @@ type NexemaProjectConfig struct {
 	Autor      string           `yaml:"author,omitempty" json:"author,omitempty"`
 	Skip       []string         `yaml:"skip,omitempty" json:"skip,omitempty"` // skipped files, as glob references
 	Generators NexemaGenerators `yaml:"generators" json:"generators"`         // At least one
+	Lint       NexemaLintConfig `yaml:"lint,omitempty" json:"lint,omitempty"`
+	Targets    []string         `yaml:"targets,omitempty" json:"targets,omitempty"` // languages whose reserved words cannot be used as names
+}
+
+// NexemaLintConfig configures the rules checked by nexema lint
+type NexemaLintConfig struct {
+	Rules map[string]string `yaml:"rules,omitempty" json:"rules,omitempty"` // the state of a rule by its name: on, off, warning or error
 }
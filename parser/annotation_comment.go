@@ -18,3 +18,25 @@ func unwrapAnnotationsOrComments(original []annotationOrComment, annotations *[]
 		}
 	}
 }
+
+// startLine returns the line where the first of entries starts. Entries are stored by the line they end,
+// which is not the same for multi-line comments and annotations with multi-line strings
+func startLine(entries []annotationOrComment) int {
+	line := -1
+	for _, elem := range entries {
+		var elemLine int
+		if elem.annotation != nil {
+			elemLine = elem.annotation.Pos.Line
+		} else if elem.comment != nil {
+			elemLine = elem.comment.Pos.Line
+		} else {
+			continue
+		}
+
+		if line == -1 || elemLine < line {
+			line = elemLine
+		}
+	}
+
+	return line
+}
@@ -2,6 +2,7 @@ package parser
 
 import (
 	"fmt"
+	"path"
 	"strings"
 
 	"tomasweigenast.com/nexema/tool/token"
@@ -14,8 +15,19 @@ type File struct {
 	FileName string
 }
 
+// SourcePath returns the path of the file relative to the root of the project, or an empty string if file is nil
+func (self *File) SourcePath() string {
+	if self == nil {
+		return ""
+	}
+
+	return path.Join(self.Path, self.FileName)
+}
+
 type Ast struct {
 	File           *File
+	Documentation  []CommentStmt    // comments at the top of the file that are not attached to a type
+	Annotations    []AnnotationStmt // annotations at the top of the file that are not attached to a type
 	UseStatements  []UseStmt
 	TypeStatements []TypeStmt
 }
@@ -29,6 +41,7 @@ type UseStmt struct {
 	Token token.Token // The "use" token
 	Path  LiteralStmt
 	Alias *IdentStmt
+	Pos   tokenizer.Pos // the span of the whole statement, from the "use" keyword
 }
 
 type AnnotationStmt struct {
@@ -76,6 +89,11 @@ type IntLiteral struct {
 	value int64
 }
 
+// UintLiteral is an integer greater than the maximum int64, so it only fits in 64 bits unsigned integers
+type UintLiteral struct {
+	value uint64
+}
+
 type FloatLiteral struct {
 	value float64
 }
@@ -93,19 +111,30 @@ type MapEntry struct {
 }
 
 type TypeStmt struct {
-	Name          IdentStmt
-	Modifier      token.TokenKind
-	BaseType      *DeclStmt
-	Documentation []CommentStmt
-	Annotations   []AnnotationStmt
-	Fields        []FieldStmt
-	Defaults      []AssignStmt
+	Name           IdentStmt
+	Modifier       token.TokenKind
+	BaseType       *DeclStmt
+	UnderlyingType *IdentStmt // the primitive that holds the value of a flags type, if declared
+	Documentation  []CommentStmt
+	Annotations    []AnnotationStmt
+	Fields         []FieldStmt
+	Oneofs         []OneofStmt // the oneof groups declared in the type, whose fields are part of Fields
+	Defaults       []AssignStmt
 }
 
 type FieldStmt struct {
 	Index         *IdentStmt
 	Name          IdentStmt
 	ValueType     *DeclStmt
+	AliasOf       *IdentStmt  // the member this one is an alias of, only for enums
+	Combines      []IdentStmt // the members this one is a combination of, only for flags
+	Oneof         *IdentStmt  // the name of the oneof group the field belongs to, if any
+	Documentation []CommentStmt
+	Annotations   []AnnotationStmt
+}
+
+type OneofStmt struct {
+	Name          IdentStmt
 	Documentation []CommentStmt
 	Annotations   []AnnotationStmt
 }
@@ -126,6 +155,14 @@ func (self IntLiteral) Value() interface{} {
 	return self.value
 }
 
+func (self UintLiteral) Literal() string {
+	return fmt.Sprint(self.value)
+}
+
+func (self UintLiteral) Value() interface{} {
+	return self.value
+}
+
 func (self FloatLiteral) Literal() string {
 	return fmt.Sprint(self.value)
 }
@@ -193,6 +230,10 @@ func MakeIntLiteral(v int64) IntLiteral {
 	return IntLiteral{v}
 }
 
+func MakeUintLiteral(v uint64) UintLiteral {
+	return UintLiteral{v}
+}
+
 func MakeFloatLiteral(v float64) FloatLiteral {
 	return FloatLiteral{v}
 }
@@ -0,0 +1,146 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"tomasweigenast.com/nexema/tool/token"
+	"tomasweigenast.com/nexema/tool/tokenizer"
+)
+
+// DocLink represents a cross-reference written in a documentation comment, in the form of
+// [TypeName], [alias.TypeName], [TypeName.field] or [alias.TypeName.field]
+type DocLink struct {
+	Text string        // the reference, without brackets
+	Pos  tokenizer.Pos // the position of the reference, brackets included
+}
+
+var docLinkRegex = regexp.MustCompile(`\[([\p{L}_][\p{L}\p{N}_]*(?:\.[\p{L}_][\p{L}\p{N}_]*)*)\]`)
+
+// IsDoc returns true if the comment is a documentation comment (/// or /** */)
+func (self CommentStmt) IsDoc() bool {
+	return self.Token.Kind == token.DocComment || self.Token.Kind == token.DocCommentMultiline
+}
+
+// FormatDocumentation takes a list of comments and outputs the documentation written on them, one line per element.
+//
+// If any of the comments is a documentation comment (/// or /** */), only documentation comments are taken
+// into account. Otherwise, line comments (//) are used. Block comments (/* */) are never documentation.
+// Empty lines are kept, once, to separate paragraphs.
+func FormatDocumentation(comments []CommentStmt) []string {
+	out := make([]string, 0)
+	for _, comment := range documentationComments(comments) {
+		if comment.Token.Kind == token.DocCommentMultiline {
+			for _, line := range strings.Split(comment.Token.Literal, "\n") {
+				line = strings.TrimSpace(line)
+				line = strings.TrimSpace(strings.TrimPrefix(line, "*"))
+				out = append(out, line)
+			}
+		} else {
+			out = append(out, strings.TrimSpace(comment.Token.Literal))
+		}
+	}
+
+	// remove leading, trailing and repeated empty lines
+	result := make([]string, 0, len(out))
+	for _, line := range out {
+		if len(line) == 0 && (len(result) == 0 || len(result[len(result)-1]) == 0) {
+			continue
+		}
+
+		result = append(result, line)
+	}
+
+	if len(result) > 0 && len(result[len(result)-1]) == 0 {
+		result = result[:len(result)-1]
+	}
+
+	return result
+}
+
+// FindDocLinks returns every cross-reference written in the documentation comments of comments
+func FindDocLinks(comments []CommentStmt) []DocLink {
+	var links []DocLink
+	for _, comment := range documentationComments(comments) {
+		text := comment.Token.Literal
+		for _, match := range docLinkRegex.FindAllStringSubmatchIndex(text, -1) {
+			// skip markdown links, like [text](url)
+			if match[1] < len(text) && text[match[1]] == '(' {
+				continue
+			}
+
+			links = append(links, DocLink{Text: text[match[2]:match[3]], Pos: docLinkPos(comment, match[0], match[1])})
+		}
+	}
+
+	return links
+}
+
+// docLinkPos returns the position of the text between the start and end byte offsets of the literal of comment
+func docLinkPos(comment CommentStmt, start, end int) tokenizer.Pos {
+	// the literal does not include the //, /// or /** that opens the comment
+	prefix := len("///")
+	if comment.Token.Kind == token.Comment {
+		prefix = len("//")
+	}
+
+	at := comment.Pos
+	at.Start += prefix
+	at.Column += prefix
+	at.Column16 += prefix
+
+	from := advance(at, comment.Token.Literal[:start])
+	to := advance(from, comment.Token.Literal[start:end])
+	return tokenizer.Span(from, to)
+}
+
+// advance returns the empty span right after text, if text is written where at starts
+func advance(at tokenizer.Pos, text string) tokenizer.Pos {
+	for len(text) > 0 {
+		ch, width := utf8.DecodeRuneInString(text)
+		text = text[width:]
+		at.Start += width
+		if ch == '\n' {
+			at.Line++
+			at.Column = 1
+			at.Column16 = 1
+			continue
+		}
+
+		at.Column += width
+		at.Column16++
+		if ch >= 0x10000 {
+			at.Column16++
+		}
+	}
+
+	return tokenizer.Pos{
+		Start: at.Start, End: at.Start,
+		Line: at.Line, Endline: at.Line,
+		Column: at.Column, EndColumn: at.Column,
+		Column16: at.Column16, EndColumn16: at.Column16,
+	}
+}
+
+// documentationComments returns the comments of arr that are used as documentation
+func documentationComments(arr []CommentStmt) []CommentStmt {
+	docs := make([]CommentStmt, 0, len(arr))
+	for _, comment := range arr {
+		if comment.IsDoc() {
+			docs = append(docs, comment)
+		}
+	}
+
+	if len(docs) > 0 {
+		return docs
+	}
+
+	for _, comment := range arr {
+		if comment.Token.Kind == token.Comment {
+			docs = append(docs, comment)
+		}
+	}
+
+	return docs
+}
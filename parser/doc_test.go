@@ -0,0 +1,126 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"tomasweigenast.com/nexema/tool/token"
+	"tomasweigenast.com/nexema/tool/tokenizer"
+)
+
+func TestFormatDocumentation(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []CommentStmt
+		want  []string
+	}{
+		{
+			name: "line comments",
+			input: []CommentStmt{
+				{Token: *token.NewToken(token.Comment, " first line ")},
+				{Token: *token.NewToken(token.Comment, "second line")},
+			},
+			want: []string{"first line", "second line"},
+		},
+		{
+			name: "doc comments take precedence over line comments",
+			input: []CommentStmt{
+				{Token: *token.NewToken(token.Comment, " TODO: remove")},
+				{Token: *token.NewToken(token.DocComment, " The documentation")},
+			},
+			want: []string{"The documentation"},
+		},
+		{
+			name: "block comments are never documentation",
+			input: []CommentStmt{
+				{Token: *token.NewToken(token.CommentMultiline, " disabled code ")},
+				{Token: *token.NewToken(token.Comment, " The documentation")},
+			},
+			want: []string{"The documentation"},
+		},
+		{
+			name: "paragraphs are kept",
+			input: []CommentStmt{
+				{Token: *token.NewToken(token.DocComment, "")},
+				{Token: *token.NewToken(token.DocComment, " First paragraph")},
+				{Token: *token.NewToken(token.DocComment, "")},
+				{Token: *token.NewToken(token.DocComment, "")},
+				{Token: *token.NewToken(token.DocComment, " Second paragraph")},
+				{Token: *token.NewToken(token.DocComment, "")},
+			},
+			want: []string{"First paragraph", "", "Second paragraph"},
+		},
+		{
+			name: "multiline doc comment",
+			input: []CommentStmt{
+				{Token: *token.NewToken(token.DocCommentMultiline, "\n * First paragraph\n * continues here\n *\n * Second paragraph\n ")},
+			},
+			want: []string{"First paragraph", "continues here", "", "Second paragraph"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, FormatDocumentation(tt.input))
+		})
+	}
+}
+
+func TestFindDocLinks(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []CommentStmt
+		want  []DocLink
+	}{
+		{
+			name: "links",
+			input: []CommentStmt{
+				{Token: *token.NewToken(token.DocComment, " See [User], [User.name] and [geo.Address.street]"), Pos: *tokenizer.NewPos(0, 52)},
+			},
+			want: []DocLink{
+				{Text: "User", Pos: *tokenizer.NewPos(8, 14)},
+				{Text: "User.name", Pos: *tokenizer.NewPos(16, 27)},
+				{Text: "geo.Address.street", Pos: *tokenizer.NewPos(32, 52)},
+			},
+		},
+		{
+			name: "markdown links are skipped",
+			input: []CommentStmt{
+				{Token: *token.NewToken(token.DocComment, " Read [the docs](https://nexema.dev) and [User][Account]"), Pos: *tokenizer.NewPos(0, 59)},
+			},
+			want: []DocLink{
+				{Text: "User", Pos: *tokenizer.NewPos(44, 50)},
+				{Text: "Account", Pos: *tokenizer.NewPos(50, 59)},
+			},
+		},
+		{
+			name: "links in ignored comments are skipped",
+			input: []CommentStmt{
+				{Token: *token.NewToken(token.Comment, " [Ignored]")},
+				{Token: *token.NewToken(token.DocComment, " [User]"), Pos: *tokenizer.NewPos(0, 10)},
+			},
+			want: []DocLink{
+				{Text: "User", Pos: *tokenizer.NewPos(4, 10)},
+			},
+		},
+		{
+			name: "links in multiline comments",
+			input: []CommentStmt{
+				{
+					Token: *token.NewToken(token.DocCommentMultiline, " See\n * [User] and é [Account]\n "),
+					Pos:   tokenizer.Pos{Start: 20, End: 57, Line: 3, Endline: 5, Column: 1, EndColumn: 4, Column16: 1, EndColumn16: 4},
+				},
+			},
+			want: []DocLink{
+				{Text: "User", Pos: tokenizer.Pos{Start: 31, End: 37, Line: 4, Endline: 4, Column: 4, EndColumn: 10, Column16: 4, EndColumn16: 10}},
+				{Text: "Account", Pos: tokenizer.Pos{Start: 45, End: 54, Line: 4, Endline: 4, Column: 18, EndColumn: 27, Column16: 17, EndColumn16: 26}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, FindDocLinks(tt.input))
+		})
+	}
+}
@@ -1,10 +1,10 @@
 package parser
 
 import (
-	"errors"
 	"fmt"
 	"strings"
 
+	"tomasweigenast.com/nexema/tool/diagnostic"
 	"tomasweigenast.com/nexema/tool/token"
 	"tomasweigenast.com/nexema/tool/tokenizer"
 )
@@ -12,6 +12,7 @@ import (
 type ParserError struct {
 	At   tokenizer.Pos
 	Kind ParserErrorKind
+	File *File // the file where the error was found, if known
 }
 
 type ParserErrorKind interface {
@@ -94,7 +95,38 @@ func (u ErrExpectedLiteral) Message() string {
 }
 
 func NewParserErr(err ParserErrorKind, at tokenizer.Pos) *ParserError {
-	return &ParserError{at, err}
+	return &ParserError{At: at, Kind: err}
+}
+
+// Code returns the code that identifies the kind of the error in diagnostics
+func (self *ParserError) Code() string {
+	switch self.Kind.(type) {
+	case ErrUnexpectedEOF:
+		return "NX0101"
+	case ErrUnexpectedToken:
+		return "NX0102"
+	case ErrTokenizer:
+		return "NX0103"
+	case ErrExpectedIdentifier:
+		return "NX0104"
+	case ErrNumberParse:
+		return "NX0105"
+	case ErrInvalidLiteral:
+		return "NX0106"
+	case ErrUnexpectedValue:
+		return "NX0107"
+	case ErrExpectedDeclaration:
+		return "NX0108"
+	case ErrExpectedLiteral:
+		return "NX0109"
+	}
+
+	return "NX0100"
+}
+
+// Diagnostic converts the error to a diagnostic.Diagnostic
+func (self *ParserError) Diagnostic() *diagnostic.Diagnostic {
+	return diagnostic.New(diagnostic.Error, self.Code(), self.Kind.Message(), self.File.SourcePath(), self.At)
 }
 
 type ParserErrorCollection []*ParserError
@@ -115,14 +147,25 @@ func (self *ParserErrorCollection) IsEmpty() bool {
 func (self *ParserErrorCollection) Display() string {
 	out := make([]string, len(*self))
 	for i, err := range *self {
-		out[i] = fmt.Sprintf("%d:%d -> %s", err.At.Line, err.At.Start, err.Kind.Message())
+		out[i] = fmt.Sprintf("%d:%d -> %s", err.At.Line, err.At.Column, err.Kind.Message())
 	}
 
 	return strings.Join(out, "\n")
 }
 
+// Diagnostics converts every error in the collection to a diagnostic.Diagnostic
+func (self *ParserErrorCollection) Diagnostics() diagnostic.Diagnostics {
+	out := make(diagnostic.Diagnostics, len(*self))
+	for i, err := range *self {
+		out[i] = err.Diagnostic()
+	}
+
+	return out
+}
+
+// AsError returns the errors in the collection as diagnostic.Diagnostics
 func (self *ParserErrorCollection) AsError() error {
-	return errors.New(self.Display())
+	return self.Diagnostics()
 }
 
 func (self *ParserErrorCollection) Clone() []ParserError {
@@ -0,0 +1,33 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"tomasweigenast.com/nexema/tool/diagnostic"
+	"tomasweigenast.com/nexema/tool/tokenizer"
+)
+
+// Codes are documented and can be suppressed, so they must never change
+func TestParserError_Code(t *testing.T) {
+	tests := []struct {
+		kind ParserErrorKind
+		want string
+	}{
+		{ErrUnexpectedEOF{}, "NX0101"},
+		{ErrUnexpectedToken{}, "NX0102"},
+		{ErrTokenizer{}, "NX0103"},
+		{ErrExpectedIdentifier{}, "NX0104"},
+		{ErrNumberParse{}, "NX0105"},
+		{ErrInvalidLiteral{}, "NX0106"},
+		{ErrUnexpectedValue{}, "NX0107"},
+		{ErrExpectedDeclaration{}, "NX0108"},
+		{ErrExpectedLiteral{}, "NX0109"},
+	}
+
+	for _, tt := range tests {
+		code := NewParserErr(tt.kind, tokenizer.Pos{}).Code()
+		require.Equal(t, tt.want, code)
+		require.NotNil(t, diagnostic.Explain(code), "%s does not have an explanation", code)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
 
 	"github.com/tidwall/btree"
 	"tomasweigenast.com/nexema/tool/token"
@@ -18,6 +19,14 @@ type Parser struct {
 	errors                *ParserErrorCollection
 	eof                   bool
 	annotationsOrComments *btree.Map[int, *[]annotationOrComment]
+	pending               []pendingToken // the tokens read after nextToken by peekAfterNext
+}
+
+// pendingToken is a token read from the tokenizer to look ahead, which has not been consumed yet
+type pendingToken struct {
+	token *token.Token
+	pos   *tokenizer.Pos
+	err   *tokenizer.TokenizerErr
 }
 
 type tokenBuf struct {
@@ -32,6 +41,7 @@ func NewParser(input io.Reader, file *File) *Parser {
 		currentToken: nil,
 		nextToken:    nil,
 		tokenizer:    *tokenizer.NewTokenizer(input),
+		errors:       newParserErrorCollection(),
 	}
 }
 
@@ -40,25 +50,38 @@ func (self *Parser) Errors() *ParserErrorCollection {
 }
 
 func (self *Parser) Parse() *Ast {
+	// read the file header, which contains the package's documentation and annotations
+	documentation, annotations := self.parseHeader()
+
 	// read "use" statements
 	var useStmts []UseStmt
 	for self.currentTokenIs(token.Use) {
 		stmt := self.parseUseStmt()
 		if stmt == nil {
-			break
+			self.synchronize()
+			continue
 		}
 
 		useStmts = append(useStmts, *stmt)
 		self.next()
 	}
 
-	// read typeStmts
+	// read typeStmts. If one of them cannot be parsed, the error is reported and the parser skips
+	// to the next declaration, so every syntax error of the file is reported at once
 	var typeStmts []TypeStmt
-	for self.currentTokenIs(token.Type) {
+	for self.currentToken != nil {
+		if !self.currentTokenIs(token.Type) {
+			self.reportErr(ErrExpectedDeclaration{*self.currentToken.token})
+			self.next()
+			self.synchronize()
+			continue
+		}
+
 		self.next()
 		stmt := self.parseTypeStmt()
 		if stmt == nil {
-			break
+			self.synchronize()
+			continue
 		}
 
 		typeStmts = append(typeStmts, *stmt)
@@ -67,6 +90,8 @@ func (self *Parser) Parse() *Ast {
 
 	return &Ast{
 		File:           self.file,
+		Documentation:  documentation,
+		Annotations:    annotations,
 		UseStatements:  useStmts,
 		TypeStatements: typeStmts,
 	}
@@ -79,18 +104,48 @@ func (self *Parser) Begin() {
 
 // Reset initializes the parser reading one token into the buffer
 func (self *Parser) Reset() *ParserError {
-	result := self.consume()
-	if result != nil {
-		return NewParserErr(ErrTokenizer{*result}, *tokenizer.NewPos())
-	}
-
 	self.eof = false
 	self.resetAnnotationCommentsMap()
 	self.errors = newParserErrorCollection()
 
+	self.consume()
+	if !self.errors.IsEmpty() {
+		return (*self.errors)[0]
+	}
+
 	return nil
 }
 
+// synchronize skips tokens until the start of the next declaration, a "type" or "use" keyword, or the end of the file.
+// If the current token already starts a declaration, nothing is skipped.
+func (self *Parser) synchronize() {
+	for self.currentToken != nil && !self.atDeclaration() {
+		self.next()
+	}
+}
+
+// atDeclaration returns true if the current token is a "type" or "use" keyword
+func (self *Parser) atDeclaration() bool {
+	return self.currentTokenIs(token.Type) || self.currentTokenIs(token.Use)
+}
+
+// parseHeader returns every comment and annotation declared at the top of the file.
+//
+// If the first statement of the file is a type, comments and annotations declared right before it
+// are left untouched because they belong to the type. To be part of the header, they must be separated
+// from the type by, at least, one blank line.
+func (self *Parser) parseHeader() (comments []CommentStmt, annotations []AnnotationStmt) {
+	var arr []annotationOrComment
+	if self.currentTokenIs(token.Type) {
+		arr = self.getDetachedAnnotationsAndComments(self.currentToken.position.Line)
+	} else {
+		arr = self.getDetachedAnnotationsAndComments(-1)
+	}
+
+	unwrapAnnotationsOrComments(arr, &annotations, &comments)
+	return
+}
+
 // parseTypeStmt parses a type statement.
 func (self *Parser) parseTypeStmt() *TypeStmt {
 	// "type" keyword already read
@@ -115,14 +170,22 @@ func (self *Parser) parseTypeStmt() *TypeStmt {
 
 	// read type modifier or "extends" keyword
 	var baseType *DeclStmt
+	var underlyingType *IdentStmt
 	modifier := token.Struct
 	currentToken := self.currentToken
 
 	if currentToken == nil {
+		self.reportErr(ErrUnexpectedEOF{})
 		return nil
 	}
 
-	switch currentToken.token.Kind {
+	// flags is read as an identifier, since it is only a keyword here
+	modifierKind := currentToken.token.Kind
+	if currentToken.token.IsContextualKeyword(token.Flags) {
+		modifierKind = token.Flags
+	}
+
+	switch modifierKind {
 	case token.Extends:
 		self.next()
 		baseType = self.parseDeclStmt(true)
@@ -134,7 +197,23 @@ func (self *Parser) parseTypeStmt() *TypeStmt {
 	case token.Struct, token.Enum, token.Union, token.Base:
 		modifier = currentToken.token.Kind
 
+	case token.Flags:
+		modifier = token.Flags
+
+		// the underlying type is optional, in the form flags(uint16)
+		if self.nextTokenIsMove(token.Lparen) {
+			if !self.expectToken(token.Ident) {
+				return nil
+			}
+
+			underlyingType = self.parseIdent()
+			if !self.expectToken(token.Rparen) {
+				return nil
+			}
+		}
+
 	default:
+		self.reportErr(ErrUnexpectedValue{Expected: "type modifier", Got: *currentToken.token})
 		return nil
 	}
 
@@ -143,37 +222,55 @@ func (self *Parser) parseTypeStmt() *TypeStmt {
 		return nil
 	}
 
-	// read fields until } or "defaults" keyword
+	// read fields and oneof groups until } or "defaults" keyword
 	var fields []FieldStmt
+	var oneofs []OneofStmt
 	var defaults []AssignStmt = nil
 
+	isEnum := modifier == token.Enum || modifier == token.Flags
+	self.next()
 	for !self.currentTokenIs(token.Rbrace) {
 		if self.currentToken == nil {
+			self.reportErr(ErrUnexpectedEOF{})
 			break
 		}
 
+		// a declaration keyword means the closing brace is missing, the next declaration is left to be parsed
+		if self.atDeclaration() {
+			self.reportExpectedCurrentTokenErr(token.Rbrace)
+			return nil
+		}
+
 		switch self.currentToken.token.Kind {
 		case token.Defaults:
 			self.next()
 			defaults = self.parseDefaultsBlock()
+			if defaults == nil {
+				return nil
+			}
 
 			// after reading the defaults block, nothing can be declared, so expect closing
 			if !self.expectToken(token.Rbrace) {
 				return nil
 			}
 
-		case token.Rbrace:
-			break // exit
-
 		default:
-			// read field
-			self.next()
-			fieldStmt := self.parseFieldStmt(modifier == token.Enum)
-			if fieldStmt == nil {
-				break
+			if self.atOneofStmt() {
+				oneof, oneofFields := self.parseOneofStmt()
+				if oneof == nil {
+					return nil
+				}
+
+				oneofs = append(oneofs, *oneof)
+				fields = append(fields, oneofFields...)
+				self.next()
+				continue
 			}
 
-			fields = append(fields, *fieldStmt)
+			fieldStmt := self.parseFieldAndTrailingComments(isEnum)
+			if fieldStmt != nil {
+				fields = append(fields, *fieldStmt)
+			}
 		}
 	}
 
@@ -182,14 +279,105 @@ func (self *Parser) parseTypeStmt() *TypeStmt {
 	}
 
 	return &TypeStmt{
-		BaseType:      baseType,
-		Name:          *typeName,
-		Modifier:      modifier,
+		BaseType:       baseType,
+		UnderlyingType: underlyingType,
+		Name:           *typeName,
+		Modifier:       modifier,
+		Documentation:  comments,
+		Annotations:    annotations,
+		Fields:         fields,
+		Oneofs:         oneofs,
+		Defaults:       defaults,
+	}
+}
+
+// parseOneofStmt parses a group of fields in the form:
+//
+// oneof [ident] { [fields] }
+//
+// It returns the group and its fields, which are marked as part of the group.
+func (self *Parser) parseOneofStmt() (*OneofStmt, []FieldStmt) {
+	// "oneof" keyword already read
+
+	// any comment or annotation read until here, while they appear one line before each other, must be added as doc
+	var annotations []AnnotationStmt = nil
+	var comments []CommentStmt = nil
+	currentLine := self.currentToken.position.Line
+	arr := self.getAnnotationsAndComments(currentLine)
+	unwrapAnnotationsOrComments(arr, &annotations, &comments)
+
+	self.next()
+	name := self.parseIdent()
+	if name == nil {
+		return nil, nil
+	}
+
+	if !self.expectToken(token.Lbrace) {
+		return nil, nil
+	}
+
+	var fields []FieldStmt
+	self.next()
+	for !self.currentTokenIs(token.Rbrace) {
+		if self.currentToken == nil {
+			self.reportErr(ErrUnexpectedEOF{})
+			return nil, nil
+		}
+
+		if self.atDeclaration() {
+			self.reportExpectedCurrentTokenErr(token.Rbrace)
+			return nil, nil
+		}
+
+		// groups cannot be nested, nor declare defaults
+		if self.atOneofStmt() {
+			self.reportErr(ErrUnexpectedValue{Expected: "field", Got: *token.NewToken(token.Oneof)})
+			return nil, nil
+		}
+
+		if self.currentTokenIs(token.Defaults) {
+			self.reportErr(ErrUnexpectedValue{Expected: "field", Got: *self.currentToken.token})
+			return nil, nil
+		}
+
+		fieldStmt := self.parseFieldAndTrailingComments(false)
+		if fieldStmt != nil {
+			fieldStmt.Oneof = name
+			fields = append(fields, *fieldStmt)
+		}
+	}
+
+	return &OneofStmt{
+		Name:          *name,
 		Documentation: comments,
 		Annotations:   annotations,
-		Fields:        fields,
-		Defaults:      defaults,
+	}, fields
+}
+
+// parseFieldAndTrailingComments parses a field statement and moves to the next token, adding
+// the comments written in the same line of the field to its documentation.
+//
+// If the field cannot be parsed, nil is returned and the rest of the line is skipped, stopping at a closing brace
+// or a declaration keyword, so the next field can be parsed.
+func (self *Parser) parseFieldAndTrailingComments(isEnum bool) *FieldStmt {
+	startLine := self.currentToken.position.Line
+	fieldStmt := self.parseFieldStmt(isEnum)
+	if fieldStmt == nil || self.currentToken == nil {
+		for self.currentToken != nil && self.currentToken.position.Line == startLine && !self.currentTokenIs(token.Rbrace) && !self.atDeclaration() {
+			self.next()
+		}
+		return nil
+	}
+
+	lastLine := self.currentToken.position.Endline
+	self.next()
+
+	// comments in the same line of the field are part of its documentation
+	if trailing := self.getTrailingComments(lastLine); trailing != nil {
+		fieldStmt.Documentation = append(fieldStmt.Documentation, trailing...)
 	}
+
+	return fieldStmt
 }
 
 // parseDefaultsBlock parses a block of declarations.
@@ -218,6 +406,12 @@ func (self *Parser) parseDefaultsBlock() []AssignStmt {
 // (index)     [ident]     [decl]
 // field_index field_name  value_type.
 //
+// Enum and flags members do not declare a value type, but they can be an alias of another member,
+// or a combination of other members for flags:
+//
+// [ident]     =  [ident]        (| [ident])
+// member_name =  another_member | and_another
+//
 // Any comment or annotation that was read until this method call, will be added as
 // documentation (if comment line is self.tokenizer.currentLine-1) and annotations, respectively.
 func (self *Parser) parseFieldStmt(isEnum bool) *FieldStmt {
@@ -254,6 +448,8 @@ func (self *Parser) parseFieldStmt(isEnum bool) *FieldStmt {
 	}
 
 	var fieldType *DeclStmt
+	var aliasOf *IdentStmt
+	var combines []IdentStmt
 	if !isEnum {
 		// read type declaration
 		self.next()
@@ -261,12 +457,36 @@ func (self *Parser) parseFieldStmt(isEnum bool) *FieldStmt {
 		if fieldType == nil {
 			return nil
 		}
+	} else if self.nextTokenIsMove(token.Assign) {
+		// enum member alias, in the form: name = another_member
+		self.next()
+		aliasOf = self.parseIdent()
+		if aliasOf == nil {
+			return nil
+		}
+
+		if self.nextTokenIs(token.Pipe) {
+			combines = []IdentStmt{*aliasOf}
+			aliasOf = nil
+
+			for self.nextTokenIsMove(token.Pipe) {
+				self.next()
+				member := self.parseIdent()
+				if member == nil {
+					return nil
+				}
+
+				combines = append(combines, *member)
+			}
+		}
 	}
 
 	return &FieldStmt{
 		Index:         fieldIndex,
 		Name:          *fieldName,
 		ValueType:     fieldType,
+		AliasOf:       aliasOf,
+		Combines:      combines,
 		Documentation: comments,
 		Annotations:   annotations,
 	}
@@ -277,6 +497,7 @@ func (self *Parser) parseFieldStmt(isEnum bool) *FieldStmt {
 // use "path/to/my/package"
 // use "path/to/my/package" as my_pkg
 func (self *Parser) parseUseStmt() *UseStmt {
+	usePos := *self.currentToken.position
 	self.next()
 
 	// now we need the path as a string
@@ -295,23 +516,30 @@ func (self *Parser) parseUseStmt() *UseStmt {
 				Token: *token.NewToken(token.Use),
 				Path:  *literal,
 				Alias: nil,
+				Pos:   tokenizer.Span(usePos, literal.Pos),
 			}
 
 			// maybe alias
 			if self.nextTokenIs(token.As) {
 				self.next()
 
-				if self.nextTokenIs(token.Ident) {
-					self.next()
-					ident := self.parseIdent()
-					useStmt.Alias = ident
+				if !self.expectToken(token.Ident) {
+					return nil
 				}
+
+				useStmt.Alias = self.parseIdent()
+				if useStmt.Alias == nil {
+					return nil
+				}
+
+				useStmt.Pos = tokenizer.Span(usePos, useStmt.Alias.Pos)
 			}
 
 			return useStmt
 		}
 	}
 
+	self.reportErr(ErrUnexpectedValue{"literal with import path", *self.currentToken.token})
 	return nil
 }
 
@@ -321,7 +549,10 @@ func (self *Parser) parseUseStmt() *UseStmt {
 // int
 // list(string)
 // map(int, string)
+// array(float32, 3)
 // MyType
+//
+// Integer arguments, which declare lengths, are returned as a DeclStmt whose token is the integer.
 func (self *Parser) parseDeclStmt(reportErr bool) *DeclStmt {
 	if self.currentToken == nil {
 		if reportErr {
@@ -354,9 +585,17 @@ func (self *Parser) parseDeclStmt(reportErr bool) *DeclStmt {
 				// while we dont reach ), read DeclStmts, separated by commas
 				for !self.nextTokenIs(token.Rparen) {
 					self.next()
-					decl := self.parseDeclStmt(true)
+
+					var decl *DeclStmt
+					if self.currentTokenIs(token.Integer) {
+						// a length argument, like in array(float32, 3) or string(64)
+						decl = &DeclStmt{Token: *self.currentToken.token, Pos: *self.currentToken.position}
+					} else {
+						decl = self.parseDeclStmt(true)
+					}
+
 					if decl == nil {
-						break
+						return nil
 					}
 
 					args = append(args, *decl)
@@ -368,8 +607,8 @@ func (self *Parser) parseDeclStmt(reportErr bool) *DeclStmt {
 					} else if self.currentTokenIs(token.Rparen) {
 						break
 					} else {
-						self.reportExpectedNextTokenErr(token.Rparen)
-						break
+						self.reportExpectedCurrentTokenErr(token.Rparen)
+						return nil
 					}
 				}
 
@@ -381,7 +620,7 @@ func (self *Parser) parseDeclStmt(reportErr bool) *DeclStmt {
 				endPos := *self.currentToken.position
 				return &DeclStmt{
 					Token:    currentToken,
-					Pos:      *tokenizer.NewPos(currentPos.Start, endPos.End, currentPos.Line, endPos.Endline),
+					Pos:      tokenizer.Span(currentPos, endPos),
 					Args:     args,
 					Alias:    nil,
 					Nullable: self.nextTokenIsMove(token.QuestionMark),
@@ -400,7 +639,7 @@ func (self *Parser) parseDeclStmt(reportErr bool) *DeclStmt {
 
 				return &DeclStmt{
 					Token: ident.Token,
-					Pos:   *tokenizer.NewPos(currentPos.Start, ident.Pos.End, currentPos.Line, ident.Pos.Endline),
+					Pos:   tokenizer.Span(currentPos, ident.Pos),
 					Args:  nil,
 					Alias: &IdentStmt{
 						Token: currentToken,
@@ -412,7 +651,7 @@ func (self *Parser) parseDeclStmt(reportErr bool) *DeclStmt {
 			default:
 				return &DeclStmt{
 					Token:    currentToken,
-					Pos:      *tokenizer.NewPos(currentPos.Start, currentPos.End, currentPos.Line, currentPos.Endline),
+					Pos:      currentPos,
 					Args:     nil,
 					Alias:    nil,
 					Nullable: self.nextTokenIsMove(token.QuestionMark),
@@ -428,18 +667,18 @@ func (self *Parser) parseDeclStmt(reportErr bool) *DeclStmt {
 // parseAnnotationStmt parses a declaration in the following form:
 //
 // #left = right
-func (self *Parser) parseAnnotationStmt() *AnnotationStmt {
+//
+// where hashPos is the position of the # that was already read
+func (self *Parser) parseAnnotationStmt(hashPos tokenizer.Pos) *AnnotationStmt {
 	assignStmt := self.parseAssignStmt()
 	if assignStmt == nil {
 		return nil
 	}
 
-	pos := assignStmt.Pos
-	pos.Start--
 	return &AnnotationStmt{
 		Token:     *token.NewToken(token.Hash),
 		Assigment: *assignStmt,
-		Pos:       pos,
+		Pos:       tokenizer.Span(hashPos, assignStmt.Pos),
 	}
 }
 
@@ -467,7 +706,7 @@ func (self *Parser) parseAssignStmt() *AssignStmt {
 		Token: *token.NewToken(token.Assign),
 		Left:  *ident,
 		Right: *literal,
-		Pos:   *tokenizer.NewPos(ident.Pos.Start, literal.Pos.End, ident.Pos.Line, literal.Pos.Endline),
+		Pos:   tokenizer.Span(ident.Pos, literal.Pos),
 	}
 }
 
@@ -485,6 +724,7 @@ func (self *Parser) parseIdent() *IdentStmt {
 		}
 	}
 
+	self.reportErr(ErrExpectedIdentifier{*self.currentToken.token})
 	return nil
 }
 
@@ -505,18 +745,25 @@ func (self *Parser) parseLiteral() *LiteralStmt {
 		literalKind = StringLiteral{literal}
 
 	case token.Integer:
-		num, err := strconv.ParseInt(literal, 10, 64)
-		if err != nil {
-			self.reportErr(ErrNumberParse{err, literal})
+		num, err := token.ParseInteger(literal, 64)
+		if err == nil {
+			literalKind = IntLiteral{num}
+			break
+		}
+
+		// greater than any int64, it can still be the value of a uint64
+		unsigned, uintErr := token.ParseUnsignedInteger(literal, 64)
+		if uintErr != nil {
+			self.reportNumberErr(ErrNumberParse{err, literal}, tokenPos)
 			return nil
 		}
 
-		literalKind = IntLiteral{num}
+		literalKind = UintLiteral{unsigned}
 
 	case token.Decimal:
-		num, err := strconv.ParseFloat(literal, 64)
+		num, err := strconv.ParseFloat(strings.ReplaceAll(literal, "_", ""), 64)
 		if err != nil {
-			self.reportErr(ErrNumberParse{err, literal})
+			self.reportNumberErr(ErrNumberParse{err, literal}, tokenPos)
 			return nil
 		}
 
@@ -567,7 +814,7 @@ func (self *Parser) parseLiteral() *LiteralStmt {
 		}
 
 		endPos := self.currentToken.position
-		tokenPos = *tokenizer.NewPos(tokenPos.Start, endPos.End, tokenPos.Line, endPos.Endline)
+		tokenPos = tokenizer.Span(tokenPos, *endPos)
 		literalToken = *token.NewToken(token.List)
 
 	// map literal
@@ -621,7 +868,7 @@ func (self *Parser) parseLiteral() *LiteralStmt {
 		}
 
 		endPos := self.currentToken.position
-		tokenPos = *tokenizer.NewPos(tokenPos.Start, endPos.End, tokenPos.Line, endPos.Endline)
+		tokenPos = tokenizer.Span(tokenPos, *endPos)
 		literalToken = *token.NewToken(token.Map)
 
 	default:
@@ -638,11 +885,7 @@ func (self *Parser) parseLiteral() *LiteralStmt {
 
 // next reads the next token, skipping comments and saving them for later use
 func (self *Parser) next() {
-	err := self.consume()
-	if err != nil {
-		self.errors.push(NewParserErr(ErrTokenizer{}, *self.tokenizer.GetCurrentPosition()))
-		return
-	}
+	self.consume()
 
 	// stop the parsing process
 	if self.currentToken == nil {
@@ -654,7 +897,7 @@ func (self *Parser) next() {
 	// here, currentToken will always contain something if .consume() fails, it returns an error
 	currToken := self.currentToken
 	switch currToken.token.Kind {
-	case token.Comment:
+	case token.Comment, token.DocComment, token.DocCommentMultiline:
 		line := currToken.position.Endline
 		self.pushComment(line, &CommentStmt{Token: *currToken.token, Pos: *currToken.position})
 
@@ -666,8 +909,9 @@ func (self *Parser) next() {
 		return
 
 	case token.Hash:
+		hashPos := *currToken.position
 		self.next()
-		annotationStmt := self.parseAnnotationStmt()
+		annotationStmt := self.parseAnnotationStmt(hashPos)
 		if annotationStmt != nil {
 			line := annotationStmt.Pos.Endline
 			self.pushAnnotation(line, annotationStmt)
@@ -677,21 +921,76 @@ func (self *Parser) next() {
 	}
 }
 
-// consume reads token twice from the tokenizer to store current and next.
-func (self *Parser) consume() *tokenizer.TokenizerErr {
+// consume moves next to current and reads the next token from the tokenizer. Tokenizer errors are reported, and
+// then the token read anyway, like a string with an invalid escape sequence, is kept, or skipped if there is none
+func (self *Parser) consume() {
 	self.currentToken = self.nextToken
-	tok, pos, err := self.tokenizer.Next()
-	if err != nil {
-		return err
+	for {
+		tok, pos, err := self.read()
+		if err != nil {
+			self.reportErrAt(ErrTokenizer{*err}, err.Pos())
+			if tok == nil {
+				continue
+			}
+		}
+
+		if tok.IsEOF() {
+			self.nextToken = nil
+			return
+		}
+
+		self.nextToken = &tokenBuf{tok, pos}
+		return
 	}
+}
 
-	if tok.IsEOF() {
-		self.nextToken = nil
-		return nil
+// read returns the next token of the tokenizer, or the first one read by peekAfterNext
+func (self *Parser) read() (*token.Token, *tokenizer.Pos, *tokenizer.TokenizerErr) {
+	if len(self.pending) > 0 {
+		next := self.pending[0]
+		self.pending = self.pending[1:]
+		return next.token, next.pos, next.err
 	}
 
-	self.nextToken = &tokenBuf{tok, pos}
-	return nil
+	return self.tokenizer.Next()
+}
+
+// peekAfterNext returns the first token after nextToken that is not a comment, without consuming it, or nil at the
+// end of the file. Errors of the tokenizer are reported when the tokens are consumed
+func (self *Parser) peekAfterNext() *token.Token {
+	for i := 0; ; i++ {
+		if i == len(self.pending) {
+			tok, pos, err := self.tokenizer.Next()
+			self.pending = append(self.pending, pendingToken{tok, pos, err})
+		}
+
+		next := self.pending[i]
+		if next.token == nil {
+			continue
+		}
+
+		if next.token.IsEOF() {
+			return nil
+		}
+
+		switch next.token.Kind {
+		case token.Comment, token.DocComment, token.CommentMultiline, token.DocCommentMultiline:
+			continue
+		}
+
+		return next.token
+	}
+}
+
+// atOneofStmt returns true if the current token starts a oneof group, which is the oneof keyword followed by the
+// name of the group and an opening brace. Otherwise, oneof is the name of a field
+func (self *Parser) atOneofStmt() bool {
+	if self.currentToken == nil || !self.currentToken.token.IsContextualKeyword(token.Oneof) || !self.nextTokenIs(token.Ident) {
+		return false
+	}
+
+	after := self.peekAfterNext()
+	return after != nil && after.Kind == token.Lbrace
 }
 
 // expectToken ensures the next_token kind is [token].
@@ -757,7 +1056,7 @@ func (self *Parser) getAnnotationsAndComments(from int) []annotationOrComment {
 	self.annotationsOrComments.Reverse(func(key int, value *[]annotationOrComment) bool {
 		if key < from && previous-key <= 1 {
 			result = append(result, *value...)
-			previous = key
+			previous = startLine(*value)
 		}
 
 		return true
@@ -776,6 +1075,61 @@ func (self *Parser) getAnnotationsAndComments(from int) []annotationOrComment {
 	return result
 }
 
+// getDetachedAnnotationsAndComments returns and removes every annotation or comment statement that has been read
+// until this call and that is not placed in the lines right before from. If from is negative, every statement is returned.
+func (self *Parser) getDetachedAnnotationsAndComments(from int) []annotationOrComment {
+	detached := make([]int, 0)
+
+	previous := from
+	self.annotationsOrComments.Reverse(func(key int, value *[]annotationOrComment) bool {
+		if from >= 0 && len(detached) == 0 && key < from && previous-key <= 1 {
+			previous = startLine(*value)
+		} else {
+			detached = append(detached, key)
+		}
+
+		return true
+	})
+
+	if len(detached) == 0 {
+		return nil
+	}
+
+	result := make([]annotationOrComment, 0)
+	for i := len(detached) - 1; i >= 0; i-- {
+		value, _ := self.annotationsOrComments.Delete(detached[i])
+		result = append(result, *value...)
+	}
+
+	return result
+}
+
+// getTrailingComments returns and removes every comment statement read in line
+func (self *Parser) getTrailingComments(line int) []CommentStmt {
+	value, ok := self.annotationsOrComments.Get(line)
+	if !ok {
+		return nil
+	}
+
+	var comments []CommentStmt
+	remaining := make([]annotationOrComment, 0)
+	for _, elem := range *value {
+		if elem.comment != nil {
+			comments = append(comments, *elem.comment)
+		} else {
+			remaining = append(remaining, elem)
+		}
+	}
+
+	if len(remaining) == 0 {
+		self.annotationsOrComments.Delete(line)
+	} else {
+		self.annotationsOrComments.Set(line, &remaining)
+	}
+
+	return comments
+}
+
 func (self *Parser) pushAnnotation(line int, stmt *AnnotationStmt) {
 	value, ok := self.annotationsOrComments.GetMut(line)
 	if !ok {
@@ -812,9 +1166,9 @@ func (self *Parser) reportExpectedNextTokenErr(expected token.TokenKind) {
 	}
 
 	if nextToken.IsEOF() {
-		self.errors.push(NewParserErr(ErrUnexpectedEOF{}, pos))
+		self.reportErrAt(ErrUnexpectedEOF{}, pos)
 	} else {
-		self.errors.push(NewParserErr(ErrUnexpectedToken{expected, nextToken}, pos))
+		self.reportErrAt(ErrUnexpectedToken{expected, nextToken}, pos)
 	}
 }
 
@@ -830,9 +1184,9 @@ func (self *Parser) reportExpectedCurrentTokenErr(expected token.TokenKind) {
 	}
 
 	if currentToken.IsEOF() {
-		self.errors.push(NewParserErr(ErrUnexpectedEOF{}, pos))
+		self.reportErrAt(ErrUnexpectedEOF{}, pos)
 	} else {
-		self.errors.push(NewParserErr(ErrUnexpectedToken{expected, currentToken}, pos))
+		self.reportErrAt(ErrUnexpectedToken{expected, currentToken}, pos)
 	}
 }
 
@@ -844,7 +1198,26 @@ func (self *Parser) reportErr(err ParserErrorKind) {
 		pos = *self.currentToken.position
 	}
 
-	self.errors.push(NewParserErr(err, pos))
+	self.reportErrAt(err, pos)
+}
+
+// reportNumberErr reports err, the error of the number at pos, unless the tokenizer already reported an error inside
+// it, like a misplaced digit separator
+func (self *Parser) reportNumberErr(err ErrNumberParse, pos tokenizer.Pos) {
+	for _, reported := range *self.errors {
+		if _, ok := reported.Kind.(ErrTokenizer); ok && reported.At.Start >= pos.Start && reported.At.End <= pos.End {
+			return
+		}
+	}
+
+	self.reportErrAt(err, pos)
+}
+
+// reportErrAt reports an error at the given position of the file being parsed
+func (self *Parser) reportErrAt(err ParserErrorKind, pos tokenizer.Pos) {
+	parserErr := NewParserErr(err, pos)
+	parserErr.File = self.file
+	self.errors.push(parserErr)
 }
 
 func (self tokenBuf) String() string {
@@ -2,35 +2,38 @@ package parser
 
 import (
 	"bytes"
+	"math"
+	"strconv"
 	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/require"
+	"tomasweigenast.com/nexema/tool/diagnostic"
 	"tomasweigenast.com/nexema/tool/token"
 	"tomasweigenast.com/nexema/tool/tokenizer"
 )
 
-var literalKindExporter = cmp.AllowUnexported(BooleanLiteral{}, StringLiteral{}, IntLiteral{}, FloatLiteral{})
+var literalKindExporter = cmp.AllowUnexported(BooleanLiteral{}, StringLiteral{}, IntLiteral{}, UintLiteral{}, FloatLiteral{})
 
 func TestParser_Consume(t *testing.T) {
 	parser := newParser("abc = 123")
 
 	// read abc. Current token should be abc, next token should be =
-	err := parser.consume()
-	require.Nil(t, err)
+	parser.consume()
+	require.True(t, parser.errors.IsEmpty())
 	expectTokenBuf(t, &tokenBuf{token.NewToken(token.Ident, "abc"), tokenizer.NewPos(0, 3)}, parser.currentToken)
 	expectTokenBuf(t, &tokenBuf{token.NewToken(token.Assign), tokenizer.NewPos(4, 5)}, parser.nextToken)
 
 	// current token should be =, next token should be 123
-	err = parser.consume()
-	require.Nil(t, err)
+	parser.consume()
+	require.True(t, parser.errors.IsEmpty())
 	expectTokenBuf(t, &tokenBuf{token.NewToken(token.Assign), tokenizer.NewPos(4, 5)}, parser.currentToken)
 	expectTokenBuf(t, &tokenBuf{token.NewToken(token.Integer, "123"), tokenizer.NewPos(6, 9)}, parser.nextToken)
 
 	// current token should be 123, next token should be eof
-	err = parser.consume()
-	require.Nil(t, err)
+	parser.consume()
+	require.True(t, parser.errors.IsEmpty())
 	expectTokenBuf(t, &tokenBuf{token.NewToken(token.Integer, "123"), tokenizer.NewPos(6, 9)}, parser.currentToken)
 	expectTokenBuf(t, nil, parser.nextToken)
 }
@@ -67,7 +70,7 @@ func TestParser_Next(t *testing.T) {
 			wantAnnotationsOrComments: nil,
 			want: []tokenBuf{
 				{token.NewToken(token.Decimal, "12.42"), tokenizer.NewPos(0, 5)},
-				{token.NewToken(token.Ident, "true"), tokenizer.NewPos(15, 19, 2, 2)},
+				{token.NewToken(token.Ident, "true"), &tokenizer.Pos{Start: 46, End: 50, Line: 3, Endline: 3, Column: 16, EndColumn: 20, Column16: 16, EndColumn16: 20}},
 			},
 		},
 		{
@@ -149,6 +152,20 @@ func TestParser_ParseLiteral(t *testing.T) {
 		{"12.53", &LiteralStmt{*token.NewToken(token.Decimal, "12.53"), FloatLiteral{12.53}, *tokenizer.NewPos(0, 5)}, nil},
 		{".53", &LiteralStmt{*token.NewToken(token.Decimal, ".53"), FloatLiteral{.53}, *tokenizer.NewPos(0, 3)}, nil},
 		{"12", &LiteralStmt{*token.NewToken(token.Integer, "12"), IntLiteral{12}, *tokenizer.NewPos(0, 2)}, nil},
+		{"0xFF", &LiteralStmt{*token.NewToken(token.Integer, "0xFF"), IntLiteral{255}, *tokenizer.NewPos(0, 4)}, nil},
+		{"-0o17", &LiteralStmt{*token.NewToken(token.Integer, "-0o17"), IntLiteral{-15}, *tokenizer.NewPos(0, 5)}, nil},
+		{"0b1010_1010", &LiteralStmt{*token.NewToken(token.Integer, "0b1010_1010"), IntLiteral{170}, *tokenizer.NewPos(0, 11)}, nil},
+		{"1_000_000", &LiteralStmt{*token.NewToken(token.Integer, "1_000_000"), IntLiteral{1000000}, *tokenizer.NewPos(0, 9)}, nil},
+		{"010", &LiteralStmt{*token.NewToken(token.Integer, "010"), IntLiteral{10}, *tokenizer.NewPos(0, 3)}, nil},
+		{"1e-9", &LiteralStmt{*token.NewToken(token.Decimal, "1e-9"), FloatLiteral{1e-9}, *tokenizer.NewPos(0, 4)}, nil},
+		{"1_000.5e2", &LiteralStmt{*token.NewToken(token.Decimal, "1_000.5e2"), FloatLiteral{100050}, *tokenizer.NewPos(0, 9)}, nil},
+		{"9223372036854775807", &LiteralStmt{*token.NewToken(token.Integer, "9223372036854775807"), IntLiteral{math.MaxInt64}, *tokenizer.NewPos(0, 19)}, nil},
+		{"-9223372036854775808", &LiteralStmt{*token.NewToken(token.Integer, "-9223372036854775808"), IntLiteral{math.MinInt64}, *tokenizer.NewPos(0, 20)}, nil},
+		{"9223372036854775808", &LiteralStmt{*token.NewToken(token.Integer, "9223372036854775808"), UintLiteral{1 << 63}, *tokenizer.NewPos(0, 19)}, nil},
+		{"18446744073709551615", &LiteralStmt{*token.NewToken(token.Integer, "18446744073709551615"), UintLiteral{math.MaxUint64}, *tokenizer.NewPos(0, 20)}, nil},
+		{"0xFFFF_FFFF_FFFF_FFFF", &LiteralStmt{*token.NewToken(token.Integer, "0xFFFF_FFFF_FFFF_FFFF"), UintLiteral{math.MaxUint64}, *tokenizer.NewPos(0, 21)}, nil},
+		{"0x1_0000_0000_0000_0000", nil, NewParserErr(ErrNumberParse{&strconv.NumError{Func: "ParseInt", Num: "10000000000000000", Err: strconv.ErrRange}, "0x1_0000_0000_0000_0000"}, *tokenizer.NewPos(0, 23))},
+		{`"tab\there \u{1F600}"`, &LiteralStmt{*token.NewToken(token.String, "tab\there 😀"), StringLiteral{"tab\there 😀"}, *tokenizer.NewPos(0, 21)}, nil},
 		{`"my string"`, &LiteralStmt{*token.NewToken(token.String, "my string"), StringLiteral{"my string"}, *tokenizer.NewPos(0, 11)}, nil},
 		{"my_type", nil, NewParserErr(ErrInvalidLiteral{*token.NewToken(token.Ident, "my_type")}, *tokenizer.NewPos(0, 7))},
 		{`["hello", true, 12.343, .12, 98, false]`, &LiteralStmt{
@@ -276,6 +293,24 @@ func TestParser_ParseDecl(t *testing.T) {
 			{*token.NewToken(token.Ident, "string"), *tokenizer.NewPos(4, 10), nil, nil, true},
 			{*token.NewToken(token.Ident, "MyEnum"), *tokenizer.NewPos(13, 27), nil, &IdentStmt{*token.NewToken(token.Ident, "package"), *tokenizer.NewPos(13, 20)}, true},
 		}, nil, true}, nil},
+		{"list(list(varint?))", &DeclStmt{*token.NewToken(token.Ident, "list"), *tokenizer.NewPos(0, 19), []DeclStmt{
+			{*token.NewToken(token.Ident, "list"), *tokenizer.NewPos(5, 18), []DeclStmt{
+				{*token.NewToken(token.Ident, "varint"), *tokenizer.NewPos(10, 16), nil, nil, true},
+			}, nil, false},
+		}, nil, false}, nil},
+		{"array(float32, 3)", &DeclStmt{*token.NewToken(token.Ident, "array"), *tokenizer.NewPos(0, 17), []DeclStmt{
+			{*token.NewToken(token.Ident, "float32"), *tokenizer.NewPos(6, 13), nil, nil, false},
+			{*token.NewToken(token.Integer, "3"), *tokenizer.NewPos(15, 16), nil, nil, false},
+		}, nil, false}, nil},
+		{"string(64)?", &DeclStmt{*token.NewToken(token.Ident, "string"), *tokenizer.NewPos(0, 10), []DeclStmt{
+			{*token.NewToken(token.Integer, "64"), *tokenizer.NewPos(7, 9), nil, nil, false},
+		}, nil, true}, nil},
+		{"map(string, list(string)?)", &DeclStmt{*token.NewToken(token.Ident, "map"), *tokenizer.NewPos(0, 26), []DeclStmt{
+			{*token.NewToken(token.Ident, "string"), *tokenizer.NewPos(4, 10), nil, nil, false},
+			{*token.NewToken(token.Ident, "list"), *tokenizer.NewPos(12, 24), []DeclStmt{
+				{*token.NewToken(token.Ident, "string"), *tokenizer.NewPos(17, 23), nil, nil, false},
+			}, nil, true},
+		}, nil, false}, nil},
 	}
 
 	for _, tt := range tests {
@@ -385,7 +420,7 @@ func TestParser_ParseAnnotation(t *testing.T) {
 			parser.Reset()
 			parser.next()
 
-			stmt := parser.parseAnnotationStmt()
+			stmt := parser.parseAnnotationStmt(*tokenizer.NewPos(0, 1))
 			if tt.wantErr == nil {
 				require.Empty(t, parser.errors)
 			} else {
@@ -400,6 +435,49 @@ func TestParser_ParseAnnotation(t *testing.T) {
 	}
 }
 
+func TestParser_ParseUse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  *UseStmt
+	}{
+		{`use "foo/bar"`, &UseStmt{
+			Token: *token.NewToken(token.Use),
+			Pos:   *tokenizer.NewPos(0, 13),
+			Path: LiteralStmt{
+				Token: *token.NewToken(token.String, "foo/bar"),
+				Kind:  StringLiteral{"foo/bar"},
+				Pos:   *tokenizer.NewPos(4, 13),
+			},
+		}},
+		{`use "foo/bar" as baz`, &UseStmt{
+			Token: *token.NewToken(token.Use),
+			Pos:   *tokenizer.NewPos(0, 20),
+			Path: LiteralStmt{
+				Token: *token.NewToken(token.String, "foo/bar"),
+				Kind:  StringLiteral{"foo/bar"},
+				Pos:   *tokenizer.NewPos(4, 13),
+			},
+			Alias: &IdentStmt{
+				Token: *token.NewToken(token.Ident, "baz"),
+				Pos:   *tokenizer.NewPos(17, 20),
+			},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			parser := newParser(tt.input)
+			parser.next()
+
+			stmt := parser.parseUseStmt()
+			require.Empty(t, *parser.errors)
+			if diff := cmp.Diff(tt.want, stmt, literalKindExporter); diff != "" {
+				t.Errorf("TestParser_ParseUse: %s -> mismatch (-want +got):\n%s", tt.input, diff)
+			}
+		})
+	}
+}
+
 func TestParser_ParseField(t *testing.T) {
 	tests := []struct {
 		input                       string
@@ -486,23 +564,23 @@ func TestParser_ParseField(t *testing.T) {
 			setLine: 5,
 			inputAnnotationOrStatements: map[int]*[]annotationOrComment{
 				0: {{comment: &CommentStmt{*token.NewToken(token.Comment, "this is not documentation"), *tokenizer.NewPos()}}},
-				3: {{comment: &CommentStmt{*token.NewToken(token.Comment, "this is documentation"), *tokenizer.NewPos(0, 0, 3, 3)}}},
+				3: {{comment: &CommentStmt{*token.NewToken(token.Comment, "this is documentation"), tokenizer.Pos{Line: 3, Endline: 3}}}},
 				4: {
-					{comment: &CommentStmt{*token.NewToken(token.Comment, "this is documentation too"), *tokenizer.NewPos(0, 0, 4, 4)}},
-					{comment: &CommentStmt{*token.NewToken(token.Comment, "hello"), *tokenizer.NewPos(0, 0, 4, 4)}},
+					{comment: &CommentStmt{*token.NewToken(token.Comment, "this is documentation too"), tokenizer.Pos{Line: 4, Endline: 4}}},
+					{comment: &CommentStmt{*token.NewToken(token.Comment, "hello"), tokenizer.Pos{Line: 4, Endline: 4}}},
 				},
 			},
 			want: &FieldStmt{
-				Index: &IdentStmt{*token.NewToken(token.Integer, "1"), *tokenizer.NewPos(0, 1, 5, 5)},
+				Index: &IdentStmt{*token.NewToken(token.Integer, "1"), tokenizer.Pos{Start: 0, End: 1, Line: 5, Endline: 5, Column: 1, EndColumn: 2, Column16: 1, EndColumn16: 2}},
 				Name:  IdentStmt{*token.NewToken(token.Ident, "my_field"), *tokenizer.NewPos(2, 10)},
 				ValueType: &DeclStmt{*token.NewToken(token.Ident, "map"), *tokenizer.NewPos(11, 27), []DeclStmt{
 					{*token.NewToken(token.Ident, "string"), *tokenizer.NewPos(15, 21), nil, nil, false},
 					{*token.NewToken(token.Ident, "bool"), *tokenizer.NewPos(22, 26), nil, nil, false},
 				}, nil, false},
 				Documentation: []CommentStmt{
-					{*token.NewToken(token.Comment, "this is documentation"), *tokenizer.NewPos(0, 0, 3, 3)},
-					{*token.NewToken(token.Comment, "hello"), *tokenizer.NewPos(0, 0, 4, 4)},
-					{*token.NewToken(token.Comment, "this is documentation too"), *tokenizer.NewPos(0, 0, 4, 4)},
+					{*token.NewToken(token.Comment, "this is documentation"), tokenizer.Pos{Line: 3, Endline: 3}},
+					{*token.NewToken(token.Comment, "hello"), tokenizer.Pos{Line: 4, Endline: 4}},
+					{*token.NewToken(token.Comment, "this is documentation too"), tokenizer.Pos{Line: 4, Endline: 4}},
 				},
 				Annotations: nil,
 			},
@@ -521,8 +599,10 @@ func TestParser_ParseField(t *testing.T) {
 				}
 			}
 
-			parser.currentToken.position.Line = tt.setLine
-			parser.currentToken.position.Endline = tt.setLine
+			if tt.setLine != 0 {
+				parser.currentToken.position.Line = tt.setLine
+				parser.currentToken.position.Endline = tt.setLine
+			}
 
 			stmt := parser.parseFieldStmt(tt.isEnum)
 			if tt.wantErr == nil {
@@ -767,7 +847,7 @@ func TestParser_ParseTypeStmt(t *testing.T) {
 				
 			}`,
 			want:    nil,
-			wantErr: NewParserErr(ErrUnexpectedEOF{}, *tokenizer.NewPos(4, 4, 8, 8)),
+			wantErr: NewParserErr(ErrUnexpectedEOF{}, tokenizer.Pos{Start: 116, End: 116, Line: 9, Endline: 9, Column: 5, EndColumn: 5, Column16: 5, EndColumn16: 5}),
 		},
 		{
 			name: "extends with alias and defaults",
@@ -828,6 +908,318 @@ func TestParser_ParseTypeStmt(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "enum with annotations, docs and aliases",
+			input: `type Color enum {
+				unknown
+
+				/// The red color
+				#default = true
+				red
+				rojo = red
+			}`,
+			want: &TypeStmt{
+				Name:     IdentStmt{*token.NewToken(token.Ident, "Color"), *tokenizer.NewPos()},
+				Modifier: token.Enum,
+				Fields: []FieldStmt{
+					{Name: IdentStmt{Token: *token.NewToken(token.Ident, "unknown")}},
+					{
+						Name: IdentStmt{Token: *token.NewToken(token.Ident, "red")},
+						Documentation: []CommentStmt{
+							{Token: *token.NewToken(token.DocComment, " The red color")},
+						},
+						Annotations: []AnnotationStmt{
+							{
+								Token: *token.NewToken(token.Hash),
+								Assigment: AssignStmt{
+									Token: *token.NewToken(token.Assign),
+									Left:  IdentStmt{Token: *token.NewToken(token.Ident, "default")},
+									Right: LiteralStmt{
+										Token: *token.NewToken(token.Ident, "true"),
+										Kind:  BooleanLiteral{true},
+									},
+								},
+							},
+						},
+					},
+					{
+						Name:    IdentStmt{Token: *token.NewToken(token.Ident, "rojo")},
+						AliasOf: &IdentStmt{Token: *token.NewToken(token.Ident, "red")},
+					},
+				},
+			},
+		},
+		{
+			name:  "enum alias without member",
+			input: `type Color enum { unknown none = 1 }`,
+			want: &TypeStmt{
+				Name:     IdentStmt{*token.NewToken(token.Ident, "Color"), *tokenizer.NewPos()},
+				Modifier: token.Enum,
+				Fields: []FieldStmt{
+					{Name: IdentStmt{Token: *token.NewToken(token.Ident, "unknown")}},
+				},
+			},
+			wantErr: NewParserErr(ErrExpectedIdentifier{*token.NewToken(token.Integer, "1")}, *tokenizer.NewPos(33, 34)),
+		},
+		{
+			name: "flags",
+			input: `type Permission flags(uint8) {
+				read
+				4 write
+				read_write = read | write
+			}`,
+			want: &TypeStmt{
+				Name:           IdentStmt{*token.NewToken(token.Ident, "Permission"), *tokenizer.NewPos()},
+				Modifier:       token.Flags,
+				UnderlyingType: &IdentStmt{Token: *token.NewToken(token.Ident, "uint8")},
+				Fields: []FieldStmt{
+					{Name: IdentStmt{Token: *token.NewToken(token.Ident, "read")}},
+					{
+						Index: &IdentStmt{Token: *token.NewToken(token.Integer, "4")},
+						Name:  IdentStmt{Token: *token.NewToken(token.Ident, "write")},
+					},
+					{
+						Name: IdentStmt{Token: *token.NewToken(token.Ident, "read_write")},
+						Combines: []IdentStmt{
+							{Token: *token.NewToken(token.Ident, "read")},
+							{Token: *token.NewToken(token.Ident, "write")},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "flags without underlying type",
+			input: `type Permission flags { read }`,
+			want: &TypeStmt{
+				Name:     IdentStmt{*token.NewToken(token.Ident, "Permission"), *tokenizer.NewPos()},
+				Modifier: token.Flags,
+				Fields: []FieldStmt{
+					{Name: IdentStmt{Token: *token.NewToken(token.Ident, "read")}},
+				},
+			},
+		},
+		{
+			name:  "fields named flags",
+			input: `type Settings struct { 0 flags uint32 mode flags.Mode }`,
+			want: &TypeStmt{
+				Name:     IdentStmt{*token.NewToken(token.Ident, "Settings"), *tokenizer.NewPos()},
+				Modifier: token.Struct,
+				Fields: []FieldStmt{
+					{
+						Index:     &IdentStmt{Token: *token.NewToken(token.Integer, "0")},
+						Name:      IdentStmt{Token: *token.NewToken(token.Ident, "flags")},
+						ValueType: &DeclStmt{Token: *token.NewToken(token.Ident, "uint32")},
+					},
+					{
+						Name: IdentStmt{Token: *token.NewToken(token.Ident, "mode")},
+						ValueType: &DeclStmt{
+							Token: *token.NewToken(token.Ident, "Mode"),
+							Alias: &IdentStmt{Token: *token.NewToken(token.Ident, "flags")},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "enum member named flags",
+			input: `type Option enum { unknown flags }`,
+			want: &TypeStmt{
+				Name:     IdentStmt{*token.NewToken(token.Ident, "Option"), *tokenizer.NewPos()},
+				Modifier: token.Enum,
+				Fields: []FieldStmt{
+					{Name: IdentStmt{Token: *token.NewToken(token.Ident, "unknown")}},
+					{Name: IdentStmt{Token: *token.NewToken(token.Ident, "flags")}},
+				},
+			},
+		},
+		{
+			name: "doc and trailing comments",
+			input: `
+			/// A user of the platform
+			type User struct {
+				/// The id of the user
+				id string // must be unique
+				// The display name
+				name string /// shown in the profile
+			}`,
+			want: &TypeStmt{
+				Name:     IdentStmt{*token.NewToken(token.Ident, "User"), *tokenizer.NewPos()},
+				Modifier: token.Struct,
+				Documentation: []CommentStmt{
+					{Token: *token.NewToken(token.DocComment, " A user of the platform")},
+				},
+				Fields: []FieldStmt{
+					{
+						Name:      IdentStmt{Token: *token.NewToken(token.Ident, "id")},
+						ValueType: &DeclStmt{Token: *token.NewToken(token.Ident, "string")},
+						Documentation: []CommentStmt{
+							{Token: *token.NewToken(token.DocComment, " The id of the user")},
+							{Token: *token.NewToken(token.Comment, " must be unique")},
+						},
+					},
+					{
+						Name:      IdentStmt{Token: *token.NewToken(token.Ident, "name")},
+						ValueType: &DeclStmt{Token: *token.NewToken(token.Ident, "string")},
+						Documentation: []CommentStmt{
+							{Token: *token.NewToken(token.Comment, " The display name")},
+							{Token: *token.NewToken(token.DocComment, " shown in the profile")},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "oneof",
+			input: `type Contact struct {
+				1 name string
+				/// How to reach the contact
+				oneof method {
+					2 email string
+					3 phone string // with country code
+				}
+				4 notes string
+			}`,
+			want: &TypeStmt{
+				Name:     IdentStmt{*token.NewToken(token.Ident, "Contact"), *tokenizer.NewPos()},
+				Modifier: token.Struct,
+				Fields: []FieldStmt{
+					{
+						Index:     &IdentStmt{Token: *token.NewToken(token.Integer, "1")},
+						Name:      IdentStmt{Token: *token.NewToken(token.Ident, "name")},
+						ValueType: &DeclStmt{Token: *token.NewToken(token.Ident, "string")},
+					},
+					{
+						Index:     &IdentStmt{Token: *token.NewToken(token.Integer, "2")},
+						Name:      IdentStmt{Token: *token.NewToken(token.Ident, "email")},
+						ValueType: &DeclStmt{Token: *token.NewToken(token.Ident, "string")},
+						Oneof:     &IdentStmt{Token: *token.NewToken(token.Ident, "method")},
+					},
+					{
+						Index:     &IdentStmt{Token: *token.NewToken(token.Integer, "3")},
+						Name:      IdentStmt{Token: *token.NewToken(token.Ident, "phone")},
+						ValueType: &DeclStmt{Token: *token.NewToken(token.Ident, "string")},
+						Oneof:     &IdentStmt{Token: *token.NewToken(token.Ident, "method")},
+						Documentation: []CommentStmt{
+							{Token: *token.NewToken(token.Comment, " with country code")},
+						},
+					},
+					{
+						Index:     &IdentStmt{Token: *token.NewToken(token.Integer, "4")},
+						Name:      IdentStmt{Token: *token.NewToken(token.Ident, "notes")},
+						ValueType: &DeclStmt{Token: *token.NewToken(token.Ident, "string")},
+					},
+				},
+				Oneofs: []OneofStmt{
+					{
+						Name: IdentStmt{Token: *token.NewToken(token.Ident, "method")},
+						Documentation: []CommentStmt{
+							{Token: *token.NewToken(token.DocComment, " How to reach the contact")},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "oneof without name",
+			input: `type Contact struct { oneof { email string } }`,
+			want: &TypeStmt{
+				Name:     IdentStmt{*token.NewToken(token.Ident, "Contact"), *tokenizer.NewPos()},
+				Modifier: token.Struct,
+			},
+			wantErr: NewParserErr(ErrExpectedIdentifier{*token.NewToken(token.Lbrace)}, *tokenizer.NewPos(28, 29)),
+		},
+		{
+			name:  "fields named oneof",
+			input: "type Choice struct {\n0 oneof string\noneof bool // trailing\noneof value { a string }\n}",
+			want: &TypeStmt{
+				Name:     IdentStmt{*token.NewToken(token.Ident, "Choice"), *tokenizer.NewPos()},
+				Modifier: token.Struct,
+				Fields: []FieldStmt{
+					{
+						Index:     &IdentStmt{Token: *token.NewToken(token.Integer, "0")},
+						Name:      IdentStmt{Token: *token.NewToken(token.Ident, "oneof")},
+						ValueType: &DeclStmt{Token: *token.NewToken(token.Ident, "string")},
+					},
+					{
+						Name:          IdentStmt{Token: *token.NewToken(token.Ident, "oneof")},
+						ValueType:     &DeclStmt{Token: *token.NewToken(token.Ident, "bool")},
+						Documentation: []CommentStmt{{Token: *token.NewToken(token.Comment, " trailing")}},
+					},
+					{
+						Name:      IdentStmt{Token: *token.NewToken(token.Ident, "a")},
+						ValueType: &DeclStmt{Token: *token.NewToken(token.Ident, "string")},
+						Oneof:     &IdentStmt{Token: *token.NewToken(token.Ident, "value")},
+					},
+				},
+				Oneofs: []OneofStmt{
+					{Name: IdentStmt{Token: *token.NewToken(token.Ident, "value")}},
+				},
+			},
+		},
+		{
+			name:    "nested oneof",
+			input:   `type Contact struct { oneof method { oneof other { email string } } }`,
+			want:    nil,
+			wantErr: NewParserErr(ErrUnexpectedValue{"field", *token.NewToken(token.Oneof)}, *tokenizer.NewPos(37, 42)),
+		},
+		{
+			name: "raw and multi-line strings",
+			input: "type Query struct {\n" +
+				"\t#pattern = `^\\d+$`\n" +
+				"\t#sql = `SELECT *\n" +
+				"\t\tFROM users`\n" +
+				"\ttext string\n" +
+				"\tdefaults {\n" +
+				"\t\ttext = `a\n" +
+				"b`\n" +
+				"\t}\n" +
+				"}",
+			want: &TypeStmt{
+				Name:     IdentStmt{*token.NewToken(token.Ident, "Query"), *tokenizer.NewPos()},
+				Modifier: token.Struct,
+				Fields: []FieldStmt{
+					{
+						Name:      IdentStmt{Token: *token.NewToken(token.Ident, "text")},
+						ValueType: &DeclStmt{Token: *token.NewToken(token.Ident, "string")},
+						Annotations: []AnnotationStmt{
+							{
+								Token: *token.NewToken(token.Hash),
+								Assigment: AssignStmt{
+									Token: *token.NewToken(token.Assign),
+									Left:  IdentStmt{Token: *token.NewToken(token.Ident, "pattern")},
+									Right: LiteralStmt{
+										Token: *token.NewToken(token.String, `^\d+$`),
+										Kind:  StringLiteral{`^\d+$`},
+									},
+								},
+							},
+							{
+								Token: *token.NewToken(token.Hash),
+								Assigment: AssignStmt{
+									Token: *token.NewToken(token.Assign),
+									Left:  IdentStmt{Token: *token.NewToken(token.Ident, "sql")},
+									Right: LiteralStmt{
+										Token: *token.NewToken(token.String, "SELECT *\n\t\tFROM users"),
+										Kind:  StringLiteral{"SELECT *\n\t\tFROM users"},
+									},
+								},
+							},
+						},
+					},
+				},
+				Defaults: []AssignStmt{
+					{
+						Token: *token.NewToken(token.Assign),
+						Left:  IdentStmt{Token: *token.NewToken(token.Ident, "text")},
+						Right: LiteralStmt{
+							Token: *token.NewToken(token.String, "a\nb"),
+							Kind:  StringLiteral{"a\nb"},
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "modifier with extends is syntax error",
 			input: `type Color enum extends Base {
@@ -1134,6 +1526,227 @@ func TestParser_Parse(t *testing.T) {
 	}
 }
 
+func TestParser_ParseHeader(t *testing.T) {
+	tests := []struct {
+		name              string
+		input             string
+		wantDocumentation []CommentStmt
+		wantAnnotations   []AnnotationStmt
+		wantTypeDocs      []CommentStmt
+	}{
+		{
+			name: "header before use statements",
+			input: `// Package doc
+			#version = 2
+			use "foo"
+
+			type A struct {}`,
+			wantDocumentation: []CommentStmt{{Token: *token.NewToken(token.Comment, " Package doc")}},
+			wantAnnotations: []AnnotationStmt{
+				{
+					Token: *token.NewToken(token.Hash),
+					Assigment: AssignStmt{
+						Token: *token.NewToken(token.Assign),
+						Left:  IdentStmt{Token: *token.NewToken(token.Ident, "version")},
+						Right: LiteralStmt{Token: *token.NewToken(token.Integer, "2"), Kind: IntLiteral{2}},
+					},
+				},
+			},
+		},
+		{
+			name: "header separated from the first type",
+			input: `// Package doc
+
+			// Type doc
+			type A struct {}`,
+			wantDocumentation: []CommentStmt{{Token: *token.NewToken(token.Comment, " Package doc")}},
+			wantTypeDocs:      []CommentStmt{{Token: *token.NewToken(token.Comment, " Type doc")}},
+		},
+		{
+			name: "comments attached to the first type are not header",
+			input: `// Type doc
+			type A struct {}`,
+			wantTypeDocs: []CommentStmt{{Token: *token.NewToken(token.Comment, " Type doc")}},
+		},
+		{
+			name:              "file without statements",
+			input:             `// Only a comment`,
+			wantDocumentation: []CommentStmt{{Token: *token.NewToken(token.Comment, " Only a comment")}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := newParser(tt.input)
+			parser.next()
+
+			got := parser.Parse()
+			require.Empty(t, parser.errors)
+
+			ignorePos := cmp.FilterPath(func(p cmp.Path) bool {
+				return strings.Contains(p.String(), "Pos")
+			}, cmp.Ignore())
+
+			if diff := cmp.Diff(tt.wantDocumentation, got.Documentation, ignorePos); diff != "" {
+				t.Errorf("TestParser_ParseHeader: %s -> documentation mismatch (-want +got):\n%s", tt.name, diff)
+			}
+
+			if diff := cmp.Diff(tt.wantAnnotations, got.Annotations, literalKindExporter, ignorePos); diff != "" {
+				t.Errorf("TestParser_ParseHeader: %s -> annotations mismatch (-want +got):\n%s", tt.name, diff)
+			}
+
+			var typeDocs []CommentStmt
+			if len(got.TypeStatements) > 0 {
+				typeDocs = got.TypeStatements[0].Documentation
+			}
+
+			if diff := cmp.Diff(tt.wantTypeDocs, typeDocs, ignorePos); diff != "" {
+				t.Errorf("TestParser_ParseHeader: %s -> type documentation mismatch (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
+
+func TestParser_Diagnostics(t *testing.T) {
+	file := &File{Path: "foo", FileName: "bar.nex"}
+	parser := NewParser(bytes.NewBufferString("type A struct {\n\t0 ñame string\n\t1 id"), file)
+	parser.Begin()
+	parser.Parse()
+
+	require.Equal(t, diagnostic.Diagnostics{
+		diagnostic.New(diagnostic.Error, "NX0101", "unexpected end of file", "foo/bar.nex", tokenizer.Pos{Start: 37, End: 37, Line: 3, Endline: 3, Column: 6, EndColumn: 6, Column16: 6, EndColumn16: 6}),
+	}, parser.Errors().Diagnostics())
+}
+
+func TestParser_ErrorRecovery(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantErrors []string
+		wantTypes  []string
+	}{
+		{
+			name: "unknown type modifiers",
+			input: `type A strct {
+				0 a string
+			}
+
+			type B struct {
+				0 b string
+			}
+
+			type C unon {}`,
+			wantErrors: []string{
+				`1:8 -> expected type modifier, got ident(strct) instead`,
+				`9:11 -> expected type modifier, got ident(unon) instead`,
+			},
+			wantTypes: []string{"B"},
+		},
+		{
+			name: "invalid fields",
+			input: `type A struct {
+				0 a string
+				1 b map(string int)
+				2 c 12
+				3 d bool
+			}
+
+			type B enum {
+				x = 1
+				y
+			}`,
+			wantErrors: []string{
+				`3:20 -> expected token to be ), got ident(int) instead`,
+				`4:9 -> expected identifier, got integer(12) instead`,
+				`9:9 -> expected identifier, got integer(1) instead`,
+			},
+			wantTypes: []string{"A", "B"},
+		},
+		{
+			name: "missing closing brace and invalid declarations",
+			input: `use "foo"
+			use 12
+			use "bar" as
+
+			type A struct {
+				0 a string
+
+			type B struct {
+				0 b string
+			}
+
+			field x
+
+			type C struct {
+				oneof value {
+					0 c string
+				type D struct {}`,
+			wantErrors: []string{
+				`2:8 -> expected literal with import path, got integer(12) instead`,
+				`5:4 -> expected token to be ident, got type(type) instead`,
+				`8:4 -> expected token to be }, got type(type) instead`,
+				`12:4 -> expected declaration, got ident(field) instead`,
+				`17:5 -> expected token to be }, got type(type) instead`,
+			},
+			wantTypes: []string{"B", "D"},
+		},
+		{
+			name: "invalid defaults and misplaced use",
+			input: `type A struct {
+				0 a string
+				defaults {
+					a =
+				}
+			}
+			type B struct {}
+			use "late"
+			type 1 struct {}
+			type D struct {}`,
+			wantErrors: []string{
+				`5:5 -> }(}) is not a valid literal value`,
+				`8:4 -> expected declaration, got use(use) instead`,
+				`9:9 -> expected identifier, got integer(1) instead`,
+			},
+			wantTypes: []string{"B", "D"},
+		},
+		{
+			name: "invalid tokens",
+			input: `type A struct {
+				0x1__0 a string
+				1 b <string
+				defaults {
+					a = "x\dy \q z"
+					b = 1__0
+				}
+			}`,
+			wantErrors: []string{
+				`2:9 -> invalid number literal: 0x1_`,
+				`3:9 -> unknown token: <`,
+				`5:12 -> invalid escape sequence in string literal: \d`,
+				`6:12 -> invalid number literal: 1_`,
+			},
+			wantTypes: []string{"A"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := newParser(tt.input)
+			parser.next()
+
+			got := parser.Parse()
+			require.Equal(t, strings.Join(tt.wantErrors, "\n"), parser.errors.Display())
+
+			types := make([]string, len(got.TypeStatements))
+			for i, stmt := range got.TypeStatements {
+				types[i] = stmt.Name.Token.Literal
+			}
+
+			require.Equal(t, tt.wantTypes, types)
+		})
+	}
+}
+
 func expectTokenBuf(t *testing.T, expected, given *tokenBuf) {
 	if expected == nil {
 		if given != nil {
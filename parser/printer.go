@@ -0,0 +1,302 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"tomasweigenast.com/nexema/tool/token"
+)
+
+// Print returns the source of ast, which parses back to an equivalent Ast.
+//
+// Only what the Ast keeps is written, so comments that are not the documentation of the file or of a statement are
+// lost, and the documentation of a statement, including the comments written after a field in its same line, is
+// written before it. Use the format package to format a file keeping all of its comments.
+func Print(ast *Ast) string {
+	printer := new(astPrinter)
+	printer.printAst(ast)
+	return printer.out.String()
+}
+
+// astPrinter writes the statements of an Ast, one per line, indented with tabs
+type astPrinter struct {
+	out    strings.Builder
+	indent int
+}
+
+func (self *astPrinter) printAst(ast *Ast) {
+	// the header must be separated from the first statement, otherwise it would become the documentation of the first type
+	header := len(ast.Documentation) > 0 || len(ast.Annotations) > 0
+	self.printDocs(ast.Documentation, ast.Annotations)
+
+	if len(ast.UseStatements) > 0 {
+		if header {
+			self.out.WriteString("\n")
+		}
+
+		for _, stmt := range ast.UseStatements {
+			self.line("use ", printLiteral(stmt.Path))
+			if stmt.Alias != nil {
+				self.out.WriteString(" as " + stmt.Alias.Token.Literal)
+			}
+			self.out.WriteString("\n")
+		}
+	}
+
+	for i := range ast.TypeStatements {
+		if i > 0 || header || len(ast.UseStatements) > 0 {
+			self.out.WriteString("\n")
+		}
+
+		self.printType(&ast.TypeStatements[i])
+	}
+}
+
+func (self *astPrinter) printType(stmt *TypeStmt) {
+	self.printDocs(stmt.Documentation, stmt.Annotations)
+	self.line("type ", stmt.Name.Token.Literal, " ")
+	switch {
+	case stmt.BaseType != nil:
+		self.out.WriteString("extends " + printDecl(stmt.BaseType))
+	case stmt.UnderlyingType != nil:
+		self.out.WriteString(stmt.Modifier.String() + "(" + stmt.UnderlyingType.Token.Literal + ")")
+	default:
+		self.out.WriteString(stmt.Modifier.String())
+	}
+
+	if len(stmt.Fields) == 0 && len(stmt.Oneofs) == 0 && len(stmt.Defaults) == 0 {
+		self.out.WriteString(" {}\n")
+		return
+	}
+
+	self.out.WriteString(" {\n")
+	self.indent++
+
+	// the fields of a group are written in its block, where the first of them is, and empty groups are written
+	// after the ones declared before them, so the groups keep their order
+	oneofs := 0
+	printOneofsUntil := func(name string) {
+		for oneofs < len(stmt.Oneofs) {
+			oneof := &stmt.Oneofs[oneofs]
+			oneofs++
+			self.printOneof(oneof, stmt.Fields)
+			if oneof.Name.Token.Literal == name {
+				return
+			}
+		}
+	}
+
+	for i := 0; i < len(stmt.Fields); i++ {
+		field := &stmt.Fields[i]
+		if field.Oneof == nil {
+			self.printField(field)
+			continue
+		}
+
+		printOneofsUntil(field.Oneof.Token.Literal)
+		for i+1 < len(stmt.Fields) && stmt.Fields[i+1].Oneof != nil && stmt.Fields[i+1].Oneof.Token.Literal == field.Oneof.Token.Literal {
+			i++
+		}
+	}
+	printOneofsUntil("")
+
+	if len(stmt.Defaults) > 0 {
+		self.out.WriteString("\n")
+		self.line("defaults {\n")
+		self.indent++
+		for _, assign := range stmt.Defaults {
+			self.line(printAssign(assign), "\n")
+		}
+		self.indent--
+		self.line("}\n")
+	}
+
+	self.indent--
+	self.line("}\n")
+}
+
+// printOneof writes the group oneof with its fields, taken from fields
+func (self *astPrinter) printOneof(oneof *OneofStmt, fields []FieldStmt) {
+	self.printDocs(oneof.Documentation, oneof.Annotations)
+	self.line("oneof ", oneof.Name.Token.Literal, " {\n")
+	self.indent++
+	for i := range fields {
+		if fields[i].Oneof != nil && fields[i].Oneof.Token.Literal == oneof.Name.Token.Literal {
+			self.printField(&fields[i])
+		}
+	}
+	self.indent--
+	self.line("}\n")
+}
+
+func (self *astPrinter) printField(stmt *FieldStmt) {
+	self.printDocs(stmt.Documentation, stmt.Annotations)
+	self.line()
+	if stmt.Index != nil {
+		self.out.WriteString(stmt.Index.Token.Literal + " ")
+	}
+
+	self.out.WriteString(stmt.Name.Token.Literal)
+	switch {
+	case stmt.ValueType != nil:
+		self.out.WriteString(" " + printDecl(stmt.ValueType))
+	case stmt.AliasOf != nil:
+		self.out.WriteString(" = " + stmt.AliasOf.Token.Literal)
+	case len(stmt.Combines) > 0:
+		members := make([]string, len(stmt.Combines))
+		for i, member := range stmt.Combines {
+			members[i] = member.Token.Literal
+		}
+		self.out.WriteString(" = " + strings.Join(members, " | "))
+	}
+
+	self.out.WriteString("\n")
+}
+
+// printDocs writes the documentation comments and then the annotations of a statement
+func (self *astPrinter) printDocs(comments []CommentStmt, annotations []AnnotationStmt) {
+	for _, comment := range comments {
+		self.line(printComment(comment), "\n")
+	}
+
+	for _, annotation := range annotations {
+		self.line("#", printAssign(annotation.Assigment), "\n")
+	}
+}
+
+// line starts a new line at the current indentation, writing parts in it
+func (self *astPrinter) line(parts ...string) {
+	self.out.WriteString(strings.Repeat("\t", self.indent))
+	for _, part := range parts {
+		self.out.WriteString(part)
+	}
+}
+
+func printComment(comment CommentStmt) string {
+	switch comment.Token.Kind {
+	case token.DocComment:
+		return "///" + comment.Token.Literal
+	case token.DocCommentMultiline:
+		return "/**" + comment.Token.Literal + "*/"
+	case token.CommentMultiline:
+		return "/*" + comment.Token.Literal + "*/"
+	}
+
+	return "//" + comment.Token.Literal
+}
+
+func printAssign(stmt AssignStmt) string {
+	return stmt.Left.Token.Literal + " = " + printLiteral(stmt.Right)
+}
+
+// printDecl returns the source of a value type, like list(string)?, or of a length argument
+func printDecl(stmt *DeclStmt) string {
+	out := stmt.Token.Literal
+	if stmt.Alias != nil {
+		out = stmt.Alias.Token.Literal + "." + out
+	}
+
+	if len(stmt.Args) > 0 {
+		args := make([]string, len(stmt.Args))
+		for i := range stmt.Args {
+			args[i] = printDecl(&stmt.Args[i])
+		}
+		out += "(" + strings.Join(args, ", ") + ")"
+	}
+
+	if stmt.Nullable {
+		out += "?"
+	}
+
+	return out
+}
+
+// printLiteral returns the source of a literal. Numbers are written as they were, if they were parsed, so a
+// hexadecimal number is still hexadecimal
+func printLiteral(stmt LiteralStmt) string {
+	switch kind := stmt.Kind.(type) {
+	case StringLiteral:
+		return quote(kind.value)
+
+	case IntLiteral:
+		if stmt.Token.Kind == token.Integer && len(stmt.Token.Literal) > 0 {
+			return stmt.Token.Literal
+		}
+
+		return strconv.FormatInt(kind.value, 10)
+
+	case UintLiteral:
+		if stmt.Token.Kind == token.Integer && len(stmt.Token.Literal) > 0 {
+			return stmt.Token.Literal
+		}
+
+		return strconv.FormatUint(kind.value, 10)
+
+	case FloatLiteral:
+		if stmt.Token.Kind == token.Decimal && len(stmt.Token.Literal) > 0 {
+			return stmt.Token.Literal
+		}
+
+		out := strconv.FormatFloat(kind.value, 'f', -1, 64)
+		if !strings.Contains(out, ".") {
+			out += ".0"
+		}
+		return out
+
+	case BooleanLiteral:
+		return kind.Literal()
+
+	case ListLiteral:
+		elems := make([]string, len(kind))
+		for i, elem := range kind {
+			elems[i] = printLiteral(elem)
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+
+	case MapLiteral:
+		entries := make([]string, len(kind))
+		for i, entry := range kind {
+			entries[i] = printLiteral(entry.Key) + ": " + printLiteral(entry.Value)
+		}
+		return "{" + strings.Join(entries, ", ") + "}"
+	}
+
+	return stmt.Token.Literal
+}
+
+// quote returns s between double quotes, escaping the characters the tokenizer decodes
+func quote(s string) string {
+	var out strings.Builder
+	out.WriteByte('"')
+	for _, ch := range s {
+		switch ch {
+		case '\\', '"':
+			out.WriteRune('\\')
+			out.WriteRune(ch)
+		case '\n':
+			out.WriteString(`\n`)
+		case '\r':
+			out.WriteString(`\r`)
+		case '\t':
+			out.WriteString(`\t`)
+		case '\b':
+			out.WriteString(`\b`)
+		case '\f':
+			out.WriteString(`\f`)
+		case '\v':
+			out.WriteString(`\v`)
+		case 0:
+			out.WriteString(`\0`)
+		default:
+			if ch < ' ' || ch == 0x7f {
+				fmt.Fprintf(&out, `\u{%x}`, ch)
+				continue
+			}
+
+			out.WriteRune(ch)
+		}
+	}
+	out.WriteByte('"')
+	return out.String()
+}
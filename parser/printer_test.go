@@ -0,0 +1,166 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/require"
+	"tomasweigenast.com/nexema/tool/token"
+	"tomasweigenast.com/nexema/tool/tokenizer"
+)
+
+func TestPrint(t *testing.T) {
+	input := `// Package identity
+#version = 1
+use "common"
+use "geo/address" as addr
+/// A user
+#obsolete = true
+type User extends common.Entity {
+	1 name string // the full name
+	2 tags list(string)?
+	address addr.Address
+	#deprecated = "use contact"
+	oneof contact {
+		email string
+		phone string(16)
+	}
+	defaults {
+		tags = ["a", "b"]
+	}
+}
+type Empty struct {}
+type Permission flags(uint8) {
+	read
+	128 write
+	all = read | write
+}
+`
+
+	want := `// Package identity
+#version = 1
+
+use "common"
+use "geo/address" as addr
+
+/// A user
+#obsolete = true
+type User extends common.Entity {
+	// the full name
+	1 name string
+	2 tags list(string)?
+	address addr.Address
+	#deprecated = "use contact"
+	oneof contact {
+		email string
+		phone string(16)
+	}
+
+	defaults {
+		tags = ["a", "b"]
+	}
+}
+
+type Empty struct {}
+
+type Permission flags(uint8) {
+	read
+	128 write
+	all = read | write
+}
+`
+
+	ast := parse(t, input)
+	require.Equal(t, want, Print(ast))
+}
+
+func TestPrint_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "empty file",
+			input: "",
+		},
+		{
+			name:  "header without statements",
+			input: "// just a comment\n#version = 2",
+		},
+		{
+			name:  "header before a type",
+			input: "// Package foo\n\n// A is documented\ntype A struct { a string }",
+		},
+		{
+			name:  "uses without header",
+			input: "use \"a\"\nuse \"b\" as b\ntype A struct { x b.B }",
+		},
+		{
+			name:  "structs",
+			input: "type A struct {\n0x1 a map(string, list(bool?))\n2 b array(float32, 3)?\nc b.C\n}",
+		},
+		{
+			name:  "enums",
+			input: "type Status enum {\n/** unknown\n * status */\nunknown\nactive // currently active\ninactive = unknown\n}",
+		},
+		{
+			name:  "flags",
+			input: "type F flags {\na\nb\nab = a | b\n}",
+		},
+		{
+			name:  "base and union",
+			input: "type B base { id string }\ntype U union { a string\nb int32 }",
+		},
+		{
+			name:  "oneof groups",
+			input: "type A struct {\nfirst string\n// the value\n#tag = true\noneof value {\n text string\n number int64\n}\noneof empty {}\nlast bool\noneof other { x string }\n}",
+		},
+		{
+			name:  "literals",
+			input: "#a = \"quote \\\" slash \\\\ line \\n tab \\t null \\0 bell \\u{7}\"\n#b = `raw\nstring`\n#c = -0b101\n#d = 1.5e-9\n#e = {\"k\": [1, 2], \"j\": {}}\n#f = []\n#g = false\n\ntype A struct {}",
+		},
+		{
+			name:  "defaults",
+			input: "type A struct {\na int32\nb float64\ndefaults {\na = 1_000\nb = 2.0\n}\n}",
+		},
+		{
+			name:  "comments",
+			input: "//// four slashes\n/// doc\n//\ntype A struct {\n///\n// a\na string /// trailing doc\n}",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ast := parse(t, test.input)
+			printed := Print(ast)
+			got := parse(t, printed)
+			if diff := cmp.Diff(ast, got, literalKindExporter, cmpopts.IgnoreTypes(tokenizer.Pos{}), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("TestPrint_RoundTrip: %s: printed source is not equivalent (-want +got):\n%s\nprinted:\n%s", test.name, diff, printed)
+			}
+
+			require.Equal(t, printed, Print(got), "printing is not stable")
+		})
+	}
+}
+
+func TestPrint_ConstructedLiterals(t *testing.T) {
+	ast := &Ast{
+		Annotations: []AnnotationStmt{
+			{Assigment: AssignStmt{Left: IdentStmt{Token: *token.NewToken(token.Ident, "ratio")}, Right: LiteralStmt{Kind: MakeFloatLiteral(2)}}},
+			{Assigment: AssignStmt{Left: IdentStmt{Token: *token.NewToken(token.Ident, "count")}, Right: LiteralStmt{Kind: MakeIntLiteral(-3)}}},
+			{Assigment: AssignStmt{Left: IdentStmt{Token: *token.NewToken(token.Ident, "names")}, Right: LiteralStmt{Kind: MakeListLiteral(LiteralStmt{Kind: MakeStringLiteral("a\"b")})}}},
+		},
+	}
+
+	require.Equal(t, "#ratio = 2.0\n#count = -3\n#names = [\"a\\\"b\"]\n", Print(ast))
+}
+
+// parse parses input, requiring it to be valid
+func parse(t *testing.T, input string) *Ast {
+	p := newParser(input)
+	p.next()
+	ast := p.Parse()
+	require.Empty(t, p.Errors().Diagnostics(), "input:\n%s", input)
+	return ast
+}
@@ -0,0 +1,127 @@
+package parser
+
+// Visitor is called by Walk for the types, fields, value types and literals of an Ast. Each method receives a
+// pointer to the statement in the Ast, so it can be modified in place to rewrite the Ast, and returns false to
+// skip the statements declared inside it.
+type Visitor interface {
+	VisitType(stmt *TypeStmt) bool
+	VisitField(stmt *FieldStmt) bool
+	VisitDecl(stmt *DeclStmt) bool
+	VisitLiteral(stmt *LiteralStmt) bool
+}
+
+// Rewriter is a Visitor that calls the functions it declares, visiting the statements whose function is nil and
+// everything declared inside them
+type Rewriter struct {
+	Type    func(stmt *TypeStmt) bool
+	Field   func(stmt *FieldStmt) bool
+	Decl    func(stmt *DeclStmt) bool
+	Literal func(stmt *LiteralStmt) bool
+}
+
+// Walk visits the statements of ast in the order they are declared, a statement before the ones declared inside
+// it:
+//
+//   - the literals of the annotations of the file, and the paths of its use statements
+//   - every type, and then its base type, the literals of its annotations, its fields and its defaults
+//   - every field, and then the literals of its annotations and its value type
+//   - every value type, and then its type arguments
+//   - every list or map literal, and then its elements, keys before values
+//
+// The fields of a oneof group are visited as the fields of the type, after the annotations of the groups. Lists
+// of statements must not be modified while they are walked, but the statements in them can be.
+func Walk(visitor Visitor, ast *Ast) {
+	walkAnnotations(visitor, ast.Annotations)
+	for i := range ast.UseStatements {
+		walkLiteral(visitor, &ast.UseStatements[i].Path)
+	}
+
+	for i := range ast.TypeStatements {
+		walkType(visitor, &ast.TypeStatements[i])
+	}
+}
+
+func walkType(visitor Visitor, stmt *TypeStmt) {
+	if !visitor.VisitType(stmt) {
+		return
+	}
+
+	if stmt.BaseType != nil {
+		walkDecl(visitor, stmt.BaseType)
+	}
+
+	walkAnnotations(visitor, stmt.Annotations)
+	for i := range stmt.Oneofs {
+		walkAnnotations(visitor, stmt.Oneofs[i].Annotations)
+	}
+
+	for i := range stmt.Fields {
+		walkField(visitor, &stmt.Fields[i])
+	}
+
+	for i := range stmt.Defaults {
+		walkLiteral(visitor, &stmt.Defaults[i].Right)
+	}
+}
+
+func walkField(visitor Visitor, stmt *FieldStmt) {
+	if !visitor.VisitField(stmt) {
+		return
+	}
+
+	walkAnnotations(visitor, stmt.Annotations)
+	if stmt.ValueType != nil {
+		walkDecl(visitor, stmt.ValueType)
+	}
+}
+
+func walkDecl(visitor Visitor, stmt *DeclStmt) {
+	if !visitor.VisitDecl(stmt) {
+		return
+	}
+
+	for i := range stmt.Args {
+		walkDecl(visitor, &stmt.Args[i])
+	}
+}
+
+func walkAnnotations(visitor Visitor, annotations []AnnotationStmt) {
+	for i := range annotations {
+		walkLiteral(visitor, &annotations[i].Assigment.Right)
+	}
+}
+
+func walkLiteral(visitor Visitor, stmt *LiteralStmt) {
+	if !visitor.VisitLiteral(stmt) {
+		return
+	}
+
+	switch kind := stmt.Kind.(type) {
+	case ListLiteral:
+		for i := range kind {
+			walkLiteral(visitor, &kind[i])
+		}
+
+	case MapLiteral:
+		for i := range kind {
+			walkLiteral(visitor, &kind[i].Key)
+			walkLiteral(visitor, &kind[i].Value)
+		}
+	}
+}
+
+func (self *Rewriter) VisitType(stmt *TypeStmt) bool {
+	return self.Type == nil || self.Type(stmt)
+}
+
+func (self *Rewriter) VisitField(stmt *FieldStmt) bool {
+	return self.Field == nil || self.Field(stmt)
+}
+
+func (self *Rewriter) VisitDecl(stmt *DeclStmt) bool {
+	return self.Decl == nil || self.Decl(stmt)
+}
+
+func (self *Rewriter) VisitLiteral(stmt *LiteralStmt) bool {
+	return self.Literal == nil || self.Literal(stmt)
+}
@@ -0,0 +1,127 @@
+package parser
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalk(t *testing.T) {
+	ast := parse(t, `#tags = ["a", {"b": 1}]
+
+use "common"
+
+type A struct {
+	#id = 1
+	0 a map(string, common.B?)
+	oneof value {
+		#nolint = true
+		b string
+	}
+	defaults {
+		a = {}
+	}
+}
+
+type C extends common.Base {
+	c bool
+}
+`)
+
+	visited := make([]string, 0)
+	Walk(&Rewriter{
+		Type: func(stmt *TypeStmt) bool {
+			visited = append(visited, "type "+stmt.Name.Token.Literal)
+			return true
+		},
+		Field: func(stmt *FieldStmt) bool {
+			visited = append(visited, "field "+stmt.Name.Token.Literal)
+			return true
+		},
+		Decl: func(stmt *DeclStmt) bool {
+			visited = append(visited, "decl "+stmt.Token.Literal)
+			return true
+		},
+		Literal: func(stmt *LiteralStmt) bool {
+			visited = append(visited, "literal "+printLiteral(*stmt))
+			return true
+		},
+	}, ast)
+
+	require.Equal(t, []string{
+		`literal ["a", {"b": 1}]`,
+		`literal "a"`,
+		`literal {"b": 1}`,
+		`literal "b"`,
+		`literal 1`,
+		`literal "common"`,
+		`type A`,
+		`field a`,
+		`literal 1`,
+		`decl map`,
+		`decl string`,
+		`decl B`,
+		`field b`,
+		`literal true`,
+		`decl string`,
+		`literal {}`,
+		`type C`,
+		`decl Base`,
+		`field c`,
+		`decl bool`,
+	}, visited)
+}
+
+func TestWalk_Skip(t *testing.T) {
+	ast := parse(t, "type A struct {\n\ta list(string)\n}\n\ntype B struct {\n\tb list(string)\n}\n")
+
+	visited := make([]string, 0)
+	Walk(&Rewriter{
+		Type: func(stmt *TypeStmt) bool {
+			return stmt.Name.Token.Literal != "A"
+		},
+		Decl: func(stmt *DeclStmt) bool {
+			visited = append(visited, stmt.Token.Literal)
+			return false
+		},
+	}, ast)
+
+	require.Equal(t, []string{"list"}, visited)
+}
+
+func TestWalk_Rewrite(t *testing.T) {
+	ast := parse(t, `use "geo"
+
+type Place struct {
+	0 location geo.Point
+	1 history list(geo.Point)
+	2 tags list(string)
+}
+`)
+
+	// renames geo.Point to geo.Coordinates and moves every field to the next index
+	Walk(&Rewriter{
+		Field: func(stmt *FieldStmt) bool {
+			index, err := strconv.Atoi(stmt.Index.Token.Literal)
+			require.NoError(t, err)
+			stmt.Index.Token.Literal = strconv.Itoa(index + 1)
+			return true
+		},
+		Decl: func(stmt *DeclStmt) bool {
+			if stmt.Alias != nil && stmt.Alias.Token.Literal == "geo" && stmt.Token.Literal == "Point" {
+				stmt.Token.Literal = "Coordinates"
+			}
+			return true
+		},
+	}, ast)
+
+	require.Equal(t, `use "geo"
+
+type Place struct {
+	1 location geo.Coordinates
+	2 history list(geo.Coordinates)
+	3 tags list(string)
+}
+`, Print(ast))
+}
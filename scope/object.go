@@ -32,6 +32,21 @@ func (self *Object) Source() *parser.TypeStmt {
 	return self.src
 }
 
+// HasField returns true if the object declares a field called name
+func (self *Object) HasField(name string) bool {
+	if self.src == nil {
+		return false
+	}
+
+	for _, field := range self.src.Fields {
+		if field.Name.Token.Literal == name {
+			return true
+		}
+	}
+
+	return false
+}
+
 // Import represents an `use` statement.
 type Import struct {
 	src   *parser.UseStmt
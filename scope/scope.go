@@ -1,6 +1,11 @@
 package scope
 
-import "tomasweigenast.com/nexema/tool/parser"
+import (
+	"sort"
+	"strings"
+
+	"tomasweigenast.com/nexema/tool/parser"
+)
 
 // Scope represents a collection of local scopes, a.k.a .nex files.
 // So, Scope is a Nexema package.
@@ -15,10 +20,14 @@ type Scope struct {
 // LocalScope represents a Nexema file, which contains a list of objects
 // an may import other Scopes
 type LocalScope struct {
+	Documentation []parser.CommentStmt    // the documentation declared in the header of the file
+	Annotations   []parser.AnnotationStmt // the annotations declared in the header of the file
+
 	file           *parser.File
 	imports        map[string]*Import
 	objects        map[string]*Object
 	resolvedScopes map[*Scope]*Import
+	references     map[*Object]bool // the objects referenced by the types of the file, recorded during analysis
 }
 
 func NewLocalScope(file *parser.File, imports map[string]*Import, objects map[string]*Object) *LocalScope {
@@ -84,6 +93,107 @@ func (self *LocalScope) FindObject(name, alias string) (obj *Object, needAlias b
 	}
 }
 
+// AddReference records that a type or documentation comment of the file references obj
+func (self *LocalScope) AddReference(obj *Object) {
+	if self.references == nil {
+		self.references = make(map[*Object]bool)
+	}
+
+	self.references[obj] = true
+}
+
+// IsReferenced returns true if a type or documentation comment of the file references obj
+func (self *LocalScope) IsReferenced(obj *Object) bool {
+	return self.references[obj]
+}
+
+// UnusedImports returns the imports whose package does not declare any object referenced by the file, sorted by
+// path. It must be called after the references of the file are recorded
+func (self *LocalScope) UnusedImports() []*Import {
+	out := make([]*Import, 0)
+	for resolvedScope, imp := range self.resolvedScopes {
+		if !resolvedScope.declaresAny(self.references) {
+			out = append(out, imp)
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Path < out[j].Path
+	})
+
+	return out
+}
+
+// VisibleNames returns the names the objects visible from the file are referenced by: the names of its own
+// objects and of the objects imported without an alias, and alias.Name for the ones imported with an alias.
+// They are sorted and may contain duplicates.
+func (self *LocalScope) VisibleNames() []string {
+	names := make([]string, 0, len(self.objects))
+	for name := range self.objects {
+		names = append(names, name)
+	}
+
+	for resolvedScope, imp := range self.resolvedScopes {
+		for _, obj := range resolvedScope.GetAllObjects() {
+			if imp.HasAlias() {
+				names = append(names, imp.Alias+"."+obj.Name)
+			} else {
+				names = append(names, obj.Name)
+			}
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// ResolveReference resolves a reference written in documentation, in the form of TypeName, alias.TypeName,
+// TypeName.field or alias.TypeName.field, where alias can be an import alias or the name of an imported package.
+// It returns the referenced object and, if any, the name of the referenced field.
+func (self *LocalScope) ResolveReference(ref string) (obj *Object, field string, ok bool) {
+	parts := strings.Split(ref, ".")
+	switch len(parts) {
+	case 1:
+		obj, _ = self.FindObject(parts[0], "")
+
+	case 2:
+		// alias.TypeName takes precedence over TypeName.field
+		obj = self.findQualifiedObject(parts[0], parts[1])
+		if obj == nil {
+			obj, _ = self.FindObject(parts[0], "")
+			field = parts[1]
+		}
+
+	case 3:
+		obj = self.findQualifiedObject(parts[0], parts[1])
+		field = parts[2]
+	}
+
+	if obj == nil {
+		return nil, "", false
+	}
+
+	if len(field) > 0 && !obj.HasField(field) {
+		return nil, "", false
+	}
+
+	return obj, field, true
+}
+
+// findQualifiedObject looks up an object called name in the imported package whose alias, or name
+// if it was not aliased, is qualifier
+func (self *LocalScope) findQualifiedObject(qualifier, name string) *Object {
+	for resolvedScope, imp := range self.resolvedScopes {
+		if imp.Alias == qualifier || (!imp.HasAlias() && resolvedScope.name == qualifier) {
+			if matches := resolvedScope.FindObjects(name); len(matches) > 0 {
+				return matches[0]
+			}
+		}
+	}
+
+	return nil
+}
+
 func NewScope(path, packageName string) *Scope {
 	return &Scope{
 		path:        path,
@@ -104,6 +214,10 @@ func (self *Scope) Path() string {
 	return self.path
 }
 
+func (self *Scope) Name() string {
+	return self.name
+}
+
 func (self *Scope) GetAllObjects() []*Object {
 	arr := make([]*Object, 0)
 	for _, local := range self.localScopes {
@@ -115,6 +229,19 @@ func (self *Scope) GetAllObjects() []*Object {
 	return arr
 }
 
+// declaresAny returns true if any of objects is declared in the scope
+func (self *Scope) declaresAny(objects map[*Object]bool) bool {
+	for _, ls := range self.localScopes {
+		for _, obj := range ls.objects {
+			if objects[obj] {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 func (self *Scope) FindObjects(name string) []*Object {
 	arr := make([]*Object, 0)
 
@@ -202,3 +202,30 @@ func TestScope_FindObject(t *testing.T) {
 		})
 	}
 }
+
+func TestLocalScope_VisibleNames(t *testing.T) {
+	imported := &Scope{
+		localScopes: []*LocalScope{
+			{objects: map[string]*Object{"Address": {Name: "Address"}}},
+		},
+	}
+
+	aliased := &Scope{
+		localScopes: []*LocalScope{
+			{objects: map[string]*Object{"Address": {Name: "Address"}, "City": {Name: "City"}}},
+		},
+	}
+
+	localScope := &LocalScope{
+		objects: map[string]*Object{
+			"User":    {Name: "User"},
+			"Account": {Name: "Account"},
+		},
+		resolvedScopes: map[*Scope]*Import{
+			imported: {},
+			aliased:  {Alias: "geo"},
+		},
+	}
+
+	require.Equal(t, []string{"Account", "Address", "User", "geo.Address", "geo.City"}, localScope.VisibleNames())
+}
@@ -2,6 +2,8 @@ package token
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	jsoniter "github.com/json-iterator/go"
 )
@@ -41,6 +43,11 @@ const (
 	QuestionMark
 	Hash
 	Defaults
+	DocComment
+	DocCommentMultiline
+	Flags
+	Pipe
+	Oneof
 )
 
 type Token struct {
@@ -53,38 +60,43 @@ var (
 )
 
 var tokenKindMap map[TokenKind]string = map[TokenKind]string{
-	Comment:          "comment",
-	CommentMultiline: "multiline-comment",
-	Whitespace:       "whitespace",
-	EOF:              "eof",
-	Illegal:          "illegal",
-	String:           "string",
-	Integer:          "integer",
-	Decimal:          "decimal",
-	Ident:            "ident",
-	List:             "list_literal",
-	Map:              "map_literal",
-	Hash:             "#",
-	Rbrace:           "}",
-	Lbrace:           "{",
-	Rparen:           ")",
-	Lparen:           "(",
-	Rbrack:           "]",
-	Lbrack:           "[",
-	Assign:           "=",
-	Colon:            ":",
-	Use:              "use",
-	As:               "as",
-	Comma:            ",",
-	Period:           ".",
-	QuestionMark:     "?",
-	Extends:          "extends",
-	Defaults:         "defaults",
-	Base:             "base",
-	Struct:           "struct",
-	Union:            "union",
-	Enum:             "enum",
-	Type:             "type",
+	Comment:             "comment",
+	CommentMultiline:    "multiline-comment",
+	DocComment:          "doc-comment",
+	DocCommentMultiline: "multiline-doc-comment",
+	Whitespace:          "whitespace",
+	EOF:                 "eof",
+	Illegal:             "illegal",
+	String:              "string",
+	Integer:             "integer",
+	Decimal:             "decimal",
+	Ident:               "ident",
+	List:                "list_literal",
+	Map:                 "map_literal",
+	Hash:                "#",
+	Rbrace:              "}",
+	Lbrace:              "{",
+	Rparen:              ")",
+	Lparen:              "(",
+	Rbrack:              "]",
+	Lbrack:              "[",
+	Assign:              "=",
+	Colon:               ":",
+	Use:                 "use",
+	As:                  "as",
+	Comma:               ",",
+	Period:              ".",
+	QuestionMark:        "?",
+	Extends:             "extends",
+	Defaults:            "defaults",
+	Base:                "base",
+	Struct:              "struct",
+	Union:               "union",
+	Enum:                "enum",
+	Flags:               "flags",
+	Pipe:                "|",
+	Oneof:               "oneof",
+	Type:                "type",
 }
 
 func NewToken(kind TokenKind, literal ...string) *Token {
@@ -102,6 +114,10 @@ func NewToken(kind TokenKind, literal ...string) *Token {
 	return &Token{kind, literalValue}
 }
 
+// ToKeyword returns the keyword written as the identifier self, or nil if it is not a keyword.
+//
+// flags and oneof are not returned, because they are only keywords where a type modifier or a oneof group is
+// declared, so they can still be used as names. The parser checks them with IsContextualKeyword
 func (self *Token) ToKeyword() *Token {
 	var kind TokenKind
 	switch self.Literal {
@@ -130,6 +146,52 @@ func (self *Token) ToKeyword() *Token {
 	return &Token{kind, self.Literal}
 }
 
+// IsContextualKeyword returns true if self is an identifier spelled as the keyword kind, like flags or oneof
+func (self *Token) IsContextualKeyword(kind TokenKind) bool {
+	return self.Kind == Ident && self.Literal == tokenKindMap[kind]
+}
+
+// ParseInteger parses the literal of an Integer token. It can be a decimal number, or a hexadecimal (0x), octal (0o)
+// or binary (0b) one, and its digits can be separated by underscores. bitSize is used as in strconv.ParseInt
+func ParseInteger(literal string, bitSize int) (int64, error) {
+	digits, base := integerDigits(literal)
+	return strconv.ParseInt(digits, base, bitSize)
+}
+
+// ParseUnsignedInteger is like ParseInteger but for unsigned numbers
+func ParseUnsignedInteger(literal string, bitSize int) (uint64, error) {
+	digits, base := integerDigits(literal)
+	return strconv.ParseUint(digits, base, bitSize)
+}
+
+// integerDigits removes the base prefix and the digit separators of an integer literal, returning its sign
+// and digits, and the base they are written in
+func integerDigits(literal string) (string, int) {
+	sign := ""
+	if strings.HasPrefix(literal, "-") {
+		sign = "-"
+		literal = literal[1:]
+	}
+
+	base := 10
+	if len(literal) > 1 && literal[0] == '0' {
+		switch literal[1] {
+		case 'x', 'X':
+			base = 16
+		case 'o', 'O':
+			base = 8
+		case 'b', 'B':
+			base = 2
+		}
+
+		if base != 10 {
+			literal = literal[2:]
+		}
+	}
+
+	return sign + strings.ReplaceAll(literal, "_", ""), base
+}
+
 func (self *Token) IsEOF() bool {
 	return self.Kind == EOF
 }
@@ -15,11 +15,13 @@ func TestToken_ToKeyword(t *testing.T) {
 		{NewToken(Ident, "type"), NewToken(Type, "type")},
 		{NewToken(Ident, "struct"), NewToken(Struct, "struct")},
 		{NewToken(Ident, "enum"), NewToken(Enum, "enum")},
+		{NewToken(Ident, "flags"), nil},
 		{NewToken(Ident, "union"), NewToken(Union, "union")},
 		{NewToken(Ident, "base"), NewToken(Base, "base")},
 		{NewToken(Ident, "extends"), NewToken(Extends, "extends")},
 		{NewToken(Ident, "defaults"), NewToken(Defaults, "defaults")},
 		{NewToken(Ident, "use"), NewToken(Use, "use")},
+		{NewToken(Ident, "oneof"), nil},
 		{NewToken(Ident, "let"), nil},
 	}
 	for _, tt := range tests {
@@ -29,3 +31,67 @@ func TestToken_ToKeyword(t *testing.T) {
 		})
 	}
 }
+
+func TestToken_IsContextualKeyword(t *testing.T) {
+	require.True(t, NewToken(Ident, "flags").IsContextualKeyword(Flags))
+	require.True(t, NewToken(Ident, "oneof").IsContextualKeyword(Oneof))
+	require.False(t, NewToken(Ident, "oneof").IsContextualKeyword(Flags))
+	require.False(t, NewToken(Ident, "enum").IsContextualKeyword(Flags))
+	require.False(t, NewToken(String, "flags").IsContextualKeyword(Flags))
+}
+
+func TestParseInteger(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{"123", 123, false},
+		{"-123", -123, false},
+		{"1_000_000", 1000000, false},
+		{"0755", 755, false},
+		{"0xFF", 255, false},
+		{"0Xff_ff", 65535, false},
+		{"-0x80", -128, false},
+		{"0o17", 15, false},
+		{"0b1010", 10, false},
+		{"0x7FFF_FFFF_FFFF_FFFF", 9223372036854775807, false},
+		{"0x8000_0000_0000_0000", 0, true},
+		{"12.5", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseInteger(tt.input, 64)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseUnsignedInteger(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    uint64
+		wantErr bool
+	}{
+		{"128", 128, false},
+		{"0x8000_0000_0000_0000", 9223372036854775808, false},
+		{"0b1000_0000", 128, false},
+		{"-1", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseUnsignedInteger(tt.input, 64)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
@@ -1,10 +1,15 @@
 package tokenizer
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 type TokenizerErr struct {
 	err       TokenizerErrKind
 	arguments []string
+	pos       Pos
 }
 
 type TokenizerErrKind error
@@ -13,13 +18,21 @@ func (self TokenizerErr) Error() string {
 	if self.err == nil {
 		return "unknown"
 	}
-	return self.err.Error()
+
+	if len(self.arguments) == 0 {
+		return self.err.Error()
+	}
+
+	return fmt.Sprintf("%s: %s", self.err.Error(), strings.Join(self.arguments, " "))
 }
 
 var (
 	ErrUnknownToken            TokenizerErrKind = errors.New("unknown token")
 	ErrInvalidString           TokenizerErrKind = errors.New("string literals must start and end with a \"")
+	ErrInvalidRawString        TokenizerErrKind = errors.New("raw string literals must start and end with a `")
 	ErrInvalidMultilineComment TokenizerErrKind = errors.New("multiline comments must end with */")
+	ErrInvalidNumber           TokenizerErrKind = errors.New("invalid number literal")
+	ErrInvalidEscape           TokenizerErrKind = errors.New("invalid escape sequence in string literal")
 )
 
 func NewTokenizerErr(err TokenizerErrKind, args ...string) *TokenizerErr {
@@ -32,3 +45,14 @@ func NewTokenizerErr(err TokenizerErrKind, args ...string) *TokenizerErr {
 func (self TokenizerErr) IsErr(kind TokenizerErrKind) bool {
 	return errors.Is(self.err, kind)
 }
+
+// Pos returns where the error is in the input, like the escape sequence or the digit that is not valid
+func (self TokenizerErr) Pos() Pos {
+	return self.pos
+}
+
+// at sets the position of the error to pos
+func (self *TokenizerErr) at(pos *Pos) *TokenizerErr {
+	self.pos = *pos
+	return self
+}
@@ -4,8 +4,10 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"tomasweigenast.com/nexema/tool/token"
 )
@@ -19,10 +21,14 @@ const (
 )
 
 type Tokenizer struct {
-	reader      *bufio.Reader
-	ch          rune
-	currentPos  int
-	currentLine int
+	reader *bufio.Reader
+	ch     rune
+	width  int // the number of bytes of ch
+
+	offset     int // the byte offset of ch
+	line       int // the line of ch, starting from 1
+	lineOffset int // the byte offset where the line of ch starts
+	column16   int // the column of ch in UTF-16 code units, starting from 0
 
 	// only for debugging
 	currentChar  string
@@ -30,39 +36,54 @@ type Tokenizer struct {
 	previousChar string
 }
 
-// Pos represents the position of a token in the input
+// Pos represents the span of a token or a statement in the input. Lines and columns start from 1, and columns
+// are given in UTF-8 bytes and in UTF-16 code units, as editors using the Language Server Protocol expect them.
+// End values point right after the last character of the span.
 type Pos struct {
-	Start   int
-	End     int
-	Line    int
-	Endline int
+	Start       int // the byte offset where the span starts, from the beginning of the input
+	End         int // the byte offset where the span ends, from the beginning of the input
+	Line        int // the line where the span starts
+	Endline     int // the line where the span ends
+	Column      int // the column where the span starts, in bytes
+	EndColumn   int // the column where the span ends, in bytes
+	Column16    int // the column where the span starts, in UTF-16 code units
+	EndColumn16 int // the column where the span ends, in UTF-16 code units
 }
 
+// NewPos returns the Pos between the start and end byte offsets of the first line of an ASCII input, whose
+// columns are the offsets plus one. Without values, it returns the zero Pos.
 func NewPos(values ...int) *Pos {
-	if len(values) > 4 {
-		panic("must provide less than 4 values")
-	} else if len(values) < 2 {
-		values = append(values, 0)
-		values = append(values, 0)
-	}
+	switch len(values) {
+	case 0:
+		return &Pos{}
 
-	var line, endline int
-	if len(values) == 3 {
-		line = values[2]
-	} else if len(values) == 4 {
-		line = values[2]
-		endline = values[3]
+	case 2:
+		start, end := values[0], values[1]
+		return &Pos{start, end, 1, 1, start + 1, end + 1, start + 1, end + 1}
 	}
 
-	return &Pos{values[0], values[1], line, endline}
+	panic("must provide zero or two values")
+}
+
+// Span returns the Pos that starts where from starts and ends where to ends
+func Span(from, to Pos) Pos {
+	return Pos{from.Start, to.End, from.Line, to.Endline, from.Column, to.EndColumn, from.Column16, to.EndColumn16}
+}
+
+// location is a point in the input
+type location struct {
+	offset, line, column, column16 int
+}
+
+func newPos(start, end location) *Pos {
+	return &Pos{start.offset, end.offset, start.line, end.line, start.column, end.column, start.column16, end.column16}
 }
 
 func NewTokenizer(reader io.Reader) *Tokenizer {
 	tokenizer := &Tokenizer{
-		reader:      bufio.NewReader(reader),
-		ch:          eof,
-		currentPos:  -1,
-		currentLine: 0,
+		reader: bufio.NewReader(reader),
+		ch:     eof,
+		line:   1,
 	}
 
 	tokenizer.next()
@@ -76,7 +97,7 @@ func (self *Tokenizer) Next() (tok *token.Token, pos *Pos, err *TokenizerErr) {
 		return token.Token_EOF, nil, nil
 	}
 
-	pos = &Pos{self.currentPos, self.currentPos + 1, self.currentLine, self.currentLine}
+	pos = newPos(self.location(), self.locationAfter())
 	var tokenKind token.TokenKind
 
 	switch self.ch {
@@ -102,6 +123,8 @@ func (self *Tokenizer) Next() (tok *token.Token, pos *Pos, err *TokenizerErr) {
 		tokenKind = token.QuestionMark
 	case '#':
 		tokenKind = token.Hash
+	case '|':
+		tokenKind = token.Pipe
 	default:
 		next := self.peek()
 		if self.ch == '.' {
@@ -123,13 +146,19 @@ func (self *Tokenizer) Next() (tok *token.Token, pos *Pos, err *TokenizerErr) {
 			return
 		}
 
+		if self.ch == '`' {
+			tok, pos, err = self.readRawString()
+			self.next()
+			return
+		}
+
 		if self.ch == '/' && (next == '*' || next == '/') {
 			tok, pos, err = self.readComment()
 			self.next()
 			return
 		}
 
-		if isAlphabetic(self.ch) {
+		if isIdentifierStart(self.ch) {
 			tok, pos, err = self.readIdentifier()
 			if err == nil {
 				// try to convert to a keyword
@@ -141,7 +170,10 @@ func (self *Tokenizer) Next() (tok *token.Token, pos *Pos, err *TokenizerErr) {
 			return
 		}
 
-		return nil, nil, NewTokenizerErr(ErrUnknownToken, string(self.ch))
+		// the character is skipped, so the next call reads the tokens after it
+		err = NewTokenizerErr(ErrUnknownToken, string(self.ch)).at(pos)
+		self.next()
+		return nil, nil, err
 	}
 
 	tok = token.NewToken(tokenKind, string(self.ch))
@@ -151,16 +183,18 @@ func (self *Tokenizer) Next() (tok *token.Token, pos *Pos, err *TokenizerErr) {
 }
 
 func (self *Tokenizer) GetCurrentPosition() *Pos {
-	return NewPos(self.currentPos, self.currentPos, self.currentLine, self.currentLine)
+	return newPos(self.location(), self.location())
 }
 
+// readIdentifier reads an identifier, which starts with a letter or an underscore, followed by letters, digits,
+// underscores, combining marks or connector punctuation
 func (self *Tokenizer) readIdentifier() (tok *token.Token, pos *Pos, err *TokenizerErr) {
 	result := new(strings.Builder)
 	result.WriteRune(self.ch)
-	startPos := self.currentPos
+	start := self.location()
 	for {
 		ch := self.next()
-		if isAlphanumeric(ch) || ch == '_' {
+		if isIdentifierPart(ch) {
 			result.WriteRune(ch)
 			continue
 		}
@@ -168,43 +202,57 @@ func (self *Tokenizer) readIdentifier() (tok *token.Token, pos *Pos, err *Tokeni
 		break
 	}
 
-	return token.NewToken(token.Ident, result.String()), self.getPos(startPos), nil
+	return token.NewToken(token.Ident, result.String()), newPos(start, self.location()), nil
 }
 
+// readComment reads a line (//) or a block (/* */) comment. Comments that start with /// or /**
+// are read as documentation comments.
 func (self *Tokenizer) readComment() (tok *token.Token, pos *Pos, err *TokenizerErr) {
 	result := new(strings.Builder)
-	startPos := self.currentPos
+	start := self.location()
 	self.next() // initial / was read
 	if self.ch == '/' {
+		kind := token.Comment
+		if self.peek() == '/' {
+			self.next() // third / was read
+			if self.peek() == '/' {
+				result.WriteRune('/')
+			} else {
+				kind = token.DocComment
+			}
+		}
+
 		for {
 			ch := self.next()
 			if ch == newline || ch == eof {
-				pos := self.getPos(startPos)
-				if ch == newline {
-					self.advanceLine()
-				}
-
-				return token.NewToken(token.Comment, result.String()), pos, nil
+				return token.NewToken(kind, result.String()), newPos(start, self.location()), nil
 			}
 
 			result.WriteRune(ch)
 		}
 	} else {
 		// * was read, scan until */ is found
-		startLine := self.currentLine
+		kind := token.CommentMultiline
+		if self.peek() == '*' {
+			self.next() // second * was read
+			if self.peek() == '/' {
+				// empty comment, /**/
+				self.next()
+				return token.NewToken(kind, ""), newPos(start, self.locationAfter()), nil
+			}
+
+			kind = token.DocCommentMultiline
+		}
+
 		for {
 			ch := self.next()
 			if ch == eof {
-				return nil, nil, NewTokenizerErr(ErrInvalidMultilineComment)
+				return nil, nil, NewTokenizerErr(ErrInvalidMultilineComment).at(newPos(start, self.location()))
 			}
 
 			if ch == '*' && self.peek() == '/' {
 				self.next() // consume last /
-				return token.NewToken(token.CommentMultiline, result.String()), &Pos{startPos, self.currentPos + 1, startLine, self.currentLine}, nil
-			}
-
-			if ch == newline {
-				self.advanceLine()
+				return token.NewToken(kind, result.String()), newPos(start, self.locationAfter()), nil
 			}
 
 			result.WriteRune(ch)
@@ -212,30 +260,61 @@ func (self *Tokenizer) readComment() (tok *token.Token, pos *Pos, err *Tokenizer
 	}
 }
 
+// readNumber reads an integer or a decimal number. Integers can be written in decimal, or in hexadecimal (0x),
+// octal (0o) or binary (0b), and decimals can declare an exponent (1e-9). Digits can be separated by underscores.
 func (self *Tokenizer) readNumber() (tok *token.Token, pos *Pos, err *TokenizerErr) {
-	isDecimal := self.ch == '.'
 	result := new(strings.Builder)
-	result.WriteRune(self.ch)
+	start := self.location()
 
-	startPos := self.currentPos
+	if self.ch == '-' {
+		result.WriteRune('-')
+		self.next()
+	}
 
-	for {
-		ch := self.next()
-		if ch == '.' {
-			if isDecimal {
-				break
-			}
+	// hexadecimal, octal or binary integer
+	if isDigit := baseDigits(self.peek()); self.ch == '0' && isDigit != nil {
+		result.WriteRune('0')
+		result.WriteRune(self.next())
+		self.next()
 
-			result.WriteRune('.')
-			isDecimal = true
-		} else if isNumeric(ch) {
-			result.WriteRune(ch)
-		} else {
-			break
+		if count, ok := self.readDigits(result, isDigit, true); count == 0 || !ok {
+			return self.invalidNumber(result, start, token.Integer)
+		}
+
+		return token.NewToken(token.Integer, result.String()), newPos(start, self.location()), nil
+	}
+
+	if _, ok := self.readDigits(result, isNumeric, false); !ok {
+		return self.invalidNumber(result, start, token.Integer)
+	}
+
+	isDecimal := false
+	if self.ch == '.' {
+		isDecimal = true
+		result.WriteRune('.')
+		self.next()
+
+		if _, ok := self.readDigits(result, isNumeric, false); !ok {
+			return self.invalidNumber(result, start, token.Decimal)
 		}
 	}
 
-	pos = self.getPos(startPos)
+	if self.ch == 'e' || self.ch == 'E' {
+		isDecimal = true
+		result.WriteRune(self.ch)
+		self.next()
+
+		if self.ch == '+' || self.ch == '-' {
+			result.WriteRune(self.ch)
+			self.next()
+		}
+
+		if count, ok := self.readDigits(result, isNumeric, false); count == 0 || !ok {
+			return self.invalidNumber(result, start, token.Decimal)
+		}
+	}
+
+	pos = newPos(start, self.location())
 	if isDecimal {
 		return token.NewToken(token.Decimal, result.String()), pos, nil
 	} else {
@@ -243,85 +322,250 @@ func (self *Tokenizer) readNumber() (tok *token.Token, pos *Pos, err *TokenizerE
 	}
 }
 
+// invalidNumber returns the error of the number read in result, starting at start, at the character that makes it
+// invalid: the current one, or the last one read if the number ends there, like a trailing separator. The rest of
+// the number is read too, so it is not read as other tokens, and it is returned as a token of the given kind.
+func (self *Tokenizer) invalidNumber(result *strings.Builder, start location, kind token.TokenKind) (*token.Token, *Pos, *TokenizerErr) {
+	at, end := self.location(), self.locationAfter()
+	if !isIdentifierPart(self.ch) {
+		// the last character read is a digit, a separator, a sign or a base, so it takes a single column
+		end = at
+		at.offset--
+		at.column--
+		at.column16--
+	}
+
+	err := NewTokenizerErr(ErrInvalidNumber, result.String()).at(newPos(at, end))
+	for isIdentifierPart(self.ch) || self.ch == '.' {
+		result.WriteRune(self.ch)
+		self.next()
+	}
+
+	return token.NewToken(kind, result.String()), newPos(start, self.location()), err
+}
+
+// readDigits reads, starting from the current character, the digits accepted by isDigit and writes them to result.
+// Digits can be separated by single underscores, which can also follow a base prefix if afterPrefix is true.
+// It returns the number of digits read, and false if an underscore is not followed by a digit.
+func (self *Tokenizer) readDigits(result *strings.Builder, isDigit func(rune) bool, afterPrefix bool) (count int, ok bool) {
+	canSeparate := afterPrefix
+	lastSeparator := false
+	for {
+		if isDigit(self.ch) {
+			count++
+			canSeparate = true
+			lastSeparator = false
+		} else if self.ch == '_' && canSeparate {
+			canSeparate = false
+			lastSeparator = true
+		} else if self.ch == '_' {
+			return count, false
+		} else {
+			return count, !lastSeparator
+		}
+
+		result.WriteRune(self.ch)
+		self.next()
+	}
+}
+
+// readString reads a string between double quotes. The following escape sequences are supported: \\, \", \', \n,
+// \r, \t, \b, \f, \v, \0 and \u{X}, where X are from 1 to 6 hexadecimal digits of a Unicode code point.
+//
+// If an escape sequence is not valid, the rest of the string is still read, so it is not read as other tokens, and
+// the string is returned with the error of its first invalid escape sequence.
 func (self *Tokenizer) readString() (tok *token.Token, pos *Pos, err *TokenizerErr) {
 	result := new(strings.Builder)
-	startPos := self.currentPos
+	start := self.location()
 	for {
 		ch := self.next()
 		if ch == newline || ch == eof {
-			return nil, nil, NewTokenizerErr(ErrInvalidString)
+			if err != nil {
+				return token.NewToken(token.String, result.String()), newPos(start, self.location()), err
+			}
+
+			return nil, nil, NewTokenizerErr(ErrInvalidString).at(newPos(start, self.location()))
 		}
 
 		if ch == '"' {
 			break
 		}
 
-		// escape "
-		if ch == '\\' && self.peek() == '"' {
-			result.WriteRune('"')
-			self.next()
-		} else {
+		if ch != '\\' {
 			result.WriteRune(ch)
+			continue
+		}
+
+		escapeStart := self.location()
+		escaped, escapeErr := self.readEscape()
+		if escapeErr == nil {
+			result.WriteRune(escaped)
+			continue
+		}
+
+		if escapeErr.IsErr(ErrInvalidString) {
+			return nil, nil, escapeErr.at(newPos(start, self.location()))
+		}
+
+		// the escape sequence ends at the character that makes it invalid, which can also end the string
+		if err == nil {
+			end := self.location()
+			if self.ch != newline {
+				end = self.locationAfter()
+			}
+
+			err = escapeErr.at(newPos(escapeStart, end))
+		}
+
+		if self.ch == '"' {
+			break
+		} else if self.ch == newline || self.ch == eof {
+			return token.NewToken(token.String, result.String()), newPos(start, self.location()), err
 		}
 	}
 
-	pos = self.getPos(startPos)
-	pos.End++
-	return token.NewToken(token.String, result.String()), pos, nil
+	return token.NewToken(token.String, result.String()), newPos(start, self.locationAfter()), err
 }
 
-func (self *Tokenizer) skipWhitespace() {
+// readRawString reads a string between backticks. Escape sequences are not interpreted, and it can span
+// multiple lines, whose carriage returns are discarded.
+func (self *Tokenizer) readRawString() (tok *token.Token, pos *Pos, err *TokenizerErr) {
+	result := new(strings.Builder)
+	start := self.location()
 	for {
-		if self.ch == newline {
-			self.currentLine++
-			self.currentPos = -1
-			self.next()
-			continue
+		ch := self.next()
+		if ch == eof {
+			return nil, nil, NewTokenizerErr(ErrInvalidRawString).at(newPos(start, self.location()))
 		}
 
-		if self.ch == space || self.ch == tab || self.ch == carriage {
-			self.next()
-		} else {
+		if ch == '`' {
 			break
 		}
+
+		if ch == carriage {
+			continue
+		}
+
+		result.WriteRune(ch)
+	}
+
+	return token.NewToken(token.String, result.String()), newPos(start, self.locationAfter()), nil
+}
+
+// readEscape reads the escape sequence that follows a backslash, returning the character it represents
+func (self *Tokenizer) readEscape() (rune, *TokenizerErr) {
+	ch := self.next()
+	switch ch {
+	case '\\', '"', '\'':
+		return ch, nil
+	case 'n':
+		return '\n', nil
+	case 'r':
+		return '\r', nil
+	case 't':
+		return '\t', nil
+	case 'b':
+		return '\b', nil
+	case 'f':
+		return '\f', nil
+	case 'v':
+		return '\v', nil
+	case '0':
+		return 0, nil
+	case 'u':
+		if self.next() != '{' {
+			return 0, NewTokenizerErr(ErrInvalidEscape, "\\u")
+		}
+
+		digits := new(strings.Builder)
+		for self.next() != '}' {
+			if !isHexDigit(self.ch) || digits.Len() == 6 {
+				return 0, NewTokenizerErr(ErrInvalidEscape, "\\u{"+digits.String())
+			}
+
+			digits.WriteRune(self.ch)
+		}
+
+		codePoint, err := strconv.ParseUint(digits.String(), 16, 32)
+		if err != nil || !utf8.ValidRune(rune(codePoint)) {
+			return 0, NewTokenizerErr(ErrInvalidEscape, "\\u{"+digits.String()+"}")
+		}
+
+		return rune(codePoint), nil
+	}
+
+	if ch == eof {
+		return 0, NewTokenizerErr(ErrInvalidString)
 	}
+
+	return 0, NewTokenizerErr(ErrInvalidEscape, "\\"+string(ch))
 }
 
-func (self *Tokenizer) advanceLine() {
-	self.currentLine++
-	self.currentPos = -1
+func (self *Tokenizer) skipWhitespace() {
+	for self.ch == newline || self.ch == space || self.ch == tab || self.ch == carriage {
+		self.next()
+	}
 }
 
+// next moves to the next character of the input, updating the location of the current one
 func (self *Tokenizer) next() rune {
-	ch, _, err := self.reader.ReadRune()
-	self.currentPos++
+	if self.ch == newline {
+		self.line++
+		self.lineOffset = self.offset + self.width
+		self.column16 = 0
+	} else {
+		self.column16 += utf16Len(self.ch)
+	}
+	self.offset += self.width
+
+	ch, width, err := self.reader.ReadRune()
 	if err == nil {
 		self.previousChar = string(self.ch)
 		self.currentChar = string(ch)
 		self.nextChar = string(self.peek())
 		self.ch = ch
+		self.width = width
 	} else {
 		self.ch = eof
+		self.width = 0
 	}
 
 	return self.ch
 }
 
 func (self *Tokenizer) peek() rune {
-	buf, err := self.reader.Peek(1)
-	if err == nil {
-		return rune(buf[0])
+	buf, _ := self.reader.Peek(utf8.UTFMax)
+	if len(buf) == 0 {
+		return eof
 	}
 
-	return eof
+	ch, _ := utf8.DecodeRune(buf)
+	return ch
+}
+
+// location returns the location of the current character
+func (self *Tokenizer) location() location {
+	return location{self.offset, self.line, self.offset - self.lineOffset + 1, self.column16 + 1}
 }
 
-func (self *Tokenizer) getPos(start int) *Pos {
-	return &Pos{
-		start,
-		self.currentPos,
-		self.currentLine,
-		self.currentLine,
+// locationAfter returns the location right after the current character, which must not be a new line
+func (self *Tokenizer) locationAfter() location {
+	loc := self.location()
+	loc.offset += self.width
+	loc.column += self.width
+	loc.column16 += utf16Len(self.ch)
+	return loc
+}
+
+// utf16Len returns the number of UTF-16 code units needed to encode ch
+func utf16Len(ch rune) int {
+	switch {
+	case ch < 0:
+		return 0
+	case ch >= 0x10000:
+		return 2
+	default:
+		return 1
 	}
 }
 
@@ -334,6 +578,33 @@ func isNumeric(c rune) bool {
 	}
 }
 
+func isHexDigit(c rune) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+func isOctalDigit(c rune) bool {
+	return c >= '0' && c <= '7'
+}
+
+func isBinaryDigit(c rune) bool {
+	return c == '0' || c == '1'
+}
+
+// baseDigits returns the function that accepts the digits of the base denoted by prefix, which is the
+// character after the 0 of an integer literal, or nil if prefix is not x, o or b
+func baseDigits(prefix rune) func(rune) bool {
+	switch prefix {
+	case 'x', 'X':
+		return isHexDigit
+	case 'o', 'O':
+		return isOctalDigit
+	case 'b', 'B':
+		return isBinaryDigit
+	}
+
+	return nil
+}
+
 func isAlphabetic(ch rune) bool {
 	if ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' {
 		return true
@@ -344,10 +615,14 @@ func isAlphabetic(ch rune) bool {
 	return false
 }
 
-func isAlphanumeric(ch rune) bool {
-	return isNumeric(ch) || isAlphabetic(ch)
+func isIdentifierStart(ch rune) bool {
+	return isAlphabetic(ch) || ch == '_'
+}
+
+func isIdentifierPart(ch rune) bool {
+	return isIdentifierStart(ch) || isNumeric(ch) || ch > '\x7f' && unicode.In(ch, unicode.Mn, unicode.Mc, unicode.Pc)
 }
 
 func (self Pos) String() string {
-	return fmt.Sprintf("%d %d %d %d", self.Start, self.End, self.Line, self.Endline)
+	return fmt.Sprintf("%d:%d-%d:%d", self.Line, self.Column, self.Endline, self.EndColumn)
 }
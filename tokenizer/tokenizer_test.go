@@ -30,6 +30,23 @@ func TestTokenizerErr_IsErr(t *testing.T) {
 	}
 }
 
+func TestTokenizerErr_Error(t *testing.T) {
+	tests := []struct {
+		err  *TokenizerErr
+		want string
+	}{
+		{NewTokenizerErr(ErrInvalidString), `string literals must start and end with a "`},
+		{NewTokenizerErr(ErrUnknownToken, "<"), "unknown token: <"},
+		{NewTokenizerErr(ErrInvalidEscape, `\d`), `invalid escape sequence in string literal: \d`},
+		{&TokenizerErr{}, "unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.err.Error())
+		})
+	}
+}
+
 func TestTokenizer_Next(t *testing.T) {
 	tests := []struct {
 		input   string
@@ -37,7 +54,7 @@ func TestTokenizer_Next(t *testing.T) {
 		wantPos *Pos
 		wantErr *TokenizerErr
 	}{
-		{"<", nil, nil, NewTokenizerErr(ErrUnknownToken, "<")},
+		{"<", nil, nil, NewTokenizerErr(ErrUnknownToken, "<").at(NewPos(0, 1))},
 		{"?", token.NewToken(token.QuestionMark, "?"), NewPos(0, 1), nil},
 		// {" ", token.NewToken(token.EOF, ""), NewPos(0, 0), nil},
 		{"=", token.NewToken(token.Assign, "="), NewPos(0, 1), nil},
@@ -51,11 +68,48 @@ func TestTokenizer_Next(t *testing.T) {
 		{")", token.NewToken(token.Rparen, ")"), NewPos(0, 1), nil},
 		{",", token.NewToken(token.Comma, ","), NewPos(0, 1), nil},
 		{".", token.NewToken(token.Period, "."), NewPos(0, 1), nil},
+		{"|", token.NewToken(token.Pipe, "|"), NewPos(0, 1), nil},
 		{"// a comment", token.NewToken(token.Comment, ` a comment`), NewPos(0, 12), nil},
 		{"/*another comment*/", token.NewToken(token.CommentMultiline, `another comment`), NewPos(0, 19), nil},
 		{"12345", token.NewToken(token.Integer, "12345"), NewPos(0, 5), nil},
 		{"12.345", token.NewToken(token.Decimal, "12.345"), NewPos(0, 6), nil},
+		{"1_000_000", token.NewToken(token.Integer, "1_000_000"), NewPos(0, 9), nil},
+		{"-42", token.NewToken(token.Integer, "-42"), NewPos(0, 3), nil},
+		{"0xFF", token.NewToken(token.Integer, "0xFF"), NewPos(0, 4), nil},
+		{"0Xff_ff", token.NewToken(token.Integer, "0Xff_ff"), NewPos(0, 7), nil},
+		{"0x_1F", token.NewToken(token.Integer, "0x_1F"), NewPos(0, 5), nil},
+		{"-0x80", token.NewToken(token.Integer, "-0x80"), NewPos(0, 5), nil},
+		{"0o755", token.NewToken(token.Integer, "0o755"), NewPos(0, 5), nil},
+		{"0O1_7", token.NewToken(token.Integer, "0O1_7"), NewPos(0, 5), nil},
+		{"0b1010", token.NewToken(token.Integer, "0b1010"), NewPos(0, 6), nil},
+		{"0B1111_0000", token.NewToken(token.Integer, "0B1111_0000"), NewPos(0, 11), nil},
+		{"0755", token.NewToken(token.Integer, "0755"), NewPos(0, 4), nil},
+		{"1e-9", token.NewToken(token.Decimal, "1e-9"), NewPos(0, 4), nil},
+		{"1E+10", token.NewToken(token.Decimal, "1E+10"), NewPos(0, 5), nil},
+		{"2e8", token.NewToken(token.Decimal, "2e8"), NewPos(0, 3), nil},
+		{"-1.5e3", token.NewToken(token.Decimal, "-1.5e3"), NewPos(0, 6), nil},
+		{".5e-2", token.NewToken(token.Decimal, ".5e-2"), NewPos(0, 5), nil},
+		{"3_141.592_65", token.NewToken(token.Decimal, "3_141.592_65"), NewPos(0, 12), nil},
+		{"1_0e1_0", token.NewToken(token.Decimal, "1_0e1_0"), NewPos(0, 7), nil},
+		{"0xFG", token.NewToken(token.Integer, "0xF"), NewPos(0, 3), nil},
+		{"0b102", token.NewToken(token.Integer, "0b10"), NewPos(0, 4), nil},
+		{"0o78", token.NewToken(token.Integer, "0o7"), NewPos(0, 3), nil},
+		{"0x", token.NewToken(token.Integer, "0x"), NewPos(0, 2), NewTokenizerErr(ErrInvalidNumber, "0x").at(NewPos(1, 2))},
+		{"0xG", token.NewToken(token.Integer, "0xG"), NewPos(0, 3), NewTokenizerErr(ErrInvalidNumber, "0x").at(NewPos(2, 3))},
+		{"0b2", token.NewToken(token.Integer, "0b2"), NewPos(0, 3), NewTokenizerErr(ErrInvalidNumber, "0b").at(NewPos(2, 3))},
+		{"0o_", token.NewToken(token.Integer, "0o_"), NewPos(0, 3), NewTokenizerErr(ErrInvalidNumber, "0o_").at(NewPos(2, 3))},
+		{"1_", token.NewToken(token.Integer, "1_"), NewPos(0, 2), NewTokenizerErr(ErrInvalidNumber, "1_").at(NewPos(1, 2))},
+		{"1__0", token.NewToken(token.Integer, "1__0"), NewPos(0, 4), NewTokenizerErr(ErrInvalidNumber, "1_").at(NewPos(2, 3))},
+		{"1_.5", token.NewToken(token.Integer, "1_.5"), NewPos(0, 4), NewTokenizerErr(ErrInvalidNumber, "1_").at(NewPos(1, 2))},
+		{"1._5", token.NewToken(token.Decimal, "1._5"), NewPos(0, 4), NewTokenizerErr(ErrInvalidNumber, "1.").at(NewPos(2, 3))},
+		{"1e", token.NewToken(token.Decimal, "1e"), NewPos(0, 2), NewTokenizerErr(ErrInvalidNumber, "1e").at(NewPos(1, 2))},
+		{"1e+", token.NewToken(token.Decimal, "1e+"), NewPos(0, 3), NewTokenizerErr(ErrInvalidNumber, "1e+").at(NewPos(2, 3))},
+		{"1e_5", token.NewToken(token.Decimal, "1e_5"), NewPos(0, 4), NewTokenizerErr(ErrInvalidNumber, "1e").at(NewPos(2, 3))},
+		{"-0b", token.NewToken(token.Integer, "-0b"), NewPos(0, 3), NewTokenizerErr(ErrInvalidNumber, "-0b").at(NewPos(2, 3))},
+		{"1.5e-", token.NewToken(token.Decimal, "1.5e-"), NewPos(0, 5), NewTokenizerErr(ErrInvalidNumber, "1.5e-").at(NewPos(4, 5))},
+		{"0x1_", token.NewToken(token.Integer, "0x1_"), NewPos(0, 4), NewTokenizerErr(ErrInvalidNumber, "0x1_").at(NewPos(3, 4))},
 		{`"a string"`, token.NewToken(token.String, `a string`), NewPos(0, 10), nil},
+		{"`a raw string`", token.NewToken(token.String, `a raw string`), NewPos(0, 14), nil},
 		{`simple_identifier123`, token.NewToken(token.Ident, `simple_identifier123`), NewPos(0, 20), nil},
 	}
 	for _, tt := range tests {
@@ -69,6 +123,31 @@ func TestTokenizer_Next(t *testing.T) {
 	}
 }
 
+func TestTokenizer_Positions(t *testing.T) {
+	input := "名前 = \"😃\"\n\tnaïve 1 // ✓\n`é\n`}"
+	want := []struct {
+		tok *token.Token
+		pos *Pos
+	}{
+		{token.NewToken(token.Ident, "名前"), &Pos{0, 6, 1, 1, 1, 7, 1, 3}},
+		{token.NewToken(token.Assign, "="), &Pos{7, 8, 1, 1, 8, 9, 4, 5}},
+		{token.NewToken(token.String, "😃"), &Pos{9, 15, 1, 1, 10, 16, 6, 10}},
+		{token.NewToken(token.Ident, "naïve"), &Pos{17, 23, 2, 2, 2, 8, 2, 7}},
+		{token.NewToken(token.Integer, "1"), &Pos{24, 25, 2, 2, 9, 10, 8, 9}},
+		{token.NewToken(token.Comment, " ✓"), &Pos{26, 32, 2, 2, 11, 17, 10, 14}},
+		{token.NewToken(token.String, "é\n"), &Pos{33, 38, 3, 4, 1, 2, 1, 2}},
+		{token.NewToken(token.Rbrace, "}"), &Pos{38, 39, 4, 4, 2, 3, 2, 3}},
+	}
+
+	tokenizer := NewTokenizer(bufio.NewReader(bytes.NewBufferString(input)))
+	for _, tt := range want {
+		gotTok, gotPos, gotErr := tokenizer.Next()
+		require.Nil(t, gotErr)
+		require.Equal(t, tt.tok, gotTok)
+		require.Equal(t, tt.pos, gotPos)
+	}
+}
+
 func TestTokenizer_readNumber(t *testing.T) {
 	tests := []struct {
 		input   string
@@ -106,9 +185,20 @@ func TestTokenizer_readString(t *testing.T) {
 	}{
 		{`"input string"`, token.NewToken(token.String, "input string"), NewPos(0, 14), nil},
 		{`"handle escape \" char"`, token.NewToken(token.String, "handle escape \" char"), NewPos(0, 23), nil},
-		{`"str`, nil, nil, NewTokenizerErr(ErrInvalidString)},
+		{`"line\n\"quoted\""`, token.NewToken(token.String, "line\n\"quoted\""), NewPos(0, 18), nil},
+		{`"\\ \' \r \t \b \f \v \0"`, token.NewToken(token.String, "\\ ' \r \t \b \f \v \x00"), NewPos(0, 25), nil},
+		{`"\u{41}\u{1F600}\u{10FFFF}"`, token.NewToken(token.String, "A😀\U0010FFFF"), NewPos(0, 27), nil},
+		{`"\d"`, token.NewToken(token.String, ""), NewPos(0, 4), NewTokenizerErr(ErrInvalidEscape, `\d`).at(NewPos(1, 3))},
+		{`"\u41"`, token.NewToken(token.String, "1"), NewPos(0, 6), NewTokenizerErr(ErrInvalidEscape, `\u`).at(NewPos(1, 4))},
+		{`"\u{}"`, token.NewToken(token.String, ""), NewPos(0, 6), NewTokenizerErr(ErrInvalidEscape, `\u{}`).at(NewPos(1, 5))},
+		{`"\u{41"`, token.NewToken(token.String, ""), NewPos(0, 7), NewTokenizerErr(ErrInvalidEscape, `\u{41`).at(NewPos(1, 7))},
+		{`"\u{1234567}"`, token.NewToken(token.String, "}"), NewPos(0, 13), NewTokenizerErr(ErrInvalidEscape, `\u{123456`).at(NewPos(1, 11))},
+		{`"\u{D800}"`, token.NewToken(token.String, ""), NewPos(0, 10), NewTokenizerErr(ErrInvalidEscape, `\u{D800}`).at(NewPos(1, 9))},
+		{`"\u{110000}"`, token.NewToken(token.String, ""), NewPos(0, 12), NewTokenizerErr(ErrInvalidEscape, `\u{110000}`).at(NewPos(1, 11))},
+		{`"\`, nil, nil, NewTokenizerErr(ErrInvalidString).at(NewPos(0, 2))},
+		{`"str`, nil, nil, NewTokenizerErr(ErrInvalidString).at(NewPos(0, 4))},
 		{`"s"tr"`, token.NewToken(token.String, "s"), NewPos(0, 3), nil},
-		{`"other chars 漢語水平考試 😃 and numbers 1234"`, token.NewToken(token.String, "other chars 漢語水平考試 😃 and numbers 1234"), NewPos(0, 39), nil},
+		{`"other chars 漢語水平考試 😃 and numbers 1234"`, token.NewToken(token.String, "other chars 漢語水平考試 😃 and numbers 1234"), &Pos{0, 54, 1, 1, 1, 55, 1, 41}, nil},
 	}
 	for _, tt := range tests {
 		t.Run(tt.input, func(t *testing.T) {
@@ -121,6 +211,32 @@ func TestTokenizer_readString(t *testing.T) {
 	}
 }
 
+func TestTokenizer_readRawString(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    *token.Token
+		wantPos *Pos
+		wantErr *TokenizerErr
+	}{
+		{"`raw string`", token.NewToken(token.String, "raw string"), NewPos(0, 12), nil},
+		{"`^\\d+\\.\\d+$`", token.NewToken(token.String, "^\\d+\\.\\d+$"), NewPos(0, 12), nil},
+		{"`no \"escapes\" \\n`", token.NewToken(token.String, "no \"escapes\" \\n"), NewPos(0, 17), nil},
+		{"``", token.NewToken(token.String, ""), NewPos(0, 2), nil},
+		{"`SELECT *\n  FROM users\n  WHERE id = ?`", token.NewToken(token.String, "SELECT *\n  FROM users\n  WHERE id = ?"), &Pos{0, 38, 1, 3, 1, 16, 1, 16}, nil},
+		{"`windows\r\nline`", token.NewToken(token.String, "windows\nline"), &Pos{0, 15, 1, 2, 1, 6, 1, 6}, nil},
+		{"`unterminated\n", nil, nil, NewTokenizerErr(ErrInvalidRawString).at(&Pos{0, 14, 1, 2, 1, 1, 1, 1})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tokenizer := NewTokenizer(bufio.NewReader(bytes.NewBufferString(tt.input)))
+			gotTok, gotPos, gotErr := tokenizer.readRawString()
+			require.Equal(t, tt.wantErr, gotErr)
+			require.Equal(t, tt.want, gotTok)
+			require.Equal(t, tt.wantPos, gotPos)
+		})
+	}
+}
+
 func TestTokenizer_readComment(t *testing.T) {
 	tests := []struct {
 		input   string
@@ -129,12 +245,17 @@ func TestTokenizer_readComment(t *testing.T) {
 		wantErr *TokenizerErr
 	}{
 		{"//simple inline comment", token.NewToken(token.Comment, "simple inline comment"), NewPos(0, 23), nil},
-		{"// 漢語水平考試 😃 1234", token.NewToken(token.Comment, " 漢語水平考試 😃 1234"), NewPos(0, 16), nil},
+		{"// 漢語水平考試 😃 1234", token.NewToken(token.Comment, " 漢語水平考試 😃 1234"), &Pos{0, 31, 1, 1, 1, 32, 1, 18}, nil},
 		{"//with more //", token.NewToken(token.Comment, "with more //"), NewPos(0, 14), nil},
 		{`//contains: "string inside"`, token.NewToken(token.Comment, `contains: "string inside"`), NewPos(0, 27), nil},
 		{"/* multiline but inline */", token.NewToken(token.CommentMultiline, " multiline but inline "), NewPos(0, 26), nil},
-		{"/* line 1 \n line \"2\"*/", token.NewToken(token.CommentMultiline, " line 1 \n line \"2\""), NewPos(0, 11, 0, 1), nil},
-		{"/* error! ", nil, nil, NewTokenizerErr(ErrInvalidMultilineComment)},
+		{"/* line 1 \n line \"2\"*/", token.NewToken(token.CommentMultiline, " line 1 \n line \"2\""), &Pos{0, 22, 1, 2, 1, 12, 1, 12}, nil},
+		{"/* error! ", nil, nil, NewTokenizerErr(ErrInvalidMultilineComment).at(NewPos(0, 10))},
+		{"/// doc comment", token.NewToken(token.DocComment, " doc comment"), NewPos(0, 15), nil},
+		{"//// not a doc comment", token.NewToken(token.Comment, "// not a doc comment"), NewPos(0, 22), nil},
+		{"/** doc\n * comment */", token.NewToken(token.DocCommentMultiline, " doc\n * comment "), &Pos{0, 21, 1, 2, 1, 14, 1, 14}, nil},
+		{"/**/", token.NewToken(token.CommentMultiline, ""), NewPos(0, 4), nil},
+		{"/** error! ", nil, nil, NewTokenizerErr(ErrInvalidMultilineComment).at(NewPos(0, 11))},
 	}
 	for _, tt := range tests {
 		t.Run(tt.input, func(t *testing.T) {
@@ -157,7 +278,12 @@ func TestTokenizer_readIdentifier(t *testing.T) {
 		{"still_valid", token.NewToken(token.Ident, "still_valid"), NewPos(0, 11)},
 		{"valid_too123", token.NewToken(token.Ident, "valid_too123"), NewPos(0, 12)},
 		{"valid__1", token.NewToken(token.Ident, "valid__1"), NewPos(0, 8)},
-		{"漢13", token.NewToken(token.Ident, "漢13"), NewPos(0, 3)},
+		{"漢13", token.NewToken(token.Ident, "漢13"), &Pos{0, 5, 1, 1, 1, 6, 1, 4}},
+		{"_private", token.NewToken(token.Ident, "_private"), NewPos(0, 8)},
+		{"naïve", token.NewToken(token.Ident, "naïve"), &Pos{0, 6, 1, 1, 1, 7, 1, 6}},
+		{"cafe\u0301", token.NewToken(token.Ident, "cafe\u0301"), &Pos{0, 6, 1, 1, 1, 7, 1, 6}},
+		{"\U0001D465_1", token.NewToken(token.Ident, "\U0001D465_1"), &Pos{0, 6, 1, 1, 1, 7, 1, 5}},
+		{"end😃", token.NewToken(token.Ident, "end"), NewPos(0, 3)},
 	}
 	for _, tt := range tests {
 		t.Run(tt.input, func(t *testing.T) {
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Words splits name in the words it is made of, either separated by underscores or hyphens, or by a change of
+// case, like in "userId" or "HTTPServer", which are split into "user", "Id" and "HTTP", "Server"
+func Words(name string) []string {
+	runes := []rune(name)
+	words := make([]string, 0)
+	current := make([]rune, 0, len(runes))
+
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, string(current))
+			current = current[:0]
+		}
+	}
+
+	for i, r := range runes {
+		if r == '_' || r == '-' || unicode.IsSpace(r) {
+			flush()
+			continue
+		}
+
+		if unicode.IsUpper(r) && len(current) > 0 {
+			previous := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextIsLower) {
+				flush()
+			}
+		}
+
+		current = append(current, r)
+	}
+
+	flush()
+	return words
+}
+
+// ToSnakeCase converts name to snake_case, like "user_id"
+func ToSnakeCase(name string) string {
+	words := Words(name)
+	for i, word := range words {
+		words[i] = strings.ToLower(word)
+	}
+
+	return strings.Join(words, "_")
+}
+
+// ToPascalCase converts name to PascalCase, like "UserId"
+func ToPascalCase(name string) string {
+	words := Words(name)
+	for i, word := range words {
+		words[i] = capitalize(word)
+	}
+
+	return strings.Join(words, "")
+}
+
+// ToCamelCase converts name to camelCase, like "userId"
+func ToCamelCase(name string) string {
+	words := Words(name)
+	for i, word := range words {
+		if i == 0 {
+			words[i] = strings.ToLower(word)
+		} else {
+			words[i] = capitalize(word)
+		}
+	}
+
+	return strings.Join(words, "")
+}
+
+func capitalize(word string) string {
+	runes := []rune(strings.ToLower(word))
+	if len(runes) > 0 {
+		runes[0] = unicode.ToUpper(runes[0])
+	}
+
+	return string(runes)
+}
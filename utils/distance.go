@@ -0,0 +1,63 @@
+package utils
+
+// EditDistance returns the Levenshtein distance between a and b, which is the number of runes that must be
+// inserted, deleted or replaced to turn a into b
+func EditDistance(a, b string) int {
+	source, target := []rune(a), []rune(b)
+
+	previous := make([]int, len(target)+1)
+	current := make([]int, len(target)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(source); i++ {
+		current[0] = i
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(target)]
+}
+
+// Closest returns the candidate with the smallest edit distance to name, if it is small enough to be a typo:
+// at most a third of the length of name, and at least one. If more than one candidate is at the same distance,
+// the first of them is returned.
+func Closest(name string, candidates []string) (closest string, distance int, ok bool) {
+	maxDistance := len([]rune(name)) / 3
+	if maxDistance < 1 {
+		maxDistance = 1
+	}
+
+	distance = maxDistance + 1
+	for _, candidate := range candidates {
+		if d := EditDistance(name, candidate); d < distance {
+			closest, distance = candidate, d
+		}
+	}
+
+	if distance > maxDistance {
+		return "", 0, false
+	}
+
+	return closest, distance, true
+}
+
+func minInt(values ...int) int {
+	out := values[0]
+	for _, value := range values[1:] {
+		if value < out {
+			out = value
+		}
+	}
+
+	return out
+}